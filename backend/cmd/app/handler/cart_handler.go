@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// CartHandler handles shopping cart HTTP requests
+type CartHandler struct {
+	cartService *cart.Service
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(cartService *cart.Service) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+// CreateCart handles POST /api/carts
+func (h *CartHandler) CreateCart(w http.ResponseWriter, r *http.Request) {
+	c, err := h.cartService.CreateCart(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to create cart")
+		return
+	}
+
+	web.RespondCreated(w, c)
+}
+
+// GetCart handles GET /api/carts/:token
+func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	c, err := h.cartService.GetCart(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		respondCartError(w, err, "failed to get cart")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// AddItem handles POST /api/carts/:token/items
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	var input cart.AddItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.cartService.AddItem(r.Context(), mux.Vars(r)["token"], input)
+	if err != nil {
+		respondCartError(w, err, "failed to add cart item")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// UpdateItem handles PATCH /api/carts/:token/items/:itemId
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := pathID(r, "itemId")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid cart item ID")
+		return
+	}
+
+	var input cart.UpdateItemInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.cartService.UpdateItem(r.Context(), mux.Vars(r)["token"], itemID, input)
+	if err != nil {
+		respondCartError(w, err, "failed to update cart item")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// RemoveItem handles DELETE /api/carts/:token/items/:itemId
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := pathID(r, "itemId")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid cart item ID")
+		return
+	}
+
+	c, err := h.cartService.RemoveItem(r.Context(), mux.Vars(r)["token"], itemID)
+	if err != nil {
+		respondCartError(w, err, "failed to remove cart item")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// SetCoupon handles PUT /api/carts/:token/coupon
+func (h *CartHandler) SetCoupon(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.cartService.SetCoupon(r.Context(), mux.Vars(r)["token"], input.Code)
+	if err != nil {
+		respondCartError(w, err, "failed to set coupon")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// RemoveCoupon handles DELETE /api/carts/:token/coupon
+func (h *CartHandler) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	c, err := h.cartService.RemoveCoupon(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		respondCartError(w, err, "failed to remove coupon")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// respondCartError maps cart errors to HTTP responses
+func respondCartError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, cart.ErrNotFound):
+		web.RespondNotFound(w, "cart not found")
+	case errors.Is(err, cart.ErrItemNotFound):
+		web.RespondNotFound(w, "cart item not found")
+	case errors.Is(err, cart.ErrValidation), errors.Is(err, product.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
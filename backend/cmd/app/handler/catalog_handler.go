@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// maxImportSize limits the size of uploaded import files
+const maxImportSize = 10 << 20
+
+// CatalogHandler handles bulk catalog HTTP requests
+type CatalogHandler struct {
+	productService *product.Service
+}
+
+// NewCatalogHandler creates a new catalog handler
+func NewCatalogHandler(productService *product.Service) *CatalogHandler {
+	return &CatalogHandler{productService: productService}
+}
+
+// Import handles POST /api/admin/products/import.
+// The file is sent as multipart field "file" or as the raw request body.
+func (h *CatalogHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var data []byte
+	var err error
+	format := r.URL.Query().Get("format")
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, formErr := r.FormFile("file")
+		if formErr != nil {
+			web.RespondBadRequest(w, "file is required")
+			return
+		}
+		defer file.Close()
+
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+		data, err = io.ReadAll(file)
+	} else {
+		if format == "" {
+			format = formatFromContentType(r.Header.Get("Content-Type"))
+		}
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		web.RespondBadRequest(w, "failed to read file")
+		return
+	}
+
+	records, err := product.ReadRecords(data, format)
+	if err != nil {
+		web.RespondBadRequest(w, err.Error())
+		return
+	}
+
+	result, err := h.productService.ImportProducts(r.Context(), records, product.ImportOptions{
+		DryRun:  r.URL.Query().Get("dry_run") == "true",
+		AdminID: adminID(r),
+	})
+	if err != nil {
+		if errors.Is(err, product.ErrValidation) {
+			web.RespondBadRequest(w, err.Error())
+			return
+		}
+		web.RespondInternalError(w, "failed to import products")
+		return
+	}
+
+	if !result.DryRun && !result.Applied {
+		web.Respond(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	web.RespondOK(w, result)
+}
+
+// Bulk handles POST /api/admin/products/bulk
+func (h *CatalogHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var input product.BulkInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.AdminID = adminID(r)
+
+	result, err := h.productService.BulkUpdate(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, product.ErrValidation) {
+			web.RespondBadRequest(w, err.Error())
+			return
+		}
+		web.RespondInternalError(w, "failed to apply bulk operation")
+		return
+	}
+
+	if !result.Applied {
+		web.Respond(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	web.RespondOK(w, result)
+}
+
+// exportContentTypes maps export formats to their content type
+var exportContentTypes = map[string]string{
+	product.FormatCSV:   "text/csv; charset=utf-8",
+	product.FormatJSONL: "application/x-ndjson",
+	product.FormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Export handles GET /api/admin/products/export.
+// ?format=csv|jsonl|xlsx (default csv), ?include_deleted=true adds trashed products.
+func (h *CatalogHandler) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = product.FormatCSV
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		web.RespondBadRequest(w, "unsupported format, use csv, jsonl or xlsx")
+		return
+	}
+
+	filename := fmt.Sprintf("productos-%s.%s", time.Now().Format("2006-01-02"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The response is streamed, so failures past this point can only be logged
+	if err := h.productService.ExportProducts(r.Context(), w, format, query.Get("include_deleted") == "true"); err != nil {
+		log.Printf("failed to export products: %v", err)
+	}
+}
+
+// formatFromContentType maps a request content type to an import format
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "spreadsheetml"):
+		return product.FormatXLSX
+	case strings.Contains(contentType, "csv"), strings.HasPrefix(contentType, "text/plain"):
+		return product.FormatCSV
+	}
+	return ""
+}
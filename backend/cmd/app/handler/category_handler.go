@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/category"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// CategoryHandler handles category HTTP requests
+type CategoryHandler struct {
+	categoryService *category.Service
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService *category.Service) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// GetTree handles GET /api/categories
+func (h *CategoryHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.categoryService.GetTree(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get categories")
+		return
+	}
+
+	web.RespondOK(w, tree)
+}
+
+// GetCategories handles GET /api/admin/categories
+func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.GetCategories(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get categories")
+		return
+	}
+
+	web.RespondOK(w, categories)
+}
+
+// GetCategory handles GET /api/admin/categories/:id
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid category ID")
+		return
+	}
+
+	c, err := h.categoryService.GetCategory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, category.ErrNotFound) {
+			web.RespondNotFound(w, "category not found")
+			return
+		}
+		web.RespondInternalError(w, "failed to get category")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// CreateCategory handles POST /api/admin/categories
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var input category.CreateCategoryInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.categoryService.CreateCategory(r.Context(), input)
+	if err != nil {
+		respondCategoryError(w, err)
+		return
+	}
+
+	web.RespondCreated(w, c)
+}
+
+// UpdateCategory handles PUT /api/admin/categories/:id
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid category ID")
+		return
+	}
+
+	var input category.UpdateCategoryInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.categoryService.UpdateCategory(r.Context(), id, input)
+	if err != nil {
+		respondCategoryError(w, err)
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// DeleteCategory handles DELETE /api/admin/categories/:id
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid category ID")
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(r.Context(), id); err != nil {
+		respondCategoryError(w, err)
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// respondCategoryError maps category errors to HTTP responses
+func respondCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, category.ErrNotFound):
+		web.RespondNotFound(w, "category not found")
+	case errors.Is(err, category.ErrDuplicateSlug), errors.Is(err, category.ErrInUse):
+		web.RespondConflict(w, err.Error())
+	default:
+		web.RespondBadRequest(w, err.Error())
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/checkout"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// CheckoutHandler handles checkout HTTP requests
+type CheckoutHandler struct {
+	checkoutService *checkout.Service
+}
+
+// NewCheckoutHandler creates a new checkout handler
+func NewCheckoutHandler(checkoutService *checkout.Service) *CheckoutHandler {
+	return &CheckoutHandler{checkoutService: checkoutService}
+}
+
+// WhatsApp handles POST /api/checkout/whatsapp
+func (h *CheckoutHandler) WhatsApp(w http.ResponseWriter, r *http.Request) {
+	var input checkout.WhatsAppInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.CustomerID = customerID(r)
+
+	result, err := h.checkoutService.WhatsApp(r.Context(), input)
+	switch {
+	case err == nil:
+		web.RespondCreated(w, result)
+	case errors.Is(err, checkout.ErrNotConfigured):
+		web.RespondError(w, http.StatusServiceUnavailable, "not_configured", "WhatsApp checkout is not available")
+	case errors.Is(err, cart.ErrNotFound):
+		web.RespondNotFound(w, "cart not found")
+	case errors.Is(err, checkout.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	case errors.Is(err, design.ErrNotFound), errors.Is(err, design.ErrNotConfigured):
+		respondDesignError(w, err, "failed to check out")
+	default:
+		respondOrderError(w, err, "failed to check out")
+	}
+}
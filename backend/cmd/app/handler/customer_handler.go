@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// CustomerHandler handles customer account HTTP requests
+type CustomerHandler struct {
+	customerService *customer.Service
+}
+
+// NewCustomerHandler creates a new customer handler
+func NewCustomerHandler(customerService *customer.Service) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService}
+}
+
+// Register handles POST /api/customers/register
+func (h *CustomerHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var input customer.RegisterInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.customerService.Register(r.Context(), input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to create account")
+		return
+	}
+
+	web.RespondCreated(w, c)
+}
+
+// Login handles POST /api/customers/login
+func (h *CustomerHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var input customer.LoginInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	response, err := h.customerService.Login(r.Context(), input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to sign in")
+		return
+	}
+
+	web.RespondOK(w, response)
+}
+
+// VerifyEmail handles POST /api/customers/verify
+func (h *CustomerHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var input customer.VerifyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	response, err := h.customerService.VerifyEmail(r.Context(), input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to verify email")
+		return
+	}
+
+	web.RespondOK(w, response)
+}
+
+// ResendVerification handles POST /api/customers/verification
+func (h *CustomerHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var input customer.ResendVerificationInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.customerService.ResendVerification(r.Context(), input); err != nil {
+		respondCustomerError(w, err, "failed to send verification email")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// GetProfile handles GET /api/customers/me
+func (h *CustomerHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	c, err := h.customerService.GetProfile(r.Context(), customerID(r))
+	if err != nil {
+		respondCustomerError(w, err, "failed to get account")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// UpdateProfile handles PUT /api/customers/me
+func (h *CustomerHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var input customer.UpdateProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.customerService.UpdateProfile(r.Context(), customerID(r), input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to update account")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// CreateAddress handles POST /api/customers/me/addresses
+func (h *CustomerHandler) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	var input customer.AddressInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	a, err := h.customerService.CreateAddress(r.Context(), customerID(r), input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to save address")
+		return
+	}
+
+	web.RespondCreated(w, a)
+}
+
+// UpdateAddress handles PUT /api/customers/me/addresses/:id
+func (h *CustomerHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid address ID")
+		return
+	}
+
+	var input customer.AddressInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	a, err := h.customerService.UpdateAddress(r.Context(), customerID(r), id, input)
+	if err != nil {
+		respondCustomerError(w, err, "failed to update address")
+		return
+	}
+
+	web.RespondOK(w, a)
+}
+
+// DeleteAddress handles DELETE /api/customers/me/addresses/:id
+func (h *CustomerHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid address ID")
+		return
+	}
+
+	if err := h.customerService.DeleteAddress(r.Context(), customerID(r), id); err != nil {
+		respondCustomerError(w, err, "failed to delete address")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// GetOrders handles GET /api/customers/me/orders
+func (h *CustomerHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	orders, total, err := h.customerService.GetOrders(r.Context(), customerID(r), page, limit)
+	if err != nil {
+		respondOrderError(w, err, "failed to get orders")
+		return
+	}
+
+	web.RespondOK(w, map[string]interface{}{
+		"orders": orders,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetOrder handles GET /api/customers/me/orders/:reference
+func (h *CustomerHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	o, err := h.customerService.GetOrder(r.Context(), customerID(r), mux.Vars(r)["reference"])
+	if err != nil {
+		respondOrderError(w, err, "failed to get order")
+		return
+	}
+
+	web.RespondOK(w, o)
+}
+
+// GetDesigns handles GET /api/customers/me/designs
+func (h *CustomerHandler) GetDesigns(w http.ResponseWriter, r *http.Request) {
+	designs, err := h.customerService.GetDesigns(r.Context(), customerID(r))
+	if err != nil {
+		respondDesignError(w, err, "failed to get designs")
+		return
+	}
+
+	web.RespondOK(w, designs)
+}
+
+// respondCustomerError maps customer errors to HTTP responses
+func respondCustomerError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, customer.ErrNotFound):
+		web.RespondNotFound(w, "account not found")
+	case errors.Is(err, customer.ErrAddressNotFound):
+		web.RespondNotFound(w, "address not found")
+	case errors.Is(err, customer.ErrInvalidCredentials):
+		web.RespondUnauthorized(w, "invalid email or password")
+	case errors.Is(err, customer.ErrEmailNotVerified):
+		web.RespondError(w, http.StatusForbidden, "forbidden", err.Error())
+	case errors.Is(err, customer.ErrEmailTaken):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, customer.ErrInvalidVerification), errors.Is(err, customer.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
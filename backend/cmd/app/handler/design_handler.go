@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// maxDesignSize limits the body of saved designs, which carry their image as base64
+const maxDesignSize = 16 << 20
+
+// DesignHandler handles custom design HTTP requests
+type DesignHandler struct {
+	designService *design.Service
+}
+
+// NewDesignHandler creates a new design handler
+func NewDesignHandler(designService *design.Service) *DesignHandler {
+	return &DesignHandler{designService: designService}
+}
+
+// CreateDesign handles POST /api/designs
+func (h *DesignHandler) CreateDesign(w http.ResponseWriter, r *http.Request) {
+	var input design.CreateDesignInput
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDesignSize)).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.CustomerID = customerID(r)
+
+	d, err := h.designService.CreateDesign(r.Context(), input)
+	if err != nil {
+		respondDesignError(w, err, "failed to save design")
+		return
+	}
+
+	web.RespondCreated(w, d)
+}
+
+// GetDesign handles GET /api/designs/{token}
+func (h *DesignHandler) GetDesign(w http.ResponseWriter, r *http.Request) {
+	d, err := h.designService.GetDesign(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		respondDesignError(w, err, "failed to get design")
+		return
+	}
+
+	web.RespondOK(w, d)
+}
+
+// RenderDesign handles POST /api/admin/designs/{token}/render with an
+// optional print area
+func (h *DesignHandler) RenderDesign(w http.ResponseWriter, r *http.Request) {
+	var input design.RenderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && err != io.EOF {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	d, err := h.designService.RenderDesign(r.Context(), mux.Vars(r)["token"], input)
+	if err != nil {
+		respondDesignError(w, err, "failed to render design")
+		return
+	}
+
+	web.RespondOK(w, d)
+}
+
+// respondDesignError maps design errors to HTTP responses
+func respondDesignError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, design.ErrNotFound):
+		web.RespondNotFound(w, "design not found")
+	case errors.Is(err, design.ErrNotConfigured):
+		web.RespondError(w, http.StatusServiceUnavailable, "not_configured", "designs cannot be ordered yet")
+	case errors.Is(err, design.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
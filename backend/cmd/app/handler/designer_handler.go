@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// DesignerHandler handles the designer's templates and shirt colors
+type DesignerHandler struct {
+	designService *design.Service
+}
+
+// NewDesignerHandler creates a new designer handler
+func NewDesignerHandler(designService *design.Service) *DesignerHandler {
+	return &DesignerHandler{designService: designService}
+}
+
+// GetCatalog handles GET /api/designer
+func (h *DesignerHandler) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	catalog, err := h.designService.GetCatalog(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get designer templates")
+		return
+	}
+
+	web.RespondOK(w, catalog)
+}
+
+// GetTemplates handles GET /api/admin/design-templates
+func (h *DesignerHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.designService.GetTemplates(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get design templates")
+		return
+	}
+
+	web.RespondOK(w, templates)
+}
+
+// GetTemplate handles GET /api/admin/design-templates/:id
+func (h *DesignerHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid template ID")
+		return
+	}
+
+	t, err := h.designService.GetTemplate(r.Context(), id)
+	if err != nil {
+		respondDesignerError(w, err, "failed to get design template")
+		return
+	}
+
+	web.RespondOK(w, t)
+}
+
+// CreateTemplate handles POST /api/admin/design-templates
+func (h *DesignerHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var input design.CreateTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	t, err := h.designService.CreateTemplate(r.Context(), input)
+	if err != nil {
+		respondDesignerError(w, err, "failed to create design template")
+		return
+	}
+
+	web.RespondCreated(w, t)
+}
+
+// UpdateTemplate handles PUT /api/admin/design-templates/:id
+func (h *DesignerHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid template ID")
+		return
+	}
+
+	var input design.UpdateTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	t, err := h.designService.UpdateTemplate(r.Context(), id, input)
+	if err != nil {
+		respondDesignerError(w, err, "failed to update design template")
+		return
+	}
+
+	web.RespondOK(w, t)
+}
+
+// UploadTemplateImage handles POST /api/admin/design-templates/:id/images/:kind
+// with the image as multipart field "file"
+func (h *DesignerHandler) UploadTemplateImage(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid template ID")
+		return
+	}
+
+	// Parse multipart form (32MB max memory)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		web.RespondBadRequest(w, "failed to parse form")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		web.RespondBadRequest(w, "file is required")
+		return
+	}
+	defer file.Close()
+
+	t, err := h.designService.SetTemplateImage(r.Context(), id, mux.Vars(r)["kind"], file, header)
+	if err != nil {
+		respondDesignerError(w, err, "failed to upload template image")
+		return
+	}
+
+	web.RespondOK(w, t)
+}
+
+// DeleteTemplate handles DELETE /api/admin/design-templates/:id
+func (h *DesignerHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid template ID")
+		return
+	}
+
+	if err := h.designService.DeleteTemplate(r.Context(), id); err != nil {
+		respondDesignerError(w, err, "failed to delete design template")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// GetColors handles GET /api/admin/shirt-colors
+func (h *DesignerHandler) GetColors(w http.ResponseWriter, r *http.Request) {
+	colors, err := h.designService.GetColors(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get shirt colors")
+		return
+	}
+
+	web.RespondOK(w, colors)
+}
+
+// GetColor handles GET /api/admin/shirt-colors/:id
+func (h *DesignerHandler) GetColor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid color ID")
+		return
+	}
+
+	c, err := h.designService.GetColor(r.Context(), id)
+	if err != nil {
+		respondDesignerError(w, err, "failed to get shirt color")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// CreateColor handles POST /api/admin/shirt-colors
+func (h *DesignerHandler) CreateColor(w http.ResponseWriter, r *http.Request) {
+	var input design.CreateColorInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.designService.CreateColor(r.Context(), input)
+	if err != nil {
+		respondDesignerError(w, err, "failed to create shirt color")
+		return
+	}
+
+	web.RespondCreated(w, c)
+}
+
+// UpdateColor handles PUT /api/admin/shirt-colors/:id
+func (h *DesignerHandler) UpdateColor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid color ID")
+		return
+	}
+
+	var input design.UpdateColorInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	c, err := h.designService.UpdateColor(r.Context(), id, input)
+	if err != nil {
+		respondDesignerError(w, err, "failed to update shirt color")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// UploadColorMockup handles POST /api/admin/shirt-colors/:id/mockup with the
+// photo as multipart field "file"
+func (h *DesignerHandler) UploadColorMockup(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid color ID")
+		return
+	}
+
+	// Parse multipart form (32MB max memory)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		web.RespondBadRequest(w, "failed to parse form")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		web.RespondBadRequest(w, "file is required")
+		return
+	}
+	defer file.Close()
+
+	c, err := h.designService.SetColorMockup(r.Context(), id, file, header)
+	if err != nil {
+		respondDesignerError(w, err, "failed to upload shirt mockup")
+		return
+	}
+
+	web.RespondOK(w, c)
+}
+
+// DeleteColor handles DELETE /api/admin/shirt-colors/:id
+func (h *DesignerHandler) DeleteColor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid color ID")
+		return
+	}
+
+	if err := h.designService.DeleteColor(r.Context(), id); err != nil {
+		respondDesignerError(w, err, "failed to delete shirt color")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// respondDesignerError maps template and shirt color errors to HTTP responses
+func respondDesignerError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, design.ErrTemplateNotFound):
+		web.RespondNotFound(w, "design template not found")
+	case errors.Is(err, design.ErrColorNotFound):
+		web.RespondNotFound(w, "shirt color not found")
+	case errors.Is(err, design.ErrDuplicateSlug), errors.Is(err, design.ErrDuplicateColor), errors.Is(err, design.ErrInUse):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, design.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
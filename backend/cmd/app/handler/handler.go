@@ -2,11 +2,22 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/tomas/tienda-backend/internal/auth"
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/category"
+	"github.com/tomas/tienda-backend/internal/checkout"
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/inventory"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/payment"
 	"github.com/tomas/tienda-backend/internal/platform/middleware"
 	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+	"github.com/tomas/tienda-backend/internal/shipping"
 	"github.com/tomas/tienda-backend/internal/upload"
 )
 
@@ -15,8 +26,19 @@ func SetupRouter(
 	productService *product.Service,
 	authService *auth.Service,
 	uploadService *upload.Service,
+	inventoryService *inventory.Service,
+	categoryService *category.Service,
+	promotionService *promotion.Service,
+	cartService *cart.Service,
+	orderService *order.Service,
+	checkoutService *checkout.Service,
+	paymentService *payment.Service,
+	shippingService *shipping.Service,
+	designService *design.Service,
+	customerService *customer.Service,
 	corsOrigin string,
 	uploadDir string,
+	trashRetentionDays int,
 ) *mux.Router {
 	r := mux.NewRouter()
 
@@ -25,6 +47,22 @@ func SetupRouter(
 	authHandler := NewAuthHandler(authService)
 	uploadHandler := NewUploadHandler(uploadService)
 	imagesHandler := NewImagesHandler(productService, uploadDir)
+	variantHandler := NewVariantHandler(productService)
+	inventoryHandler := NewInventoryHandler(inventoryService)
+	categoryHandler := NewCategoryHandler(categoryService)
+	trashHandler := NewTrashHandler(productService, uploadService, trashRetentionDays)
+	revisionHandler := NewRevisionHandler(productService)
+	catalogHandler := NewCatalogHandler(productService)
+	priceAdjustmentHandler := NewPriceAdjustmentHandler(productService)
+	promotionHandler := NewPromotionHandler(promotionService)
+	cartHandler := NewCartHandler(cartService)
+	orderHandler := NewOrderHandler(orderService)
+	checkoutHandler := NewCheckoutHandler(checkoutService)
+	paymentHandler := NewPaymentHandler(paymentService)
+	shippingHandler := NewShippingHandler(shippingService)
+	designHandler := NewDesignHandler(designService)
+	designerHandler := NewDesignerHandler(designService)
+	customerHandler := NewCustomerHandler(customerService)
 
 	// Apply global middleware
 	r.Use(middleware.Logger)
@@ -38,13 +76,40 @@ func SetupRouter(
 	// API router
 	api := r.PathPrefix("/api").Subrouter()
 
+	// Guest checkouts and designs belong to the customer when one is signed in
+	optionalCustomerMW := middleware.OptionalCustomerMiddleware(customerService)
+
 	// Public routes
 	api.HandleFunc("/products", productHandler.GetProducts).Methods("GET", "OPTIONS")
+	api.HandleFunc("/products/by-slug/{slug}", productHandler.GetProductBySlug).Methods("GET", "OPTIONS")
 	api.HandleFunc("/products/{id}", productHandler.GetProduct).Methods("GET", "OPTIONS")
+	api.HandleFunc("/products/{id}/variants", variantHandler.GetVariants).Methods("GET", "OPTIONS")
+	api.HandleFunc("/categories", categoryHandler.GetTree).Methods("GET", "OPTIONS")
+	api.HandleFunc("/promotions/quote", promotionHandler.Quote).Methods("POST", "OPTIONS")
+	api.HandleFunc("/carts", cartHandler.CreateCart).Methods("POST", "OPTIONS")
+	api.HandleFunc("/carts/{token}", cartHandler.GetCart).Methods("GET", "OPTIONS")
+	api.HandleFunc("/carts/{token}/items", cartHandler.AddItem).Methods("POST", "OPTIONS")
+	api.HandleFunc("/carts/{token}/items/{itemId}", cartHandler.UpdateItem).Methods("PATCH", "OPTIONS")
+	api.HandleFunc("/carts/{token}/items/{itemId}", cartHandler.RemoveItem).Methods("DELETE")
+	api.HandleFunc("/carts/{token}/coupon", cartHandler.SetCoupon).Methods("PUT", "OPTIONS")
+	api.HandleFunc("/carts/{token}/coupon", cartHandler.RemoveCoupon).Methods("DELETE")
+	api.Handle("/checkout/whatsapp", optionalCustomerMW(http.HandlerFunc(checkoutHandler.WhatsApp))).Methods("POST", "OPTIONS")
+	api.HandleFunc("/payments/checkout", paymentHandler.CreateCheckout).Methods("POST", "OPTIONS")
+	api.HandleFunc("/payments/webhook", paymentHandler.Webhook).Methods("POST")
+	api.HandleFunc("/shipping/quote", shippingHandler.Quote).Methods("POST", "OPTIONS")
+	api.Handle("/designs", optionalCustomerMW(http.HandlerFunc(designHandler.CreateDesign))).Methods("POST", "OPTIONS")
+	api.HandleFunc("/designs/{token}", designHandler.GetDesign).Methods("GET", "OPTIONS")
+	api.HandleFunc("/designer", designerHandler.GetCatalog).Methods("GET", "OPTIONS")
 
 	// Auth routes
 	api.HandleFunc("/auth/login", authHandler.Login).Methods("POST", "OPTIONS")
 
+	// Customer account routes
+	api.HandleFunc("/customers/register", customerHandler.Register).Methods("POST", "OPTIONS")
+	api.HandleFunc("/customers/login", customerHandler.Login).Methods("POST", "OPTIONS")
+	api.HandleFunc("/customers/verify", customerHandler.VerifyEmail).Methods("POST", "OPTIONS")
+	api.HandleFunc("/customers/verification", customerHandler.ResendVerification).Methods("POST", "OPTIONS")
+
 	// OPTIONS routes for admin endpoints (must be registered BEFORE auth middleware to handle CORS preflight)
 	optionsHandler := func(w http.ResponseWriter, r *http.Request) {
 		// CORS headers are already set by global middleware
@@ -52,9 +117,60 @@ func SetupRouter(
 	}
 	api.HandleFunc("/products", optionsHandler).Methods("OPTIONS")
 	api.HandleFunc("/products/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/products/{id}/variants", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/products/{id}/variants/{variantId}", optionsHandler).Methods("OPTIONS")
 	api.HandleFunc("/admin/upload", optionsHandler).Methods("OPTIONS")
 	api.HandleFunc("/admin/images/orphaned", optionsHandler).Methods("OPTIONS")
 	api.HandleFunc("/admin/images/{filename}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/inventory/movements", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/inventory/low-stock", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/inventory/products/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/categories", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/categories/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/trash", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/import", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/export", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/bulk", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/{id}/restore", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/{id}/revisions", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/{id}/prices", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/products/{id}/revisions/{revision}/rollback", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/price-adjustments", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/price-adjustments/preview", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/price-adjustments/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/price-adjustments/{id}/revert", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/promotions", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/promotions/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/orders", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/orders/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/orders/{id}/status", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/orders/{id}/payments", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/shipping", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/designs/{token}/render", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/design-templates", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/design-templates/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/design-templates/{id}/images/{kind}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/shirt-colors", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/shirt-colors/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/admin/shirt-colors/{id}/mockup", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me/addresses", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me/addresses/{id}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me/orders", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me/orders/{reference}", optionsHandler).Methods("OPTIONS")
+	api.HandleFunc("/customers/me/designs", optionsHandler).Methods("OPTIONS")
+
+	// Protected customer routes (signed in customers only reach their own account)
+	customerAPI := api.PathPrefix("/customers/me").Subrouter()
+	customerAPI.Use(middleware.CustomerAuthMiddleware(customerService))
+	customerAPI.HandleFunc("", customerHandler.GetProfile).Methods("GET")
+	customerAPI.HandleFunc("", customerHandler.UpdateProfile).Methods("PUT")
+	customerAPI.HandleFunc("/addresses", customerHandler.CreateAddress).Methods("POST")
+	customerAPI.HandleFunc("/addresses/{id}", customerHandler.UpdateAddress).Methods("PUT")
+	customerAPI.HandleFunc("/addresses/{id}", customerHandler.DeleteAddress).Methods("DELETE")
+	customerAPI.HandleFunc("/orders", customerHandler.GetOrders).Methods("GET")
+	customerAPI.HandleFunc("/orders/{reference}", customerHandler.GetOrder).Methods("GET")
+	customerAPI.HandleFunc("/designs", customerHandler.GetDesigns).Methods("GET")
 
 	// Protected admin routes (authenticated)
 	authMW := middleware.AuthMiddleware(authService)
@@ -64,9 +180,61 @@ func SetupRouter(
 	adminAPI.HandleFunc("/products", productHandler.CreateProduct).Methods("POST")
 	adminAPI.HandleFunc("/products/{id}", productHandler.UpdateProduct).Methods("PUT", "PATCH")
 	adminAPI.HandleFunc("/products/{id}", productHandler.DeleteProduct).Methods("DELETE")
+	adminAPI.HandleFunc("/products/{id}/variants", variantHandler.CreateVariant).Methods("POST")
+	adminAPI.HandleFunc("/products/{id}/variants/{variantId}", variantHandler.UpdateVariant).Methods("PUT", "PATCH")
+	adminAPI.HandleFunc("/products/{id}/variants/{variantId}", variantHandler.DeleteVariant).Methods("DELETE")
 	adminAPI.HandleFunc("/admin/upload", uploadHandler.UploadImage).Methods("POST")
 	adminAPI.HandleFunc("/admin/images/orphaned", imagesHandler.GetOrphanedImages).Methods("GET")
 	adminAPI.HandleFunc("/admin/images/{filename}", imagesHandler.DeleteImage).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/inventory/movements", inventoryHandler.RecordMovement).Methods("POST")
+	adminAPI.HandleFunc("/admin/inventory/movements", inventoryHandler.GetMovements).Methods("GET")
+	adminAPI.HandleFunc("/admin/inventory/low-stock", inventoryHandler.GetLowStock).Methods("GET")
+	adminAPI.HandleFunc("/admin/inventory/products/{id}", inventoryHandler.GetStockLevel).Methods("GET")
+	adminAPI.HandleFunc("/admin/categories", categoryHandler.GetCategories).Methods("GET")
+	adminAPI.HandleFunc("/admin/categories", categoryHandler.CreateCategory).Methods("POST")
+	adminAPI.HandleFunc("/admin/categories/{id}", categoryHandler.GetCategory).Methods("GET")
+	adminAPI.HandleFunc("/admin/categories/{id}", categoryHandler.UpdateCategory).Methods("PUT", "PATCH")
+	adminAPI.HandleFunc("/admin/categories/{id}", categoryHandler.DeleteCategory).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/products/import", catalogHandler.Import).Methods("POST")
+	adminAPI.HandleFunc("/admin/products/export", catalogHandler.Export).Methods("GET")
+	adminAPI.HandleFunc("/admin/products/bulk", catalogHandler.Bulk).Methods("POST")
+	adminAPI.HandleFunc("/admin/products/trash", trashHandler.GetTrash).Methods("GET")
+	adminAPI.HandleFunc("/admin/products/trash", trashHandler.PurgeTrash).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/products/{id}/restore", trashHandler.RestoreProduct).Methods("POST")
+	adminAPI.HandleFunc("/admin/products/{id}/revisions", revisionHandler.GetRevisions).Methods("GET")
+	adminAPI.HandleFunc("/admin/products/{id}/prices", productHandler.GetPriceHistory).Methods("GET")
+	adminAPI.HandleFunc("/admin/products/{id}/revisions/{revision}/rollback", revisionHandler.Rollback).Methods("POST")
+	adminAPI.HandleFunc("/admin/price-adjustments", priceAdjustmentHandler.GetAdjustments).Methods("GET")
+	adminAPI.HandleFunc("/admin/price-adjustments", priceAdjustmentHandler.CreateAdjustment).Methods("POST")
+	adminAPI.HandleFunc("/admin/price-adjustments/preview", priceAdjustmentHandler.Preview).Methods("POST")
+	adminAPI.HandleFunc("/admin/price-adjustments/{id}", priceAdjustmentHandler.GetAdjustment).Methods("GET")
+	adminAPI.HandleFunc("/admin/price-adjustments/{id}", priceAdjustmentHandler.CancelAdjustment).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/price-adjustments/{id}/revert", priceAdjustmentHandler.RevertAdjustment).Methods("POST")
+	adminAPI.HandleFunc("/admin/promotions", promotionHandler.GetPromotions).Methods("GET")
+	adminAPI.HandleFunc("/admin/promotions", promotionHandler.CreatePromotion).Methods("POST")
+	adminAPI.HandleFunc("/admin/promotions/{id}", promotionHandler.GetPromotion).Methods("GET")
+	adminAPI.HandleFunc("/admin/promotions/{id}", promotionHandler.UpdatePromotion).Methods("PUT")
+	adminAPI.HandleFunc("/admin/promotions/{id}", promotionHandler.DeletePromotion).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/orders", orderHandler.GetOrders).Methods("GET")
+	adminAPI.HandleFunc("/admin/orders", orderHandler.CreateOrder).Methods("POST")
+	adminAPI.HandleFunc("/admin/orders/{id}", orderHandler.GetOrder).Methods("GET")
+	adminAPI.HandleFunc("/admin/orders/{id}/status", orderHandler.TransitionOrder).Methods("POST")
+	adminAPI.HandleFunc("/admin/orders/{id}/payments", paymentHandler.GetPayments).Methods("GET")
+	adminAPI.HandleFunc("/admin/shipping", shippingHandler.GetSettings).Methods("GET")
+	adminAPI.HandleFunc("/admin/shipping", shippingHandler.UpdateSettings).Methods("PUT")
+	adminAPI.HandleFunc("/admin/designs/{token}/render", designHandler.RenderDesign).Methods("POST")
+	adminAPI.HandleFunc("/admin/design-templates", designerHandler.GetTemplates).Methods("GET")
+	adminAPI.HandleFunc("/admin/design-templates", designerHandler.CreateTemplate).Methods("POST")
+	adminAPI.HandleFunc("/admin/design-templates/{id}", designerHandler.GetTemplate).Methods("GET")
+	adminAPI.HandleFunc("/admin/design-templates/{id}", designerHandler.UpdateTemplate).Methods("PUT")
+	adminAPI.HandleFunc("/admin/design-templates/{id}", designerHandler.DeleteTemplate).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/design-templates/{id}/images/{kind}", designerHandler.UploadTemplateImage).Methods("POST")
+	adminAPI.HandleFunc("/admin/shirt-colors", designerHandler.GetColors).Methods("GET")
+	adminAPI.HandleFunc("/admin/shirt-colors", designerHandler.CreateColor).Methods("POST")
+	adminAPI.HandleFunc("/admin/shirt-colors/{id}", designerHandler.GetColor).Methods("GET")
+	adminAPI.HandleFunc("/admin/shirt-colors/{id}", designerHandler.UpdateColor).Methods("PUT")
+	adminAPI.HandleFunc("/admin/shirt-colors/{id}", designerHandler.DeleteColor).Methods("DELETE")
+	adminAPI.HandleFunc("/admin/shirt-colors/{id}/mockup", designerHandler.UploadColorMockup).Methods("POST")
 
 	// Health check
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -76,3 +244,24 @@ func SetupRouter(
 
 	return r
 }
+
+// pathID parses a numeric route variable such as a product ID
+func pathID(r *http.Request, key string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[key], 10, 64)
+}
+
+// adminID returns the ID of the authenticated admin, or 0 when unknown
+func adminID(r *http.Request) int64 {
+	if claims, ok := middleware.GetClaims(r); ok {
+		return claims.AdminID
+	}
+	return 0
+}
+
+// customerID returns the ID of the signed in customer, or 0 for guests
+func customerID(r *http.Request) int64 {
+	if claims, ok := middleware.GetCustomerClaims(r); ok {
+		return claims.CustomerID
+	}
+	return 0
+}
@@ -26,7 +26,7 @@ func NewImagesHandler(productService *product.Service, uploadDir string) *Images
 	}
 }
 
-// OrphanedImage represents an image not associated with any product
+// OrphanedImage represents an image not associated with any product, category or designer asset
 type OrphanedImage struct {
 	Filename   string    `json:"filename"`
 	URL        string    `json:"url"`
@@ -34,7 +34,8 @@ type OrphanedImage struct {
 	UploadedAt time.Time `json:"uploadedAt"`
 }
 
-// GetOrphanedImages returns all images not referenced by products
+// GetOrphanedImages returns all images not referenced by products, trashed
+// or not, categories or the designer
 func (h *ImagesHandler) GetOrphanedImages(w http.ResponseWriter, r *http.Request) {
 	// Get all files in uploads directory
 	files, err := os.ReadDir(h.uploadDir)
@@ -43,23 +44,13 @@ func (h *ImagesHandler) GetOrphanedImages(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get all products to check image references
-	products, _, err := h.productService.GetProducts(r.Context(), product.GetAllFilters{})
+	// Build set of used image filenames
+	usedImages, err := h.productService.ReferencedImages(r.Context())
 	if err != nil {
-		web.RespondInternalError(w, "failed to get products")
+		web.RespondInternalError(w, "failed to get image references")
 		return
 	}
 
-	// Build set of used image filenames
-	usedImages := make(map[string]bool)
-	for _, product := range products {
-		for _, imageURL := range product.Images {
-			// Extract filename from URL
-			filename := filepath.Base(imageURL)
-			usedImages[filename] = true
-		}
-	}
-
 	// Find orphaned images
 	var orphaned []OrphanedImage
 	for _, file := range files {
@@ -103,20 +94,15 @@ func (h *ImagesHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if image is being used by any product
-	products, _, err := h.productService.GetProducts(r.Context(), product.GetAllFilters{})
+	// Check if image is being used by any product, category or designer asset
+	usedImages, err := h.productService.ReferencedImages(r.Context())
 	if err != nil {
 		web.RespondInternalError(w, "failed to check image usage")
 		return
 	}
-
-	for _, product := range products {
-		for _, imageURL := range product.Images {
-			if strings.HasSuffix(imageURL, filename) {
-				web.RespondBadRequest(w, "image is being used by a product")
-				return
-			}
-		}
+	if usedImages[filename] {
+		web.RespondBadRequest(w, "image is being used")
+		return
 	}
 
 	// Delete file
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/tomas/tienda-backend/internal/inventory"
+	"github.com/tomas/tienda-backend/internal/platform/middleware"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// InventoryHandler handles inventory HTTP requests
+type InventoryHandler struct {
+	inventoryService *inventory.Service
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(inventoryService *inventory.Service) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// RecordMovement handles POST /api/admin/inventory/movements
+func (h *InventoryHandler) RecordMovement(w http.ResponseWriter, r *http.Request) {
+	var input inventory.RecordMovementInput
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	if claims, ok := middleware.GetClaims(r); ok {
+		input.AdminID = claims.AdminID
+	}
+
+	movement, err := h.inventoryService.RecordMovement(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, inventory.ErrProductNotFound):
+			web.RespondNotFound(w, err.Error())
+		case errors.Is(err, inventory.ErrInsufficientStock):
+			web.RespondConflict(w, err.Error())
+		default:
+			web.RespondBadRequest(w, err.Error())
+		}
+		return
+	}
+
+	web.RespondCreated(w, movement)
+}
+
+// GetMovements handles GET /api/admin/inventory/movements
+func (h *InventoryHandler) GetMovements(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	productID, _ := strconv.ParseInt(query.Get("product_id"), 10, 64)
+
+	filters := inventory.ListMovementsFilters{
+		Page:      page,
+		Limit:     limit,
+		ProductID: productID,
+		Type:      inventory.MovementType(query.Get("type")),
+	}
+
+	movements, total, err := h.inventoryService.GetMovements(r.Context(), filters)
+	if err != nil {
+		web.RespondInternalError(w, "failed to get movements")
+		return
+	}
+
+	web.RespondOK(w, map[string]interface{}{
+		"movements": movements,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetStockLevel handles GET /api/admin/inventory/products/:id
+func (h *InventoryHandler) GetStockLevel(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	level, err := h.inventoryService.GetStockLevel(r.Context(), productID)
+	if err != nil {
+		if errors.Is(err, inventory.ErrProductNotFound) {
+			web.RespondNotFound(w, "product not found")
+			return
+		}
+		web.RespondInternalError(w, "failed to get stock level")
+		return
+	}
+
+	web.RespondOK(w, level)
+}
+
+// GetLowStock handles GET /api/admin/inventory/low-stock
+func (h *InventoryHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	threshold := -1
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			web.RespondBadRequest(w, "invalid threshold")
+			return
+		}
+		threshold = parsed
+	}
+
+	levels, err := h.inventoryService.GetLowStock(r.Context(), threshold)
+	if err != nil {
+		web.RespondInternalError(w, "failed to get low stock products")
+		return
+	}
+
+	web.RespondOK(w, levels)
+}
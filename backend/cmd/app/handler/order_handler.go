@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// OrderHandler handles order HTTP requests
+type OrderHandler struct {
+	orderService *order.Service
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *order.Service) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+// GetOrders handles GET /api/admin/orders
+func (h *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	filters := order.ListFilters{
+		Page:     page,
+		Limit:    limit,
+		Statuses: queryList(query, "status"),
+		Search:   query.Get("search"),
+	}
+
+	// Dates are whole days: to=2024-05-31 includes the orders of May 31st
+	if value := query.Get("from"); value != "" {
+		from, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			web.RespondBadRequest(w, "from must be a date like 2024-05-01")
+			return
+		}
+		filters.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			web.RespondBadRequest(w, "to must be a date like 2024-05-31")
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+		filters.To = &to
+	}
+
+	orders, total, err := h.orderService.GetOrders(r.Context(), filters)
+	if err != nil {
+		respondOrderError(w, err, "failed to get orders")
+		return
+	}
+
+	web.RespondOK(w, map[string]interface{}{
+		"orders": orders,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// GetOrder handles GET /api/admin/orders/:id
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid order ID")
+		return
+	}
+
+	o, err := h.orderService.GetOrder(r.Context(), id)
+	if err != nil {
+		respondOrderError(w, err, "failed to get order")
+		return
+	}
+
+	web.RespondOK(w, o)
+}
+
+// CreateOrder handles POST /api/admin/orders
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var input order.CreateOrderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.AdminID = adminID(r)
+
+	o, err := h.orderService.CreateOrder(r.Context(), input)
+	if err != nil {
+		respondOrderError(w, err, "failed to create order")
+		return
+	}
+
+	web.RespondCreated(w, o)
+}
+
+// TransitionOrder handles POST /api/admin/orders/:id/status
+func (h *OrderHandler) TransitionOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid order ID")
+		return
+	}
+
+	var input order.TransitionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.AdminID = adminID(r)
+
+	o, err := h.orderService.TransitionOrder(r.Context(), id, input)
+	if err != nil {
+		respondOrderError(w, err, "failed to update order status")
+		return
+	}
+
+	web.RespondOK(w, o)
+}
+
+// respondOrderError maps order errors to HTTP responses
+func respondOrderError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, order.ErrNotFound):
+		web.RespondNotFound(w, "order not found")
+	case errors.Is(err, order.ErrTransition), errors.Is(err, order.ErrOutOfStock), errors.Is(err, promotion.ErrUsageLimit):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, order.ErrValidation), errors.Is(err, product.ErrValidation), errors.Is(err, promotion.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
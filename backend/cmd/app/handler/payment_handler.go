@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/payment"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+// maxNotificationSize limits the body of payment webhooks
+const maxNotificationSize = 1 << 20
+
+// PaymentHandler handles online payment HTTP requests
+type PaymentHandler struct {
+	paymentService *payment.Service
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(paymentService *payment.Service) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// CreateCheckout handles POST /api/payments/checkout
+func (h *PaymentHandler) CreateCheckout(w http.ResponseWriter, r *http.Request) {
+	var input payment.CheckoutInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.paymentService.CreateCheckout(r.Context(), input)
+	switch {
+	case err == nil:
+		web.RespondCreated(w, p)
+	case errors.Is(err, payment.ErrNotConfigured):
+		web.RespondError(w, http.StatusServiceUnavailable, "not_configured", "online payments are not available")
+	case errors.Is(err, payment.ErrAlreadyPaid):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, payment.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		respondOrderError(w, err, "failed to create checkout")
+	}
+}
+
+// Webhook handles POST /api/payments/webhook. Any response other than 2xx
+// makes the provider retry the notification later.
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxNotificationSize))
+	if err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	err = h.paymentService.HandleNotification(r.Context(), r, body)
+	switch {
+	case err == nil:
+		web.RespondOK(w, map[string]string{"status": "ok"})
+	case errors.Is(err, payment.ErrSignature):
+		web.RespondUnauthorized(w, "invalid signature")
+	case errors.Is(err, payment.ErrNotConfigured):
+		web.RespondError(w, http.StatusServiceUnavailable, "not_configured", "online payments are not available")
+	default:
+		log.Printf("Failed to process payment notification: %v", err)
+		web.RespondInternalError(w, "failed to process notification")
+	}
+}
+
+// GetPayments handles GET /api/admin/orders/:id/payments
+func (h *PaymentHandler) GetPayments(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid order ID")
+		return
+	}
+
+	payments, err := h.paymentService.GetPayments(r.Context(), id)
+	if errors.Is(err, order.ErrNotFound) {
+		web.RespondNotFound(w, "order not found")
+		return
+	}
+	if err != nil {
+		web.RespondInternalError(w, "failed to get payments")
+		return
+	}
+
+	web.RespondOK(w, map[string]interface{}{"payments": payments})
+}
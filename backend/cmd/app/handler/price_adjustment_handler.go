@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// PriceAdjustmentHandler handles mass price adjustment HTTP requests
+type PriceAdjustmentHandler struct {
+	productService *product.Service
+}
+
+// NewPriceAdjustmentHandler creates a new price adjustment handler
+func NewPriceAdjustmentHandler(productService *product.Service) *PriceAdjustmentHandler {
+	return &PriceAdjustmentHandler{productService: productService}
+}
+
+// Preview handles POST /api/admin/price-adjustments/preview
+func (h *PriceAdjustmentHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var input product.PriceAdjustmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	preview, err := h.productService.PreviewPriceAdjustment(r.Context(), input)
+	if err != nil {
+		respondAdjustmentError(w, err, "failed to preview price adjustment")
+		return
+	}
+
+	web.RespondOK(w, preview)
+}
+
+// CreateAdjustment handles POST /api/admin/price-adjustments
+func (h *PriceAdjustmentHandler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	var input product.PriceAdjustmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	input.AdminID = adminID(r)
+
+	adjustment, err := h.productService.CreatePriceAdjustment(r.Context(), input)
+	if err != nil {
+		respondAdjustmentError(w, err, "failed to create price adjustment")
+		return
+	}
+
+	web.RespondCreated(w, adjustment)
+}
+
+// GetAdjustments handles GET /api/admin/price-adjustments
+func (h *PriceAdjustmentHandler) GetAdjustments(w http.ResponseWriter, r *http.Request) {
+	adjustments, err := h.productService.ListPriceAdjustments(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get price adjustments")
+		return
+	}
+
+	web.RespondOK(w, adjustments)
+}
+
+// GetAdjustment handles GET /api/admin/price-adjustments/:id
+func (h *PriceAdjustmentHandler) GetAdjustment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid price adjustment ID")
+		return
+	}
+
+	adjustment, err := h.productService.GetPriceAdjustment(r.Context(), id)
+	if err != nil {
+		respondAdjustmentError(w, err, "failed to get price adjustment")
+		return
+	}
+
+	web.RespondOK(w, adjustment)
+}
+
+// CancelAdjustment handles DELETE /api/admin/price-adjustments/:id
+func (h *PriceAdjustmentHandler) CancelAdjustment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid price adjustment ID")
+		return
+	}
+
+	adjustment, err := h.productService.CancelPriceAdjustment(r.Context(), id)
+	if err != nil {
+		respondAdjustmentError(w, err, "failed to cancel price adjustment")
+		return
+	}
+
+	web.RespondOK(w, adjustment)
+}
+
+// RevertAdjustment handles POST /api/admin/price-adjustments/:id/revert
+func (h *PriceAdjustmentHandler) RevertAdjustment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid price adjustment ID")
+		return
+	}
+
+	result, err := h.productService.RevertPriceAdjustment(r.Context(), id, adminID(r))
+	if err != nil {
+		respondAdjustmentError(w, err, "failed to revert price adjustment")
+		return
+	}
+
+	web.RespondOK(w, result)
+}
+
+// respondAdjustmentError maps price adjustment errors to HTTP responses
+func respondAdjustmentError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, product.ErrAdjustmentNotFound):
+		web.RespondNotFound(w, "price adjustment not found")
+	case errors.Is(err, product.ErrAdjustmentStatus):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, product.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
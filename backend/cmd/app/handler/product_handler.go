@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/tomas/tienda-backend/internal/product"
@@ -46,6 +48,23 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 		Category: query.Get("category"),
 		Sort:     query.Get("sort"),
 		Order:    query.Get("order"),
+
+		Gender:   query.Get("gender"),
+		Oversize: queryBool(query, "oversize"),
+		Featured: queryBool(query, "featured"),
+		Tags:     queryList(query, "tag"),
+		Sizes:    queryList(query, "size"),
+		Colors:   queryList(query, "color"),
+
+		InStockOnly: query.Get("in_stock") == "true",
+	}
+	filters.MinPrice, _ = strconv.Atoi(query.Get("min_price"))
+	filters.MaxPrice, _ = strconv.Atoi(query.Get("max_price"))
+
+	// Cursor mode for infinite scrolling: ?cursor= (empty) requests the first page
+	if query.Has("cursor") {
+		h.getProductsByCursor(w, r, filters, query.Get("cursor"))
+		return
 	}
 	
 	products, total, err := h.productService.GetProducts(r.Context(), filters)
@@ -53,6 +72,12 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 		web.RespondInternalError(w, "failed to get products")
 		return
 	}
+
+	facets, err := h.productService.GetFacets(r.Context(), filters)
+	if err != nil {
+		web.RespondInternalError(w, "failed to get product facets")
+		return
+	}
 	
 	// Calculate pagination info
 	totalPages := (total + limit - 1) / limit
@@ -65,11 +90,46 @@ func (h *ProductHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 			"total": total,
 			"pages": totalPages,
 		},
+		"facets": facets,
 	}
 	
 	web.RespondOK(w, response)
 }
 
+// getProductsByCursor responds with a keyset-paginated page of products.
+// Facets are only computed for the first page.
+func (h *ProductHandler) getProductsByCursor(w http.ResponseWriter, r *http.Request, filters product.GetAllFilters, cursor string) {
+	page, err := h.productService.GetProductsByCursor(r.Context(), filters, cursor)
+	if err != nil {
+		if errors.Is(err, product.ErrInvalidCursor) {
+			web.RespondBadRequest(w, "invalid cursor")
+			return
+		}
+		web.RespondInternalError(w, "failed to get products")
+		return
+	}
+
+	response := map[string]interface{}{
+		"products": page.Products,
+		"pagination": map[string]interface{}{
+			"limit":       filters.Limit,
+			"next_cursor": page.NextCursor,
+			"prev_cursor": page.PrevCursor,
+		},
+	}
+
+	if cursor == "" {
+		facets, err := h.productService.GetFacets(r.Context(), filters)
+		if err != nil {
+			web.RespondInternalError(w, "failed to get product facets")
+			return
+		}
+		response["facets"] = facets
+	}
+
+	web.RespondOK(w, response)
+}
+
 // GetProduct handles GET /api/products/:id
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -92,6 +152,31 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	web.RespondOK(w, p)
 }
 
+// GetProductBySlug handles GET /api/products/by-slug/:slug
+// Previous slugs of renamed products are redirected permanently to the current one.
+func (h *ProductHandler) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	p, err := h.productService.GetProductBySlug(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		var moved *product.SlugMovedError
+		if errors.As(err, &moved) {
+			target := "/api/products/by-slug/" + url.PathEscape(moved.Slug)
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+			return
+		}
+		if errors.Is(err, product.ErrNotFound) {
+			web.RespondNotFound(w, "product not found")
+			return
+		}
+		web.RespondInternalError(w, "failed to get product")
+		return
+	}
+
+	web.RespondOK(w, p)
+}
+
 // CreateProduct handles POST /api/products
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	var input product.CreateProductInput
@@ -101,6 +186,8 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	input.AdminID = adminID(r)
+
 	p, err := h.productService.CreateProduct(r.Context(), input)
 	if err != nil {
 		web.RespondBadRequest(w, err.Error())
@@ -126,6 +213,8 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	input.AdminID = adminID(r)
+
 	p, err := h.productService.UpdateProduct(r.Context(), id, input)
 	if err != nil {
 		if errors.Is(err, product.ErrNotFound) {
@@ -148,7 +237,7 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := h.productService.DeleteProduct(r.Context(), id); err != nil {
+	if err := h.productService.DeleteProduct(r.Context(), id, adminID(r)); err != nil {
 		if errors.Is(err, product.ErrNotFound) {
 			web.RespondNotFound(w, "product not found")
 			return
@@ -159,3 +248,47 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	
 	web.RespondNoContent(w)
 }
+
+// GetPriceHistory handles GET /api/admin/products/:id/prices
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	history, err := h.productService.GetPriceHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			web.RespondNotFound(w, "product not found")
+			return
+		}
+		web.RespondInternalError(w, "failed to get price history")
+		return
+	}
+
+	web.RespondOK(w, history)
+}
+
+// queryList returns the values of a repeatable query parameter,
+// accepting both ?size=M&size=L and ?size=M,L
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, raw := range query[key] {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// queryBool parses an optional boolean query parameter, nil when absent or invalid
+func queryBool(query url.Values, key string) *bool {
+	value, err := strconv.ParseBool(query.Get(key))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,65 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/cmd/app/handler"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+type productHandlerSuite struct {
+	suite.Suite
+	router *mux.Router
+}
+
+func TestProductHandlerSuite(t *testing.T) {
+	suite.Run(t, new(productHandlerSuite))
+}
+
+func (s *productHandlerSuite) SetupTest() {
+	ctx := context.Background()
+	svc := product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+	s.router = mux.NewRouter()
+	s.router.HandleFunc("/api/products/by-slug/{slug}", handler.NewProductHandler(svc).GetProductBySlug)
+
+	p, err := svc.CreateProduct(ctx, product.CreateProductInput{Name: "Gauchito Gil", Price: 550000})
+	s.Require().NoError(err)
+	name := "Gauchito Gil Rojo"
+	_, err = svc.UpdateProduct(ctx, p.ID, product.UpdateProductInput{Name: &name})
+	s.Require().NoError(err)
+}
+
+// get requests a path and returns the recorded response
+func (s *productHandlerSuite) get(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+var bySlugCases = []struct {
+	name         string
+	path         string
+	wantStatus   int
+	wantLocation string
+}{
+	{name: "Current slug", path: "/api/products/by-slug/gauchito-gil-rojo", wantStatus: http.StatusOK},
+	{name: "Old slug", path: "/api/products/by-slug/gauchito-gil", wantStatus: http.StatusMovedPermanently, wantLocation: "/api/products/by-slug/gauchito-gil-rojo"},
+	{name: "Old slug with query", path: "/api/products/by-slug/gauchito-gil?ref=ig", wantStatus: http.StatusMovedPermanently, wantLocation: "/api/products/by-slug/gauchito-gil-rojo?ref=ig"},
+	{name: "Unknown slug", path: "/api/products/by-slug/san-la-muerte", wantStatus: http.StatusNotFound},
+}
+
+func (s *productHandlerSuite) TestGetProductBySlug() {
+	for _, tc := range bySlugCases {
+		w := s.get(tc.path)
+
+		s.Equal(tc.wantStatus, w.Code, tc.name)
+		s.Equal(tc.wantLocation, w.Header().Get("Location"), tc.name)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// PromotionHandler handles promotion HTTP requests
+type PromotionHandler struct {
+	promotionService *promotion.Service
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService *promotion.Service) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// Quote handles POST /api/promotions/quote
+func (h *PromotionHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var input promotion.QuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	quote, err := h.promotionService.Quote(r.Context(), input)
+	if err != nil {
+		respondPromotionError(w, err, "failed to quote products")
+		return
+	}
+
+	web.RespondOK(w, quote)
+}
+
+// GetPromotions handles GET /api/admin/promotions
+func (h *PromotionHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.promotionService.GetPromotions(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get promotions")
+		return
+	}
+
+	web.RespondOK(w, promotions)
+}
+
+// GetPromotion handles GET /api/admin/promotions/:id
+func (h *PromotionHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid promotion ID")
+		return
+	}
+
+	p, err := h.promotionService.GetPromotion(r.Context(), id)
+	if err != nil {
+		respondPromotionError(w, err, "failed to get promotion")
+		return
+	}
+
+	web.RespondOK(w, p)
+}
+
+// CreatePromotion handles POST /api/admin/promotions
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var input promotion.PromotionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.promotionService.CreatePromotion(r.Context(), input)
+	if err != nil {
+		respondPromotionError(w, err, "failed to create promotion")
+		return
+	}
+
+	web.RespondCreated(w, p)
+}
+
+// UpdatePromotion handles PUT /api/admin/promotions/:id
+func (h *PromotionHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid promotion ID")
+		return
+	}
+
+	var input promotion.PromotionInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	p, err := h.promotionService.UpdatePromotion(r.Context(), id, input)
+	if err != nil {
+		respondPromotionError(w, err, "failed to update promotion")
+		return
+	}
+
+	web.RespondOK(w, p)
+}
+
+// DeletePromotion handles DELETE /api/admin/promotions/:id
+func (h *PromotionHandler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid promotion ID")
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(r.Context(), id); err != nil {
+		respondPromotionError(w, err, "failed to delete promotion")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// respondPromotionError maps promotion errors to HTTP responses
+func respondPromotionError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, promotion.ErrNotFound):
+		web.RespondNotFound(w, "promotion not found")
+	case errors.Is(err, promotion.ErrDuplicateCode):
+		web.RespondConflict(w, err.Error())
+	case errors.Is(err, promotion.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// RevisionHandler handles product revision HTTP requests
+type RevisionHandler struct {
+	productService *product.Service
+}
+
+// NewRevisionHandler creates a new revision handler
+func NewRevisionHandler(productService *product.Service) *RevisionHandler {
+	return &RevisionHandler{productService: productService}
+}
+
+// GetRevisions handles GET /api/admin/products/:id/revisions
+func (h *RevisionHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	revisions, err := h.productService.GetRevisions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			web.RespondNotFound(w, "product not found")
+			return
+		}
+		web.RespondInternalError(w, "failed to get revisions")
+		return
+	}
+
+	web.RespondOK(w, revisions)
+}
+
+// Rollback handles POST /api/admin/products/:id/revisions/:revision/rollback
+func (h *RevisionHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	number, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		web.RespondBadRequest(w, "invalid revision")
+		return
+	}
+
+	p, err := h.productService.RollbackProduct(r.Context(), id, number, adminID(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrRevisionNotFound):
+			web.RespondNotFound(w, "revision not found")
+		case errors.Is(err, product.ErrNotFound):
+			web.RespondNotFound(w, "product not found")
+		default:
+			web.RespondBadRequest(w, err.Error())
+		}
+		return
+	}
+
+	web.RespondOK(w, p)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/promotion"
+	"github.com/tomas/tienda-backend/internal/shipping"
+)
+
+// ShippingHandler handles shipping HTTP requests
+type ShippingHandler struct {
+	shippingService *shipping.Service
+}
+
+// NewShippingHandler creates a new shipping handler
+func NewShippingHandler(shippingService *shipping.Service) *ShippingHandler {
+	return &ShippingHandler{shippingService: shippingService}
+}
+
+// Quote handles POST /api/shipping/quote
+func (h *ShippingHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var input shipping.QuoteInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	quote, err := h.shippingService.Quote(r.Context(), input)
+	if err != nil {
+		respondShippingError(w, err, "failed to quote shipping")
+		return
+	}
+
+	web.RespondOK(w, quote)
+}
+
+// GetSettings handles GET /api/admin/shipping
+func (h *ShippingHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.shippingService.GetSettings(r.Context())
+	if err != nil {
+		web.RespondInternalError(w, "failed to get shipping settings")
+		return
+	}
+
+	web.RespondOK(w, settings)
+}
+
+// UpdateSettings handles PUT /api/admin/shipping
+func (h *ShippingHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var settings shipping.Settings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	saved, err := h.shippingService.UpdateSettings(r.Context(), &settings, adminID(r))
+	if err != nil {
+		respondShippingError(w, err, "failed to update shipping settings")
+		return
+	}
+
+	web.RespondOK(w, saved)
+}
+
+// respondShippingError maps shipping errors to HTTP responses
+func respondShippingError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, cart.ErrNotFound):
+		web.RespondNotFound(w, "cart not found")
+	case errors.Is(err, shipping.ErrValidation), errors.Is(err, promotion.ErrValidation):
+		web.RespondBadRequest(w, err.Error())
+	default:
+		web.RespondInternalError(w, message)
+	}
+}
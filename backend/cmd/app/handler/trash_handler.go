@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/upload"
+)
+
+// TrashHandler handles soft-deleted product HTTP requests
+type TrashHandler struct {
+	productService *product.Service
+	uploadService  *upload.Service
+	retentionDays  int
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(productService *product.Service, uploadService *upload.Service, retentionDays int) *TrashHandler {
+	return &TrashHandler{
+		productService: productService,
+		uploadService:  uploadService,
+		retentionDays:  retentionDays,
+	}
+}
+
+// GetTrash handles GET /api/admin/products/trash
+func (h *TrashHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	products, total, err := h.productService.ListTrash(r.Context(), page, limit)
+	if err != nil {
+		web.RespondInternalError(w, "failed to get trashed products")
+		return
+	}
+
+	web.RespondOK(w, map[string]interface{}{
+		"products": products,
+		"pagination": map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + limit - 1) / limit,
+		},
+		"retention_days": h.retentionDays,
+	})
+}
+
+// RestoreProduct handles POST /api/admin/products/:id/restore
+func (h *TrashHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	p, err := h.productService.RestoreProduct(r.Context(), id, adminID(r))
+	if err != nil {
+		if errors.Is(err, product.ErrNotFound) {
+			web.RespondNotFound(w, "product not found in trash")
+			return
+		}
+		web.RespondInternalError(w, "failed to restore product")
+		return
+	}
+
+	web.RespondOK(w, p)
+}
+
+// PurgeTrash handles DELETE /api/admin/products/trash.
+// Products deleted more than ?older_than_days= days ago (default: the
+// configured retention) are removed permanently along with images no
+// other product uses.
+func (h *TrashHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	days := h.retentionDays
+	if raw := r.URL.Query().Get("older_than_days"); raw != "" {
+		var err error
+		if days, err = strconv.Atoi(raw); err != nil || days < 0 {
+			web.RespondBadRequest(w, "invalid older_than_days")
+			return
+		}
+	}
+
+	result, err := h.productService.PurgeTrash(r.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		web.RespondInternalError(w, "failed to purge trash")
+		return
+	}
+
+	// Rows are already gone, so a file that cannot be removed is only logged
+	// and will show up as an orphaned image instead
+	deleted := []string{}
+	for _, filename := range result.Images {
+		if err := h.uploadService.DeleteFile(filename); err != nil {
+			log.Printf("failed to delete purged image %s: %v", filename, err)
+			continue
+		}
+		deleted = append(deleted, filename)
+	}
+
+	web.RespondOK(w, map[string]interface{}{
+		"purged":           len(result.ProductIDs),
+		"product_ids":      result.ProductIDs,
+		"kept_product_ids": result.KeptIDs,
+		"deleted_images":   deleted,
+	})
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/platform/web"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// VariantHandler handles product variant HTTP requests
+type VariantHandler struct {
+	productService *product.Service
+}
+
+// NewVariantHandler creates a new variant handler
+func NewVariantHandler(productService *product.Service) *VariantHandler {
+	return &VariantHandler{productService: productService}
+}
+
+// GetVariants handles GET /api/products/:id/variants
+func (h *VariantHandler) GetVariants(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	variants, err := h.productService.GetVariants(r.Context(), productID)
+	if err != nil {
+		respondVariantError(w, err, web.RespondInternalError, "failed to get variants")
+		return
+	}
+
+	web.RespondOK(w, variants)
+}
+
+// CreateVariant handles POST /api/products/:id/variants
+func (h *VariantHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+
+	var input product.CreateVariantInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	variant, err := h.productService.CreateVariant(r.Context(), productID, input)
+	if err != nil {
+		respondVariantError(w, err, web.RespondBadRequest, err.Error())
+		return
+	}
+
+	web.RespondCreated(w, variant)
+}
+
+// UpdateVariant handles PUT /api/products/:id/variants/:variantId
+func (h *VariantHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+	variantID, err := pathID(r, "variantId")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid variant ID")
+		return
+	}
+
+	var input product.UpdateVariantInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		web.RespondBadRequest(w, "invalid request body")
+		return
+	}
+
+	variant, err := h.productService.UpdateVariant(r.Context(), productID, variantID, input)
+	if err != nil {
+		respondVariantError(w, err, web.RespondBadRequest, err.Error())
+		return
+	}
+
+	web.RespondOK(w, variant)
+}
+
+// DeleteVariant handles DELETE /api/products/:id/variants/:variantId
+func (h *VariantHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	productID, err := pathID(r, "id")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid product ID")
+		return
+	}
+	variantID, err := pathID(r, "variantId")
+	if err != nil {
+		web.RespondBadRequest(w, "invalid variant ID")
+		return
+	}
+
+	if err := h.productService.DeleteVariant(r.Context(), productID, variantID); err != nil {
+		respondVariantError(w, err, web.RespondInternalError, "failed to delete variant")
+		return
+	}
+
+	web.RespondNoContent(w)
+}
+
+// respondVariantError maps variant lookup errors to HTTP responses,
+// reporting any other error with the given response function
+func respondVariantError(w http.ResponseWriter, err error, fallback func(http.ResponseWriter, string), message string) {
+	switch {
+	case errors.Is(err, product.ErrNotFound):
+		web.RespondNotFound(w, "product not found")
+	case errors.Is(err, product.ErrVariantNotFound):
+		web.RespondNotFound(w, "variant not found")
+	case errors.Is(err, product.ErrDuplicateVariant), errors.Is(err, product.ErrVariantInUse):
+		web.RespondConflict(w, err.Error())
+	default:
+		fallback(w, message)
+	}
+}
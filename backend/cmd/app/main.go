@@ -11,9 +11,20 @@ import (
 
 	"github.com/tomas/tienda-backend/cmd/app/handler"
 	"github.com/tomas/tienda-backend/internal/auth"
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/category"
+	"github.com/tomas/tienda-backend/internal/checkout"
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/inventory"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/payment"
 	"github.com/tomas/tienda-backend/internal/platform/config"
 	"github.com/tomas/tienda-backend/internal/platform/database"
+	"github.com/tomas/tienda-backend/internal/platform/mail"
 	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+	"github.com/tomas/tienda-backend/internal/shipping"
 	"github.com/tomas/tienda-backend/internal/upload"
 )
 
@@ -50,17 +61,44 @@ func main() {
 	// Initialize repositories
 	productRepo := product.NewSQLiteRepository(db.DB)
 	authRepo := auth.NewSQLiteRepository(db.DB)
+	inventoryRepo := inventory.NewSQLiteRepository(db.DB)
+	categoryRepo := category.NewSQLiteRepository(db.DB)
+	promotionRepo := promotion.NewSQLiteRepository(db.DB)
+	cartRepo := cart.NewSQLiteRepository(db.DB)
+	orderRepo := order.NewSQLiteRepository(db.DB)
+	paymentRepo := payment.NewSQLiteRepository(db.DB)
+	shippingRepo := shipping.NewSQLiteRepository(db.DB)
+	designRepo := design.NewSQLiteRepository(db.DB)
+	customerRepo := customer.NewSQLiteRepository(db.DB)
 
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(cfg.JWTSecret, cfg.JWTExpiryHours)
+	customerJWTManager := customer.NewJWTManager(cfg.JWTSecret, cfg.CustomerJWTExpiryHours)
 
 	// Initialize services
 	productService := product.NewService(productRepo)
 	authService := auth.NewService(authRepo, jwtManager)
 	uploadService := upload.NewService(cfg.UploadDir, cfg.MaxUploadSizeMB, cfg.BaseURL)
+	inventoryService := inventory.NewService(inventoryRepo, cfg.LowStockThreshold)
+	categoryService := category.NewService(categoryRepo)
+	promotionService := promotion.NewService(promotionRepo, productService)
+	cartService := cart.NewService(cartRepo, productService, promotionService, time.Duration(cfg.CartTTLDays)*24*time.Hour)
+	orderService := order.NewService(orderRepo, productService, promotionService, time.Duration(cfg.StockHoldMinutes)*time.Minute)
+	designRenderer, err := design.NewRenderer(cfg.DesignFontDir, float64(cfg.DesignPrintWidthCM), float64(cfg.DesignPrintHeightCM))
+	if err != nil {
+		log.Fatalf("Failed to set up design rendering: %v", err)
+	}
+	designService := design.NewService(designRepo, uploadService, designRenderer, cfg.DesignProductID, cfg.DesignSurcharge, cfg.DesignShareURL)
+	checkoutService, err := checkout.NewService(orderService, cartService, designService, cfg.WhatsAppPhone, cfg.WhatsAppMessageTemplate)
+	if err != nil {
+		log.Fatalf("Failed to set up checkout: %v", err)
+	}
+	paymentService := payment.NewService(paymentRepo, orderService, newPaymentProvider(cfg))
+	shippingService := shipping.NewService(shippingRepo, cartService, promotionService)
+	customerService := customer.NewService(customerRepo, customerJWTManager, orderService, designService, newMailer(cfg), cfg.CustomerVerifyURL)
 
 	// Setup router
-	router := handler.SetupRouter(productService, authService, uploadService, cfg.CORSOrigin, cfg.UploadDir)
+	router := handler.SetupRouter(productService, authService, uploadService, inventoryService, categoryService, promotionService, cartService, orderService, checkoutService, paymentService, shippingService, designService, customerService, cfg.CORSOrigin, cfg.UploadDir, cfg.TrashRetentionDays)
 
 	// Create HTTP server
 	addr := ":" + cfg.Port
@@ -80,12 +118,20 @@ func main() {
 		}
 	}()
 
+	// Apply scheduled price adjustments in the background
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go runPriceAdjustments(schedulerCtx, productService, time.Minute)
+	go runCartCleanup(schedulerCtx, cartService, time.Hour)
+	go runReservationCleanup(schedulerCtx, orderService, time.Minute)
+
 	// Wait for interrupt signal for graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopScheduler()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -97,3 +143,106 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// runPriceAdjustments applies due price adjustments every interval until ctx is cancelled
+func runPriceAdjustments(ctx context.Context, productService *product.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		adjustments, err := productService.ApplyDuePriceAdjustments(ctx)
+		if err != nil {
+			log.Printf("Failed to apply scheduled price adjustments: %v", err)
+		}
+		for _, a := range adjustments {
+			if a.Status == product.AdjustmentFailed {
+				log.Printf("Price adjustment %d failed: %s", a.ID, a.Error)
+			} else {
+				log.Printf("Applied price adjustment %d to %d prices", a.ID, len(a.Items))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// newPaymentProvider creates the configured online payment provider, or nil
+// when online payments are disabled
+func newPaymentProvider(cfg *config.Config) payment.Provider {
+	switch cfg.PaymentProvider {
+	case "":
+		return nil
+	case "mercadopago":
+		log.Printf("Online payments enabled with Mercado Pago at %s", cfg.MercadoPagoAPIURL)
+		return payment.NewMercadoPago(payment.MercadoPagoConfig{
+			APIURL:          cfg.MercadoPagoAPIURL,
+			AccessToken:     cfg.MercadoPagoAccessToken,
+			WebhookSecret:   cfg.MercadoPagoWebhookSecret,
+			NotificationURL: cfg.BaseURL + "/api/payments/webhook",
+			ReturnURL:       cfg.PaymentReturnURL,
+		})
+	case "fake":
+		log.Println("Online payments enabled with the fake provider; do not use in production")
+		return payment.NewFake()
+	default:
+		log.Fatalf("Unknown PAYMENT_PROVIDER %q", cfg.PaymentProvider)
+		return nil
+	}
+}
+
+// newMailer returns the sender for customer emails, logging them when no
+// SMTP server is configured
+func newMailer(cfg *config.Config) mail.Sender {
+	if cfg.SMTPHost == "" {
+		log.Println("SMTP_HOST is not set; emails will be logged instead of sent")
+		return mail.LogSender{}
+	}
+	return mail.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+}
+
+// runCartCleanup deletes expired carts every interval until ctx is cancelled
+func runCartCleanup(ctx context.Context, cartService *cart.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := cartService.PurgeExpired(ctx)
+		if err != nil {
+			log.Printf("Failed to delete expired carts: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Deleted %d expired carts", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runReservationCleanup releases the stock of pending orders past their hold
+// every interval until ctx is cancelled
+func runReservationCleanup(ctx context.Context, orderService *order.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		released, err := orderService.ReleaseExpiredReservations(ctx)
+		if err != nil {
+			log.Printf("Failed to release expired stock reservations: %v", err)
+		} else if released > 0 {
+			log.Printf("Released %d expired stock reservations", released)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
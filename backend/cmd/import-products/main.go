@@ -0,0 +1,90 @@
+// Command import-products creates or updates catalog products from a CSV or
+// XLSX file using the same rules as POST /api/admin/products/import.
+//
+//	go run ./cmd/import-products -file coleccion.xlsx -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tomas/tienda-backend/internal/platform/config"
+	"github.com/tomas/tienda-backend/internal/platform/database"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV or XLSX file to import")
+	format := flag.String("format", "", "file format (csv or xlsx), defaults to the file extension")
+	dryRun := flag.Bool("dry-run", false, "validate the file without saving")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("Failed to read file: %v", err)
+	}
+
+	records, err := product.ReadRecords(data, *format)
+	if err != nil {
+		log.Fatalf("Failed to parse file: %v", err)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	db, err := database.New(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Run migrations
+	if err := database.Migrate(db.DB); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	service := product.NewService(product.NewSQLiteRepository(db.DB))
+
+	result, err := service.ImportProducts(context.Background(), records, product.ImportOptions{DryRun: *dryRun})
+	if err != nil {
+		log.Fatalf("Failed to import products: %v", err)
+	}
+
+	for _, p := range result.Products {
+		fmt.Printf("row %d: %s %s (%d variants)\n", p.Row, p.Action, p.Slug, p.Variants)
+	}
+	for _, e := range result.Errors {
+		if e.Column != "" {
+			fmt.Printf("row %d, column %s: %s\n", e.Row, e.Column, e.Message)
+		} else {
+			fmt.Printf("row %d: %s\n", e.Row, e.Message)
+		}
+	}
+
+	switch {
+	case len(result.Errors) > 0:
+		fmt.Printf("%d errors, nothing was imported\n", len(result.Errors))
+		os.Exit(1)
+	case result.DryRun:
+		fmt.Printf("Dry run: %d products would be created and %d updated\n", result.Created, result.Updated)
+	default:
+		fmt.Printf("Imported %d new and %d updated products\n", result.Created, result.Updated)
+	}
+}
@@ -0,0 +1,40 @@
+// Command mercadopago-stub serves a local imitation of the Mercado Pago API
+// so online payments can be tested without an account or network access.
+// Point the store at it with MERCADOPAGO_API_URL and use the same access
+// token and webhook secret:
+//
+//	go run ./cmd/mercadopago-stub -addr :8090
+//
+// Opening a checkout URL it returned pays the order and notifies the store;
+// add ?status=rejected to simulate a failed payment.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"github.com/tomas/tienda-backend/internal/payment"
+)
+
+func main() {
+	// Share the store's .env so token and secret match
+	_ = godotenv.Load()
+
+	addr := flag.String("addr", ":8090", "address to listen on")
+	token := flag.String("token", os.Getenv("MERCADOPAGO_ACCESS_TOKEN"), "access token the store must send")
+	secret := flag.String("secret", os.Getenv("MERCADOPAGO_WEBHOOK_SECRET"), "secret signing webhook notifications")
+	flag.Parse()
+
+	if *token == "" || *secret == "" {
+		log.Fatal("An access token and webhook secret are required (-token and -secret, or MERCADOPAGO_ACCESS_TOKEN and MERCADOPAGO_WEBHOOK_SECRET)")
+	}
+
+	log.Printf("Mercado Pago stub listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, payment.NewMercadoPagoStub(*token, *secret)); err != nil {
+		log.Fatalf("Failed to start stub: %v", err)
+	}
+}
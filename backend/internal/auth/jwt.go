@@ -67,7 +67,8 @@ func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
 	}
 	
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	// Customer tokens carry no admin
+	if !ok || !token.Valid || claims.AdminID == 0 {
 		return nil, ErrInvalidToken
 	}
 	
@@ -0,0 +1,123 @@
+package cart
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Warning codes
+const (
+	WarningProductUnavailable = "product_unavailable" // Deleted or no longer sold
+	WarningVariantUnavailable = "variant_unavailable" // Size/color no longer offered
+	WarningPriceChanged       = "price_changed"       // Price differs from when the item was added
+)
+
+// Cart is an anonymous shopping cart identified by its token. Prices and
+// totals are computed from the current catalog every time it is read.
+type Cart struct {
+	Token     string                  `json:"token"`
+	Items     []*Item                 `json:"items"`
+	Coupon    *promotion.CouponResult `json:"coupon,omitempty"`
+	ItemCount int                     `json:"item_count"` // Units of available items
+	Subtotal  int                     `json:"subtotal"`
+	Discount  int                     `json:"discount"`
+	Total     int                     `json:"total"`
+	Warnings  []Warning               `json:"warnings"`
+	CreatedAt time.Time               `json:"created_at"`
+	UpdatedAt time.Time               `json:"updated_at"`
+	ExpiresAt time.Time               `json:"expires_at"`
+
+	id         int64
+	couponCode string
+}
+
+// Item is a product line of a cart
+type Item struct {
+	ID        int64                       `json:"id"`
+	ProductID int64                       `json:"product_id"`
+	VariantID *int64                      `json:"variant_id,omitempty"`
+	Name      string                      `json:"name"`
+	Slug      string                      `json:"slug,omitempty"`
+	Image     string                      `json:"image,omitempty"`
+	Size      string                      `json:"size,omitempty"`
+	Color     string                      `json:"color,omitempty"`
+	Quantity  int                         `json:"quantity"`
+	UnitPrice int                         `json:"unit_price"`
+	Subtotal  int                         `json:"subtotal"`
+	Discount  int                         `json:"discount"`
+	Total     int                         `json:"total"`
+	Promotion *promotion.AppliedPromotion `json:"promotion,omitempty"`
+	Available bool                        `json:"available"` // Unavailable items are left out of the totals
+
+	addedPrice int // Unit price when the item was added or last updated
+}
+
+// Warning tells the shopper about a change affecting an item since it was added
+type Warning struct {
+	ItemID    int64  `json:"item_id"`
+	ProductID int64  `json:"product_id"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	OldPrice  int    `json:"old_price,omitempty"`
+	NewPrice  int    `json:"new_price,omitempty"`
+}
+
+// AddItemInput represents input for adding a product to a cart
+type AddItemInput struct {
+	ProductID int64  `json:"product_id"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// UpdateItemInput represents input for changing the quantity of a cart item.
+// A quantity of 0 removes the item.
+type UpdateItemInput struct {
+	Quantity int `json:"quantity"`
+}
+
+// Validate validates add item input, defaulting the quantity to one
+func (input *AddItemInput) Validate() error {
+	if input.ProductID <= 0 {
+		return ErrInvalidInput("product_id is required")
+	}
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+	if input.Quantity < 0 || input.Quantity > promotion.MaxQuantity {
+		return ErrInvalidInput(fmt.Sprintf("quantity must be between 1 and %d", promotion.MaxQuantity))
+	}
+	return nil
+}
+
+// Validate validates update item input
+func (input *UpdateItemInput) Validate() error {
+	if input.Quantity < 0 || input.Quantity > promotion.MaxQuantity {
+		return ErrInvalidInput(fmt.Sprintf("quantity must be between 0 and %d", promotion.MaxQuantity))
+	}
+	return nil
+}
+
+// scanItem scans a database row into an Item
+func scanItem(row interface{ Scan(...interface{}) error }) (*Item, error) {
+	var item Item
+
+	err := row.Scan(
+		&item.ID,
+		&item.ProductID,
+		&item.Name,
+		&item.Size,
+		&item.Color,
+		&item.Quantity,
+		&item.addedPrice,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	item.UnitPrice = item.addedPrice
+
+	return &item, nil
+}
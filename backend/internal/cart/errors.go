@@ -0,0 +1,22 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates a cart was not found or has expired
+	ErrNotFound = errors.New("cart not found")
+
+	// ErrItemNotFound indicates a cart item was not found
+	ErrItemNotFound = errors.New("cart item not found")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
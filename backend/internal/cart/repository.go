@@ -0,0 +1,30 @@
+package cart
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for cart data access
+type Repository interface {
+	// Create creates an empty cart with the given token
+	Create(ctx context.Context, token string) (*Cart, error)
+
+	// GetByToken retrieves a cart and its items, unless it was last updated before expiredBefore
+	GetByToken(ctx context.Context, token string, expiredBefore time.Time) (*Cart, error)
+
+	// AddItem adds a product to a cart, adding to the quantity of an existing item with the same size and color
+	AddItem(ctx context.Context, cartID int64, item *Item) error
+
+	// UpdateItem sets the quantity and current unit price of a cart item
+	UpdateItem(ctx context.Context, cartID, itemID int64, quantity, unitPrice int) error
+
+	// RemoveItem removes an item from a cart
+	RemoveItem(ctx context.Context, cartID, itemID int64) error
+
+	// SetCoupon sets or, with an empty code, clears the coupon of a cart
+	SetCoupon(ctx context.Context, cartID int64, code string) error
+
+	// DeleteExpired removes carts last updated before the cutoff and returns how many
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
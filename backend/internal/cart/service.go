@@ -0,0 +1,320 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Service provides business logic for shopping carts
+type Service struct {
+	repo       Repository
+	products   *product.Service
+	promotions *promotion.Service
+	ttl        time.Duration
+}
+
+// NewService creates a new cart service. Carts not changed for longer than
+// ttl are expired.
+func NewService(repo Repository, productService *product.Service, promotionService *promotion.Service, ttl time.Duration) *Service {
+	return &Service{
+		repo:       repo,
+		products:   productService,
+		promotions: promotionService,
+		ttl:        ttl,
+	}
+}
+
+// CreateCart creates an empty cart with a new random token
+func (s *Service) CreateCart(ctx context.Context) (*Cart, error) {
+	c, err := s.repo.Create(ctx, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.price(ctx, c)
+}
+
+// GetCart retrieves a cart priced at the current catalog prices
+func (s *Service) GetCart(ctx context.Context, token string) (*Cart, error) {
+	c, err := s.get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.price(ctx, c)
+}
+
+// AddItem adds a product in the given size and color to a cart. Adding a
+// product already in the cart increases its quantity.
+func (s *Service) AddItem(ctx context.Context, token string, input AddItemInput) (*Cart, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.products.GetProduct(ctx, input.ProductID)
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, ErrInvalidInput(fmt.Sprintf("product %d not found", input.ProductID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{ProductID: p.ID, Name: p.Name, Size: input.Size, Color: input.Color, Quantity: input.Quantity}
+	if err := resolve(p, item); err != nil {
+		return nil, err
+	}
+
+	for _, existing := range c.Items {
+		if existing.ProductID == item.ProductID && existing.Size == item.Size && existing.Color == item.Color &&
+			existing.Quantity+item.Quantity > promotion.MaxQuantity {
+			return nil, ErrInvalidInput(fmt.Sprintf("a cart holds at most %d units of each item", promotion.MaxQuantity))
+		}
+	}
+
+	if err := s.repo.AddItem(ctx, c.id, item); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, token)
+}
+
+// UpdateItem changes the quantity of a cart item, removing it when the
+// quantity is 0. The item takes the current price, clearing any price
+// change warning.
+func (s *Service) UpdateItem(ctx context.Context, token string, itemID int64, input UpdateItemInput) (*Cart, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if input.Quantity == 0 {
+		return s.RemoveItem(ctx, token, itemID)
+	}
+
+	c, err := s.get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *Item
+	for _, i := range c.Items {
+		if i.ID == itemID {
+			item = i
+		}
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+
+	p, err := s.products.GetProduct(ctx, item.ProductID)
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, ErrInvalidInput("product is no longer available")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := resolve(p, item); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateItem(ctx, c.id, itemID, input.Quantity, item.UnitPrice); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, token)
+}
+
+// RemoveItem removes an item from a cart
+func (s *Service) RemoveItem(ctx context.Context, token string, itemID int64) (*Cart, error) {
+	c, err := s.get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveItem(ctx, c.id, itemID); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, token)
+}
+
+// SetCoupon sets the coupon of a cart. An unusable coupon is kept and
+// reported in Cart.Coupon, so it applies once the cart qualifies.
+func (s *Service) SetCoupon(ctx context.Context, token, code string) (*Cart, error) {
+	code = promotion.NormalizeCode(code)
+	if code == "" {
+		return nil, ErrInvalidInput("code is required")
+	}
+
+	return s.setCoupon(ctx, token, code)
+}
+
+// RemoveCoupon clears the coupon of a cart
+func (s *Service) RemoveCoupon(ctx context.Context, token string) (*Cart, error) {
+	return s.setCoupon(ctx, token, "")
+}
+
+// PurgeExpired deletes the carts not changed within the TTL and returns how many
+func (s *Service) PurgeExpired(ctx context.Context) (int, error) {
+	return s.repo.DeleteExpired(ctx, time.Now().Add(-s.ttl))
+}
+
+func (s *Service) setCoupon(ctx context.Context, token, code string) (*Cart, error) {
+	c, err := s.get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetCoupon(ctx, c.id, code); err != nil {
+		return nil, err
+	}
+
+	return s.GetCart(ctx, token)
+}
+
+// get retrieves an unexpired cart without pricing it
+func (s *Service) get(ctx context.Context, token string) (*Cart, error) {
+	return s.repo.GetByToken(ctx, token, time.Now().Add(-s.ttl))
+}
+
+// price checks every item against the catalog, warns about the ones that
+// are no longer available or changed price, and computes the totals of the
+// rest with the current promotions
+func (s *Service) price(ctx context.Context, c *Cart) (*Cart, error) {
+	c.ExpiresAt = c.UpdatedAt.Add(s.ttl)
+	c.Warnings = []Warning{}
+
+	var available []*Item
+	var quoteItems []promotion.QuoteItemInput
+	cache := make(map[int64]*product.Product)
+
+	for _, item := range c.Items {
+		p, ok := cache[item.ProductID]
+		if !ok {
+			var err error
+			p, err = s.products.GetProduct(ctx, item.ProductID)
+			if err != nil && !errors.Is(err, product.ErrNotFound) {
+				return nil, err
+			}
+			cache[item.ProductID] = p
+		}
+
+		if p == nil {
+			c.Warnings = append(c.Warnings, Warning{
+				ItemID:    item.ID,
+				ProductID: item.ProductID,
+				Code:      WarningProductUnavailable,
+				Message:   fmt.Sprintf("%s is no longer available", item.Name),
+			})
+			continue
+		}
+
+		item.Name = p.Name
+		item.Slug = p.Slug
+		if len(p.Images) > 0 {
+			item.Image = p.Images[0]
+		}
+		if err := resolve(p, item); err != nil {
+			c.Warnings = append(c.Warnings, Warning{
+				ItemID:    item.ID,
+				ProductID: item.ProductID,
+				Code:      WarningVariantUnavailable,
+				Message:   fmt.Sprintf("%s is no longer available in %s", item.Name, option(item)),
+			})
+			continue
+		}
+
+		item.Available = true
+		available = append(available, item)
+		quoteItems = append(quoteItems, promotion.QuoteItemInput{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	if len(available) == 0 {
+		if c.couponCode != "" {
+			c.Coupon = &promotion.CouponResult{Code: c.couponCode, Error: "cart has no available items"}
+		}
+		return c, nil
+	}
+
+	q, err := s.promotions.Quote(ctx, promotion.QuoteInput{Items: quoteItems, Coupon: c.couponCode})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range q.Lines {
+		item := available[i]
+		item.UnitPrice = line.UnitPrice
+		item.Subtotal = line.Subtotal
+		item.Discount = line.Discount
+		item.Total = line.Total
+		item.Promotion = line.Promotion
+		c.ItemCount += item.Quantity
+
+		if item.UnitPrice != item.addedPrice {
+			c.Warnings = append(c.Warnings, Warning{
+				ItemID:    item.ID,
+				ProductID: item.ProductID,
+				Code:      WarningPriceChanged,
+				Message:   fmt.Sprintf("the price of %s changed", item.Name),
+				OldPrice:  item.addedPrice,
+				NewPrice:  item.UnitPrice,
+			})
+		}
+	}
+
+	c.Subtotal = q.Subtotal
+	c.Discount = q.Discount
+	c.Total = q.Total
+	c.Coupon = q.Coupon
+
+	return c, nil
+}
+
+// resolve matches the size and color of an item to the options the product
+// offers, normalizing their spelling, and sets the variant and current unit
+// price of the item
+func resolve(p *product.Product, item *Item) error {
+	sel, err := p.Select(item.Size, item.Color)
+	if err != nil {
+		return err
+	}
+
+	item.Size, item.Color = sel.Size, sel.Color
+	item.VariantID = nil
+	if sel.Variant != nil {
+		item.VariantID = &sel.Variant.ID
+	}
+	item.UnitPrice = sel.UnitPrice
+
+	return nil
+}
+
+// option describes the size and color of an item for messages
+func option(item *Item) string {
+	parts := make([]string, 0, 2)
+	if item.Size != "" {
+		parts = append(parts, "size "+item.Size)
+	}
+	if item.Color != "" {
+		parts = append(parts, item.Color)
+	}
+	if len(parts) == 0 {
+		return "this option"
+	}
+	return strings.Join(parts, ", ")
+}
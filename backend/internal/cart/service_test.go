@@ -0,0 +1,203 @@
+package cart_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	shirtPrice  = 500000
+	gauchoPrice = 550000
+	cartTTL     = time.Hour
+)
+
+type cartSuite struct {
+	suite.Suite
+	ctx      context.Context
+	products *product.Service
+	svc      *cart.Service
+	shirt    *product.Product // Without variants, 2 units in the cart
+	gaucho   *product.Product // With variants M and L, 1 unit of M in the cart
+	medium   *product.Variant
+	token    string
+}
+
+func TestCartSuite(t *testing.T) {
+	suite.Run(t, new(cartSuite))
+}
+
+func (s *cartSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.products = product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), s.products)
+	s.svc = cart.NewService(cart.NewSQLiteRepository(db), s.products, promotions, cartTTL)
+
+	var err error
+	s.shirt, err = s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera Lisa", Price: shirtPrice})
+	s.Require().NoError(err)
+	s.gaucho, err = s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Gauchito Gil", Price: gauchoPrice})
+	s.Require().NoError(err)
+	s.medium, err = s.products.CreateVariant(s.ctx, s.gaucho.ID, product.CreateVariantInput{Size: "M", Color: "Rojo", Stock: 5})
+	s.Require().NoError(err)
+	_, err = s.products.CreateVariant(s.ctx, s.gaucho.ID, product.CreateVariantInput{Size: "L", Color: "Rojo", Stock: 2})
+	s.Require().NoError(err)
+
+	c, err := s.svc.CreateCart(s.ctx)
+	s.Require().NoError(err)
+	s.token = c.Token
+	_, err = s.svc.AddItem(s.ctx, s.token, cart.AddItemInput{ProductID: s.shirt.ID, Quantity: 2})
+	s.Require().NoError(err)
+	_, err = s.svc.AddItem(s.ctx, s.token, cart.AddItemInput{ProductID: s.gaucho.ID, Size: "m", Color: "rojo", Quantity: 1})
+	s.Require().NoError(err)
+}
+
+// intPtr returns a pointer to an int
+func intPtr(v int) *int {
+	return &v
+}
+
+// cart reads the cart
+func (s *cartSuite) cart() *cart.Cart {
+	c, err := s.svc.GetCart(s.ctx, s.token)
+	s.Require().NoError(err)
+	return c
+}
+
+// item returns the cart item of a product
+func (s *cartSuite) item(c *cart.Cart, productID int64) *cart.Item {
+	for _, item := range c.Items {
+		if item.ProductID == productID {
+			return item
+		}
+	}
+	s.FailNow("item not in cart")
+	return nil
+}
+
+func (s *cartSuite) TestUnchangedCartHasNoWarnings() {
+	c := s.cart()
+
+	s.Empty(c.Warnings)
+	s.Equal(3, c.ItemCount)
+	s.Equal(2*shirtPrice+gauchoPrice, c.Total)
+	s.Equal("M", s.item(c, s.gaucho.ID).Size)
+	s.Equal("Rojo", s.item(c, s.gaucho.ID).Color)
+}
+
+var priceChangeCases = []struct {
+	name     string
+	update   product.UpdateProductInput
+	newPrice int
+}{
+	{name: "Price raised", update: product.UpdateProductInput{Price: intPtr(600000)}, newPrice: 600000},
+	{name: "Sale started", update: product.UpdateProductInput{SalePrice: intPtr(400000)}, newPrice: 400000},
+}
+
+func (s *cartSuite) TestPriceChangeWarns() {
+	for _, tc := range priceChangeCases {
+		s.SetupTest()
+		_, err := s.products.UpdateProduct(s.ctx, s.shirt.ID, tc.update)
+		s.Require().NoError(err, tc.name)
+
+		c := s.cart()
+
+		s.Require().Len(c.Warnings, 1, tc.name)
+		s.Equal(cart.Warning{
+			ItemID:    s.item(c, s.shirt.ID).ID,
+			ProductID: s.shirt.ID,
+			Code:      cart.WarningPriceChanged,
+			Message:   "the price of Remera Lisa changed",
+			OldPrice:  shirtPrice,
+			NewPrice:  tc.newPrice,
+		}, c.Warnings[0], tc.name)
+		s.Equal(2*tc.newPrice+gauchoPrice, c.Total, tc.name)
+	}
+}
+
+func (s *cartSuite) TestUpdatingItemAcceptsNewPrice() {
+	_, err := s.products.UpdateProduct(s.ctx, s.shirt.ID, product.UpdateProductInput{Price: intPtr(600000)})
+	s.Require().NoError(err)
+
+	c, err := s.svc.UpdateItem(s.ctx, s.token, s.item(s.cart(), s.shirt.ID).ID, cart.UpdateItemInput{Quantity: 3})
+
+	s.Require().NoError(err)
+	s.Empty(c.Warnings)
+	s.Equal(3*600000+gauchoPrice, c.Total)
+}
+
+var unavailableCases = []struct {
+	name      string
+	change    func(s *cartSuite)
+	gaucho    bool // The unavailable item is the gaucho rather than the shirt
+	code      string
+	message   string
+	wantTotal int
+}{
+	{
+		name:      "Product deleted",
+		change:    func(s *cartSuite) { s.Require().NoError(s.products.DeleteProduct(s.ctx, s.shirt.ID, 0)) },
+		code:      cart.WarningProductUnavailable,
+		message:   "Remera Lisa is no longer available",
+		wantTotal: gauchoPrice,
+	},
+	{
+		name: "Variant out of stock",
+		change: func(s *cartSuite) {
+			_, err := s.products.UpdateVariant(s.ctx, s.gaucho.ID, s.medium.ID, product.UpdateVariantInput{Stock: intPtr(0)})
+			s.Require().NoError(err)
+		},
+		gaucho:    true,
+		code:      cart.WarningVariantUnavailable,
+		message:   "Gauchito Gil is no longer available in size M, Rojo",
+		wantTotal: 2 * shirtPrice,
+	},
+	{
+		name:      "Variant removed",
+		change:    func(s *cartSuite) { s.Require().NoError(s.products.DeleteVariant(s.ctx, s.gaucho.ID, s.medium.ID)) },
+		gaucho:    true,
+		code:      cart.WarningVariantUnavailable,
+		message:   "Gauchito Gil is no longer available in size M, Rojo",
+		wantTotal: 2 * shirtPrice,
+	},
+}
+
+func (s *cartSuite) TestUnavailableItemsWarnAndLeaveTheTotals() {
+	for _, tc := range unavailableCases {
+		s.SetupTest()
+		tc.change(s)
+
+		c := s.cart()
+		productID := s.shirt.ID
+		if tc.gaucho {
+			productID = s.gaucho.ID
+		}
+		item := s.item(c, productID)
+
+		s.Require().Len(c.Warnings, 1, tc.name)
+		s.Equal(cart.Warning{ItemID: item.ID, ProductID: productID, Code: tc.code, Message: tc.message}, c.Warnings[0], tc.name)
+		s.False(item.Available, tc.name)
+		s.Len(c.Items, 2, tc.name)
+		s.Equal(tc.wantTotal, c.Total, tc.name)
+	}
+}
+
+func (s *cartSuite) TestRestoredProductIsAvailableAgain() {
+	s.Require().NoError(s.products.DeleteProduct(s.ctx, s.shirt.ID, 0))
+	_, err := s.products.RestoreProduct(s.ctx, s.shirt.ID, 0)
+	s.Require().NoError(err)
+
+	c := s.cart()
+
+	s.Empty(c.Warnings)
+	s.True(s.item(c, s.shirt.ID).Available)
+	s.Equal(2*shirtPrice+gauchoPrice, c.Total)
+}
@@ -0,0 +1,210 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite cart repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// Create creates an empty cart with the given token
+func (r *SQLiteRepository) Create(ctx context.Context, token string) (*Cart, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO carts (token, created_at, updated_at) VALUES (?, ?, ?)",
+		token, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cart: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return &Cart{id: id, Token: token, Items: []*Item{}, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// GetByToken retrieves a cart and its items, unless it was last updated before expiredBefore
+func (r *SQLiteRepository) GetByToken(ctx context.Context, token string, expiredBefore time.Time) (*Cart, error) {
+	var c Cart
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, token, coupon, created_at, updated_at FROM carts WHERE token = ? AND updated_at >= ?",
+		token, expiredBefore,
+	).Scan(&c.id, &c.Token, &c.couponCode, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, name, size, color, quantity, unit_price
+		FROM cart_items
+		WHERE cart_id = ?
+		ORDER BY id
+	`, c.id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cart items: %w", err)
+	}
+	defer rows.Close()
+
+	c.Items = []*Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		c.Items = append(c.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return &c, nil
+}
+
+// AddItem adds a product to a cart, adding to the quantity of an existing
+// item with the same size and color
+func (r *SQLiteRepository) AddItem(ctx context.Context, cartID int64, item *Item) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO cart_items (cart_id, product_id, name, size, color, quantity, unit_price, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (cart_id, product_id, size, color) DO UPDATE SET
+			quantity = quantity + excluded.quantity,
+			name = excluded.name,
+			unit_price = excluded.unit_price,
+			updated_at = excluded.updated_at
+	`, cartID, item.ProductID, item.Name, item.Size, item.Color, item.Quantity, item.UnitPrice, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to add cart item: %w", err)
+	}
+
+	if err := touch(ctx, tx, cartID, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cart item: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateItem sets the quantity and current unit price of a cart item
+func (r *SQLiteRepository) UpdateItem(ctx context.Context, cartID, itemID int64, quantity, unitPrice int) error {
+	return r.changeItem(ctx, cartID,
+		"UPDATE cart_items SET quantity = ?, unit_price = ?, updated_at = ? WHERE id = ? AND cart_id = ?",
+		quantity, unitPrice, time.Now(), itemID, cartID,
+	)
+}
+
+// RemoveItem removes an item from a cart
+func (r *SQLiteRepository) RemoveItem(ctx context.Context, cartID, itemID int64) error {
+	return r.changeItem(ctx, cartID, "DELETE FROM cart_items WHERE id = ? AND cart_id = ?", itemID, cartID)
+}
+
+// changeItem runs a statement on a single cart item and touches the cart
+func (r *SQLiteRepository) changeItem(ctx context.Context, cartID int64, query string, args ...interface{}) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to change cart item: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrItemNotFound
+	}
+
+	if err := touch(ctx, tx, cartID, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cart item change: %w", err)
+	}
+
+	return nil
+}
+
+// SetCoupon sets or, with an empty code, clears the coupon of a cart
+func (r *SQLiteRepository) SetCoupon(ctx context.Context, cartID int64, code string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE carts SET coupon = ?, updated_at = ? WHERE id = ?",
+		code, time.Now(), cartID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set cart coupon: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes carts last updated before the cutoff and returns how many
+func (r *SQLiteRepository) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM cart_items WHERE cart_id IN (SELECT id FROM carts WHERE updated_at < ?)",
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired cart items: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM carts WHERE updated_at < ?", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired carts: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit expired cart deletion: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+// touch extends the life of a cart after a change
+func touch(ctx context.Context, tx *sql.Tx, cartID int64, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE carts SET updated_at = ? WHERE id = ?", now, cartID); err != nil {
+		return fmt.Errorf("failed to update cart: %w", err)
+	}
+	return nil
+}
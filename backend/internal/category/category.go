@@ -0,0 +1,136 @@
+package category
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Category represents a product category in the catalog tree
+type Category struct {
+	ID           int64       `json:"id"`
+	Slug         string      `json:"slug"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	ParentID     *int64      `json:"parent_id,omitempty"`
+	SortOrder    int         `json:"sort_order"`
+	CoverImage   string      `json:"cover_image"`
+	ProductCount int         `json:"product_count"` // Includes products of subcategories in the tree
+	Children     []*Category `json:"children,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// CreateCategoryInput represents input for creating a category
+type CreateCategoryInput struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
+	SortOrder   int    `json:"sort_order"`
+	CoverImage  string `json:"cover_image"`
+}
+
+// UpdateCategoryInput represents input for updating a category.
+// A parent_id of 0 moves the category to the top level.
+type UpdateCategoryInput struct {
+	Slug        *string `json:"slug,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	ParentID    *int64  `json:"parent_id,omitempty"`
+	SortOrder   *int    `json:"sort_order,omitempty"`
+	CoverImage  *string `json:"cover_image,omitempty"`
+}
+
+// Validate validates category creation input and derives the slug from the name when empty
+func (input *CreateCategoryInput) Validate() error {
+	if input.Name == "" {
+		return ErrInvalidInput("name is required")
+	}
+	if input.Slug == "" {
+		input.Slug = input.Name
+	}
+	input.Slug = slug.Make(input.Slug)
+	if input.Slug == "" {
+		return ErrInvalidInput("slug must contain letters or digits")
+	}
+	return nil
+}
+
+// Validate validates category update input and normalizes the slug
+func (input *UpdateCategoryInput) Validate() error {
+	if input.Name != nil && *input.Name == "" {
+		return ErrInvalidInput("name cannot be empty")
+	}
+	if input.Slug != nil {
+		normalized := slug.Make(*input.Slug)
+		if normalized == "" {
+			return ErrInvalidInput("slug must contain letters or digits")
+		}
+		input.Slug = &normalized
+	}
+	return nil
+}
+
+// buildTree nests a flat, ordered category list under its parents and
+// rolls product counts up so each node includes its descendants
+func buildTree(categories []*Category) []*Category {
+	byID := make(map[int64]*Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	roots := []*Category{}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if parent, ok := byID[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+
+	for _, root := range roots {
+		rollUpCounts(root)
+	}
+
+	return roots
+}
+
+// rollUpCounts adds the product counts of all descendants to c and returns the total
+func rollUpCounts(c *Category) int {
+	for _, child := range c.Children {
+		c.ProductCount += rollUpCounts(child)
+	}
+	return c.ProductCount
+}
+
+// scanCategory scans a database row into a Category
+func scanCategory(row interface{ Scan(...interface{}) error }) (*Category, error) {
+	var c Category
+	var parentID sql.NullInt64
+
+	err := row.Scan(
+		&c.ID,
+		&c.Slug,
+		&c.Name,
+		&c.Description,
+		&parentID,
+		&c.SortOrder,
+		&c.CoverImage,
+		&c.ProductCount,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
+
+	return &c, nil
+}
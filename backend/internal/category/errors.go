@@ -0,0 +1,22 @@
+package category
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates a category was not found
+	ErrNotFound = errors.New("category not found")
+
+	// ErrDuplicateSlug indicates another category already uses the slug
+	ErrDuplicateSlug = errors.New("category slug already exists")
+
+	// ErrInUse indicates the category still has products or subcategories
+	ErrInUse = errors.New("category has products or subcategories")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("invalid input: %s", msg)
+	}
+)
@@ -0,0 +1,21 @@
+package category
+
+import "context"
+
+// Repository defines the interface for category data access
+type Repository interface {
+	// GetAll retrieves all categories ordered by sort order and name, with direct product counts
+	GetAll(ctx context.Context) ([]*Category, error)
+
+	// GetByID retrieves a single category by ID
+	GetByID(ctx context.Context, id int64) (*Category, error)
+
+	// Create creates a new category
+	Create(ctx context.Context, input CreateCategoryInput) (*Category, error)
+
+	// Update updates an existing category and renames it on its products
+	Update(ctx context.Context, id int64, input UpdateCategoryInput) (*Category, error)
+
+	// Delete removes a category without products or subcategories
+	Delete(ctx context.Context, id int64) error
+}
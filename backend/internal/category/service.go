@@ -0,0 +1,96 @@
+package category
+
+import "context"
+
+// Service provides business logic for categories
+type Service struct {
+	repo Repository
+}
+
+// NewService creates a new category service
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// GetCategories retrieves the flat category list
+func (s *Service) GetCategories(ctx context.Context) ([]*Category, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetTree retrieves categories nested under their parents with rolled-up product counts
+func (s *Service) GetTree(ctx context.Context) ([]*Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(categories), nil
+}
+
+// GetCategory retrieves a single category by ID
+func (s *Service) GetCategory(ctx context.Context, id int64) (*Category, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// CreateCategory creates a new category with validation
+func (s *Service) CreateCategory(ctx context.Context, input CreateCategoryInput) (*Category, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil {
+		if _, err := s.repo.GetByID(ctx, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Create(ctx, input)
+}
+
+// UpdateCategory updates a category, rejecting parents that would create a cycle
+func (s *Service) UpdateCategory(ctx context.Context, id int64, input UpdateCategoryInput) (*Category, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil && *input.ParentID != 0 {
+		if err := s.checkParent(ctx, id, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Update(ctx, id, input)
+}
+
+// DeleteCategory removes a category
+func (s *Service) DeleteCategory(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// checkParent ensures parentID exists and is neither id nor one of its descendants
+func (s *Service) checkParent(ctx context.Context, id, parentID int64) error {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	parents := make(map[int64]*int64, len(categories))
+	for _, c := range categories {
+		parents[c.ID] = c.ParentID
+	}
+
+	if _, ok := parents[parentID]; !ok {
+		return ErrNotFound
+	}
+
+	// Walk up from the new parent; reaching id means a cycle
+	current := &parentID
+	for steps := 0; current != nil && steps <= len(categories); steps++ {
+		if *current == id {
+			return ErrInvalidInput("category cannot be nested under itself or a subcategory")
+		}
+		current = parents[*current]
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package category_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/category"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+type categorySuite struct {
+	suite.Suite
+	ctx      context.Context
+	svc      *category.Service
+	products *product.Service
+}
+
+func TestCategorySuite(t *testing.T) {
+	suite.Run(t, new(categorySuite))
+}
+
+func (s *categorySuite) SetupTest() {
+	s.ctx = context.Background()
+	db := testdouble.NewDB(s.T())
+	s.svc = category.NewService(category.NewSQLiteRepository(db))
+	s.products = product.NewService(product.NewSQLiteRepository(db))
+}
+
+// create creates a category, under a parent when given one
+func (s *categorySuite) create(name string, parent *category.Category) *category.Category {
+	input := category.CreateCategoryInput{Name: name}
+	if parent != nil {
+		input.ParentID = &parent.ID
+	}
+	c, err := s.svc.CreateCategory(s.ctx, input)
+	s.Require().NoError(err)
+	return c
+}
+
+// addProduct creates a product in a category
+func (s *categorySuite) addProduct(name string, c *category.Category) *product.Product {
+	p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: name, Price: 550000, CategoryID: &c.ID})
+	s.Require().NoError(err)
+	return p
+}
+
+func (s *categorySuite) TestSlugIsDerivedFromName() {
+	c := s.create("Santos Populares", nil)
+
+	_, duplicateErr := s.svc.CreateCategory(s.ctx, category.CreateCategoryInput{Name: "Otra", Slug: "Santos  Populares"})
+	_, emptyErr := s.svc.CreateCategory(s.ctx, category.CreateCategoryInput{Name: "Otra", Slug: "¡!"})
+
+	s.Equal("santos-populares", c.Slug)
+	s.ErrorIs(duplicateErr, category.ErrDuplicateSlug)
+	s.Error(emptyErr)
+}
+
+func (s *categorySuite) TestTreeRollsUpProductCounts() {
+	shirts := s.create("Remeras", nil)
+	saints := s.create("Santos", shirts)
+	virgins := s.create("Vírgenes", shirts)
+	s.create("Tazas", nil)
+	s.addProduct("Remera Lisa", shirts)
+	s.addProduct("Gauchito Gil", saints)
+	s.addProduct("Virgen María", virgins)
+	trashed := s.addProduct("Virgen de Luján", virgins)
+	s.Require().NoError(s.products.DeleteProduct(s.ctx, trashed.ID, 0))
+
+	tree, err := s.svc.GetTree(s.ctx)
+
+	s.Require().NoError(err)
+	s.Require().Len(tree, 2)
+	s.Equal("Remeras", tree[0].Name)
+	s.Equal(3, tree[0].ProductCount)
+	s.Require().Len(tree[0].Children, 2)
+	s.Equal("Santos", tree[0].Children[0].Name)
+	s.Equal(1, tree[0].Children[0].ProductCount)
+	s.Equal("Vírgenes", tree[0].Children[1].Name)
+	s.Equal(1, tree[0].Children[1].ProductCount)
+	s.Equal("Tazas", tree[1].Name)
+	s.Zero(tree[1].ProductCount)
+}
+
+func (s *categorySuite) TestParentCannotCreateCycle() {
+	shirts := s.create("Remeras", nil)
+	saints := s.create("Santos", shirts)
+	gauchos := s.create("Gauchitos", saints)
+
+	_, selfErr := s.svc.UpdateCategory(s.ctx, shirts.ID, category.UpdateCategoryInput{ParentID: &shirts.ID})
+	_, descendantErr := s.svc.UpdateCategory(s.ctx, shirts.ID, category.UpdateCategoryInput{ParentID: &gauchos.ID})
+	missing := int64(999)
+	_, missingErr := s.svc.UpdateCategory(s.ctx, shirts.ID, category.UpdateCategoryInput{ParentID: &missing})
+	top := int64(0)
+	moved, movedErr := s.svc.UpdateCategory(s.ctx, gauchos.ID, category.UpdateCategoryInput{ParentID: &top})
+
+	s.ErrorContains(selfErr, "invalid input")
+	s.ErrorContains(descendantErr, "invalid input")
+	s.ErrorIs(missingErr, category.ErrNotFound)
+	s.Require().NoError(movedErr)
+	s.Nil(moved.ParentID)
+}
+
+func (s *categorySuite) TestRenameUpdatesProducts() {
+	shirts := s.create("Remeras", nil)
+	p := s.addProduct("Remera Lisa", shirts)
+	name := "Remeras de Algodón"
+
+	_, err := s.svc.UpdateCategory(s.ctx, shirts.ID, category.UpdateCategoryInput{Name: &name})
+	s.Require().NoError(err)
+	renamed, getErr := s.products.GetProduct(s.ctx, p.ID)
+
+	s.Require().NoError(getErr)
+	s.Equal(name, renamed.Category)
+}
+
+func (s *categorySuite) TestCategoryFilterIncludesSubcategories() {
+	shirts := s.create("Remeras", nil)
+	saints := s.create("Santos", shirts)
+	mugs := s.create("Tazas", nil)
+	s.addProduct("Remera Lisa", shirts)
+	s.addProduct("Gauchito Gil", saints)
+	s.addProduct("Taza Gauchito", mugs)
+
+	bySlug, _, slugErr := s.products.GetProducts(s.ctx, product.GetAllFilters{Category: "remeras", Sort: "name", Order: "asc"})
+	byName, _, nameErr := s.products.GetProducts(s.ctx, product.GetAllFilters{Category: "Santos"})
+
+	s.Require().NoError(slugErr)
+	s.Require().Len(bySlug, 2)
+	s.Equal("Gauchito Gil", bySlug[0].Name)
+	s.Equal("Remera Lisa", bySlug[1].Name)
+	s.Require().NoError(nameErr)
+	s.Require().Len(byName, 1)
+	s.Equal("Gauchito Gil", byName[0].Name)
+}
+
+func (s *categorySuite) TestDeleteRefusesCategoriesInUse() {
+	shirts := s.create("Remeras", nil)
+	saints := s.create("Santos", shirts)
+	mugs := s.create("Tazas", nil)
+	s.addProduct("Gauchito Gil", saints)
+
+	parentErr := s.svc.DeleteCategory(s.ctx, shirts.ID)
+	productsErr := s.svc.DeleteCategory(s.ctx, saints.ID)
+	emptyErr := s.svc.DeleteCategory(s.ctx, mugs.ID)
+	_, getErr := s.svc.GetCategory(s.ctx, mugs.ID)
+
+	s.ErrorIs(parentErr, category.ErrInUse)
+	s.ErrorIs(productsErr, category.ErrInUse)
+	s.NoError(emptyErr)
+	s.ErrorIs(getErr, category.ErrNotFound)
+}
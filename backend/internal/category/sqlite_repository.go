@@ -0,0 +1,202 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const categoryColumns = `id, slug, name, description, parent_id, sort_order, cover_image,
+	(SELECT COUNT(*) FROM products WHERE products.category_id = categories.id AND products.deleted_at IS NULL) AS product_count,
+	created_at, updated_at`
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite category repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// GetAll retrieves all categories ordered by sort order and name, with direct product counts
+func (r *SQLiteRepository) GetAll(ctx context.Context) ([]*Category, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM categories
+		ORDER BY sort_order ASC, name ASC
+	`, categoryColumns)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*Category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return categories, nil
+}
+
+// GetByID retrieves a single category by ID
+func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Category, error) {
+	query := fmt.Sprintf("SELECT %s FROM categories WHERE id = ?", categoryColumns)
+
+	c, err := scanCategory(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return c, nil
+}
+
+// Create creates a new category
+func (r *SQLiteRepository) Create(ctx context.Context, input CreateCategoryInput) (*Category, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO categories (slug, name, description, parent_id, sort_order, cover_image, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Slug, input.Name, input.Description, input.ParentID, input.SortOrder, input.CoverImage, now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateSlug
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Update updates an existing category and renames it on its products
+func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdateCategoryInput) (*Category, error) {
+	// Check if category exists
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// Build UPDATE query dynamically
+	var setClauses []string
+	var args []interface{}
+
+	if input.Slug != nil {
+		setClauses = append(setClauses, "slug = ?")
+		args = append(args, *input.Slug)
+	}
+	if input.Name != nil {
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, *input.Name)
+	}
+	if input.Description != nil {
+		setClauses = append(setClauses, "description = ?")
+		args = append(args, *input.Description)
+	}
+	if input.ParentID != nil {
+		setClauses = append(setClauses, "parent_id = ?")
+		if *input.ParentID == 0 {
+			args = append(args, nil)
+		} else {
+			args = append(args, *input.ParentID)
+		}
+	}
+	if input.SortOrder != nil {
+		setClauses = append(setClauses, "sort_order = ?")
+		args = append(args, *input.SortOrder)
+	}
+	if input.CoverImage != nil {
+		setClauses = append(setClauses, "cover_image = ?")
+		args = append(args, *input.CoverImage)
+	}
+
+	// Always update updated_at
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, time.Now())
+
+	args = append(args, id)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("UPDATE categories SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateSlug
+		}
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	// Keep the denormalized category name on products in sync
+	if input.Name != nil {
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET category = ? WHERE category_id = ?", *input.Name, id); err != nil {
+			return nil, fmt.Errorf("failed to rename category on products: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit category update: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes a category without products or subcategories
+func (r *SQLiteRepository) Delete(ctx context.Context, id int64) error {
+	c, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var children int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM categories WHERE parent_id = ?", id).Scan(&children); err != nil {
+		return fmt.Errorf("failed to count subcategories: %w", err)
+	}
+	if children > 0 || c.ProductCount > 0 {
+		return ErrInUse
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Soft-deleted products keep their category name but lose the link
+	if _, err := tx.ExecContext(ctx, "UPDATE products SET category_id = NULL WHERE category_id = ?", id); err != nil {
+		return fmt.Errorf("failed to unlink products: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit category deletion: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotConfigured indicates the WhatsApp checkout has no phone number to send orders to
+	ErrNotConfigured = errors.New("whatsapp checkout is not configured")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
@@ -0,0 +1,121 @@
+package checkout
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/order"
+)
+
+// Service turns carts, product selections and designs into orders handed off to WhatsApp
+type Service struct {
+	orders  *order.Service
+	carts   *cart.Service
+	designs *design.Service
+	phone   string
+	message *template.Template
+}
+
+// NewService creates a new checkout service. Orders are sent to the WhatsApp
+// phone number with a message rendered from messageTemplate, or
+// DefaultMessageTemplate when empty.
+func NewService(orderService *order.Service, cartService *cart.Service, designService *design.Service, phone, messageTemplate string) (*Service, error) {
+	message, err := parseMessageTemplate(messageTemplate)
+	if err == nil {
+		// Catch references to unknown fields now rather than after placing an order
+		err = message.Execute(io.Discard, &order.Order{Items: []*order.Item{{}}})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid WhatsApp message template: %w", err)
+	}
+
+	return &Service{
+		orders:  orderService,
+		carts:   cartService,
+		designs: designService,
+		phone:   strings.TrimPrefix(strings.ReplaceAll(phone, " ", ""), "+"),
+		message: message,
+	}, nil
+}
+
+// WhatsApp places a pending order for a cart, a single product or a design
+// and returns the wa.me link the customer follows to send it. Unavailable
+// cart items are left out; the cart itself is kept.
+func (s *Service) WhatsApp(ctx context.Context, input WhatsAppInput) (*WhatsAppCheckout, error) {
+	if s.phone == "" {
+		return nil, ErrNotConfigured
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	orderInput := order.CreateOrderInput{
+		Customer:   input.Customer,
+		Notes:      input.Notes,
+		Source:     order.SourceWhatsApp,
+		CustomerID: input.CustomerID,
+	}
+
+	switch {
+	case input.CartToken != "":
+		c, err := s.carts.GetCart(ctx, input.CartToken)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range c.Items {
+			if item.Available {
+				orderInput.Items = append(orderInput.Items, order.CreateItemInput{
+					ProductID: item.ProductID,
+					Size:      item.Size,
+					Color:     item.Color,
+					Quantity:  item.Quantity,
+				})
+			}
+		}
+		if len(orderInput.Items) == 0 {
+			return nil, ErrInvalidInput("cart has no available items")
+		}
+		if c.Coupon != nil && c.Coupon.Valid {
+			orderInput.Coupon = c.Coupon.Code
+		}
+	case input.DesignToken != "":
+		item, err := s.designs.OrderItem(ctx, input.DesignToken, input.Size, input.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		orderInput.Items = []order.CreateItemInput{*item}
+		orderInput.Coupon = input.Coupon
+	default:
+		orderInput.Items = []order.CreateItemInput{{
+			ProductID: input.ProductID,
+			Size:      input.Size,
+			Color:     input.Color,
+			Quantity:  input.Quantity,
+		}}
+		orderInput.Coupon = input.Coupon
+	}
+
+	o, err := s.orders.CreateOrder(ctx, orderInput)
+	if err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	if err := s.message.Execute(&message, o); err != nil {
+		return nil, fmt.Errorf("failed to render WhatsApp message: %w", err)
+	}
+
+	return &WhatsAppCheckout{
+		Order:   o,
+		Message: message.String(),
+		URL:     fmt.Sprintf("https://wa.me/%s?text=%s", s.phone, strings.ReplaceAll(url.QueryEscape(message.String()), "+", "%20")),
+	}, nil
+}
@@ -0,0 +1,98 @@
+package checkout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/checkout"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	shopPhone     = "5491123456789"
+	priceTemplate = "{{price .Total}}"
+	holdFor       = 15 * time.Minute
+)
+
+type whatsAppSuite struct {
+	suite.Suite
+	ctx      context.Context
+	products *product.Service
+	orders   *order.Service
+	carts    *cart.Service
+}
+
+func TestWhatsAppSuite(t *testing.T) {
+	suite.Run(t, new(whatsAppSuite))
+}
+
+func (s *whatsAppSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.products = product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), s.products)
+	s.orders = order.NewService(order.NewSQLiteRepository(db), s.products, promotions, holdFor)
+	s.carts = cart.NewService(cart.NewSQLiteRepository(db), s.products, promotions, time.Hour)
+}
+
+// checkout places a WhatsApp order for a product at a price with a message template
+func (s *whatsAppSuite) checkout(template string, price, quantity int) *checkout.WhatsAppCheckout {
+	svc, err := checkout.NewService(s.orders, s.carts, nil, shopPhone, template)
+	s.Require().NoError(err)
+	p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera", Price: price})
+	s.Require().NoError(err)
+
+	result, err := svc.WhatsApp(s.ctx, checkout.WhatsAppInput{
+		ProductID: p.ID,
+		Quantity:  quantity,
+		Customer:  order.Customer{Name: "Ana"},
+	})
+	s.Require().NoError(err)
+	return result
+}
+
+var priceCases = []struct {
+	name     string
+	price    int
+	quantity int
+	want     string
+}{
+	{name: "Whole pesos", price: 1250000, quantity: 1, want: "$12.500"},
+	{name: "With cents", price: 1250050, quantity: 1, want: "$12.500,50"},
+	{name: "Cents below ten", price: 1250005, quantity: 1, want: "$12.500,05"},
+	{name: "Below a peso", price: 99, quantity: 1, want: "$0,99"},
+	{name: "Millions", price: 50000000, quantity: 3, want: "$1.500.000"},
+}
+
+func (s *whatsAppSuite) TestMessagePricesInPesos() {
+	for _, tc := range priceCases {
+		result := s.checkout(priceTemplate, tc.price, tc.quantity)
+
+		s.Equal(tc.want, result.Message, tc.name)
+	}
+}
+
+func (s *whatsAppSuite) TestDefaultMessage() {
+	result := s.checkout("", 1250050, 2)
+
+	s.Contains(result.Message, "• 2 x Remera: $25.001\n")
+	s.Contains(result.Message, "Total: $25.001")
+	s.Contains(result.Message, "Soy Ana.")
+	s.Contains(result.URL, "https://wa.me/"+shopPhone+"?text=")
+}
+
+func (s *whatsAppSuite) TestNotConfigured() {
+	svc, err := checkout.NewService(s.orders, s.carts, nil, "", "")
+	s.Require().NoError(err)
+
+	_, err = svc.WhatsApp(s.ctx, checkout.WhatsAppInput{ProductID: 1, Customer: order.Customer{Name: "Ana"}})
+
+	s.ErrorIs(err, checkout.ErrNotConfigured)
+}
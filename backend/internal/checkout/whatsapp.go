@@ -0,0 +1,104 @@
+package checkout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/tomas/tienda-backend/internal/order"
+)
+
+// DefaultMessageTemplate is the WhatsApp message used when none is configured.
+// Templates receive the *order.Order and a price function formatting amounts
+// like the storefront.
+const DefaultMessageTemplate = `Hola! Quiero hacer el pedido {{.Reference}}:
+{{range .Items}}
+• {{.Quantity}} x {{.Name}}{{with .Size}} - Talle {{.}}{{end}}{{with .Color}} - {{.}}{{end}}: {{price .Subtotal}}{{with .Design}}
+  Diseño personalizado: {{.ImageURL}}{{end}}{{end}}
+{{if .Discount}}
+Descuento: -{{price .Discount}}{{end}}
+Total: {{price .Total}}{{with .Customer.Name}}
+
+Soy {{.}}.{{end}}`
+
+// WhatsAppInput represents input for checking out through WhatsApp: either a
+// cart, a single product selection or a custom design in a size
+type WhatsAppInput struct {
+	CartToken string `json:"cart_token,omitempty"`
+
+	ProductID   int64  `json:"product_id,omitempty"`
+	DesignToken string `json:"design_token,omitempty"` // Printed in its shirt color, so color is ignored
+	Size        string `json:"size,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Quantity    int    `json:"quantity,omitempty"`
+	Coupon      string `json:"coupon,omitempty"`
+
+	Customer order.Customer `json:"customer"`
+	Notes    string         `json:"notes,omitempty"`
+
+	CustomerID int64 `json:"-"` // Taken from the signed-in customer placing the order
+}
+
+// WhatsAppCheckout is a placed order and the link opening the shop's
+// WhatsApp chat with the order message pre-filled
+type WhatsAppCheckout struct {
+	Order   *order.Order `json:"order"`
+	Message string       `json:"message"`
+	URL     string       `json:"url"`
+}
+
+// Validate validates WhatsApp checkout input, defaulting the quantity to one
+func (input *WhatsAppInput) Validate() error {
+	input.CartToken = strings.TrimSpace(input.CartToken)
+	input.DesignToken = strings.TrimSpace(input.DesignToken)
+
+	sources := 0
+	for _, set := range []bool{input.CartToken != "", input.ProductID > 0, input.DesignToken != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return ErrInvalidInput("send only one of cart_token, product_id or design_token")
+	}
+	if sources == 0 {
+		return ErrInvalidInput("cart_token, product_id or design_token is required")
+	}
+	if input.CartToken == "" && input.Quantity == 0 {
+		input.Quantity = 1
+	}
+	return nil
+}
+
+// parseMessageTemplate parses a WhatsApp message template, falling back to the default
+func parseMessageTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultMessageTemplate
+	}
+	return template.New("whatsapp").Funcs(template.FuncMap{"price": formatPrice}).Parse(text)
+}
+
+// formatPrice formats an amount in cents the way the storefront shows it,
+// e.g. $12.500 or $12.500,50 when there are cents
+func formatPrice(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.Itoa(amount / 100)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(d)
+	}
+	if cents := amount % 100; cents != 0 {
+		fmt.Fprintf(&b, ",%02d", cents)
+	}
+
+	return sign + "$" + b.String()
+}
@@ -0,0 +1,228 @@
+package customer
+
+import (
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Account limits
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // bcrypt ignores anything longer
+	MaxAddresses      = 10
+)
+
+// Customer is a shopper account. Customers sign in apart from admins and
+// only reach their own profile, orders and designs.
+type Customer struct {
+	ID            int64      `json:"id"`
+	Email         string     `json:"email"`
+	PasswordHash  string     `json:"-"` // Never expose password hash
+	Name          string     `json:"name"`
+	Phone         string     `json:"phone,omitempty"`
+	EmailVerified bool       `json:"email_verified"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	Addresses     []*Address `json:"addresses"` // Default address first
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Address is a saved shipping address, with the fields of an order's
+// customer data so checkouts can be filled from it
+type Address struct {
+	ID         int64     `json:"id"`
+	Label      string    `json:"label,omitempty"`     // e.g. Casa or Trabajo
+	Recipient  string    `json:"recipient,omitempty"` // Who receives the parcel, when not the customer
+	Phone      string    `json:"phone,omitempty"`
+	Address    string    `json:"address"`
+	City       string    `json:"city,omitempty"`
+	Province   string    `json:"province,omitempty"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	IsDefault  bool      `json:"is_default"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RegisterInput represents input for creating an account
+type RegisterInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// LoginInput represents customer login credentials
+type LoginInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// LoginResponse represents the login response with token
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Customer  *Customer `json:"customer"`
+}
+
+// UpdateProfileInput represents input for updating a profile
+type UpdateProfileInput struct {
+	Name  *string `json:"name,omitempty"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+// AddressInput represents input for saving an address. Updates replace
+// every field.
+type AddressInput struct {
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Address    string `json:"address"`
+	City       string `json:"city"`
+	Province   string `json:"province"`
+	PostalCode string `json:"postal_code"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+// VerifyInput represents input for verifying an email
+type VerifyInput struct {
+	Token string `json:"token"`
+}
+
+// ResendVerificationInput represents a request for a new verification email
+type ResendVerificationInput struct {
+	Email string `json:"email"`
+}
+
+// Validate validates registration input, trimming the email, name and phone
+func (input *RegisterInput) Validate() error {
+	email, err := normalizeEmail(input.Email)
+	if err != nil {
+		return err
+	}
+	input.Email = email
+
+	if len(input.Password) < MinPasswordLength || len(input.Password) > MaxPasswordLength {
+		return ErrInvalidInput(fmt.Sprintf("password must have between %d and %d characters", MinPasswordLength, MaxPasswordLength))
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return ErrInvalidInput("name is required")
+	}
+	input.Phone = strings.TrimSpace(input.Phone)
+	return nil
+}
+
+// Validate validates login input
+func (input *LoginInput) Validate() error {
+	input.Email = strings.TrimSpace(input.Email)
+	if input.Email == "" || input.Password == "" {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// Validate validates a verification email request, trimming the email
+func (input *ResendVerificationInput) Validate() error {
+	email, err := normalizeEmail(input.Email)
+	if err != nil {
+		return err
+	}
+	input.Email = email
+	return nil
+}
+
+// Validate validates profile update input
+func (input *UpdateProfileInput) Validate() error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return ErrInvalidInput("name cannot be empty")
+		}
+		input.Name = &name
+	}
+	if input.Phone != nil {
+		phone := strings.TrimSpace(*input.Phone)
+		input.Phone = &phone
+	}
+	return nil
+}
+
+// Validate validates address input, trimming every field
+func (input *AddressInput) Validate() error {
+	for _, field := range []*string{&input.Label, &input.Recipient, &input.Phone, &input.Address, &input.City, &input.Province, &input.PostalCode} {
+		*field = strings.TrimSpace(*field)
+	}
+	if input.Address == "" {
+		return ErrInvalidInput("address is required")
+	}
+	return nil
+}
+
+// normalizeEmail validates a bare email address and returns it trimmed
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", ErrInvalidInput("email is required")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", ErrInvalidInput("email is invalid")
+	}
+	return email, nil
+}
+
+// scanCustomer scans a database row into a Customer
+func scanCustomer(row interface{ Scan(...interface{}) error }) (*Customer, error) {
+	var c Customer
+	var verifiedAt sql.NullTime
+
+	err := row.Scan(
+		&c.ID,
+		&c.Email,
+		&c.PasswordHash,
+		&c.Name,
+		&c.Phone,
+		&verifiedAt,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifiedAt.Valid {
+		c.EmailVerified = true
+		c.VerifiedAt = &verifiedAt.Time
+	}
+	c.Addresses = []*Address{}
+
+	return &c, nil
+}
+
+// scanAddress scans a database row into an Address
+func scanAddress(row interface{ Scan(...interface{}) error }) (*Address, error) {
+	var a Address
+
+	err := row.Scan(
+		&a.ID,
+		&a.Label,
+		&a.Recipient,
+		&a.Phone,
+		&a.Address,
+		&a.City,
+		&a.Province,
+		&a.PostalCode,
+		&a.IsDefault,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,37 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates a customer was not found
+	ErrNotFound = errors.New("customer not found")
+
+	// ErrAddressNotFound indicates a saved address was not found
+	ErrAddressNotFound = errors.New("address not found")
+
+	// ErrEmailTaken indicates another account already uses the email
+	ErrEmailTaken = errors.New("email is already registered")
+
+	// ErrInvalidCredentials indicates a wrong email or password
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrEmailNotVerified indicates a customer signing in before verifying their email
+	ErrEmailNotVerified = errors.New("email is not verified")
+
+	// ErrInvalidToken indicates an invalid customer JWT
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrInvalidVerification indicates an unknown or expired email verification token
+	ErrInvalidVerification = errors.New("verification link is invalid or expired")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
@@ -0,0 +1,87 @@
+package customer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// audience marks customer tokens
+const audience = "customer"
+
+// Claims represents customer JWT claims
+type Claims struct {
+	CustomerID int64  `json:"customer_id"`
+	Email      string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// JWTManager handles customer JWT generation and validation. Customer tokens
+// are signed with a key derived from the secret, so they never pass for
+// admin tokens, nor admin tokens for them, although both come from
+// JWT_SECRET.
+type JWTManager struct {
+	key         []byte
+	expiryHours int
+}
+
+// NewJWTManager creates a new customer JWT manager
+func NewJWTManager(secret string, expiryHours int) *JWTManager {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("customer tokens"))
+
+	return &JWTManager{
+		key:         mac.Sum(nil),
+		expiryHours: expiryHours,
+	}
+}
+
+// Generate creates a new JWT token for a customer
+func (m *JWTManager) Generate(c *Customer) (string, time.Time, error) {
+	expiresAt := time.Now().Add(time.Duration(m.expiryHours) * time.Hour)
+
+	claims := Claims{
+		CustomerID: c.ID,
+		Email:      c.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(c.ID, 10),
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(m.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// Validate parses and validates a customer JWT token
+func (m *JWTManager) Validate(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		// Verify signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return m.key, nil
+	}, jwt.WithAudience(audience))
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.CustomerID <= 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,40 @@
+package customer
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for customer data access
+type Repository interface {
+	// Create stores a customer with the password already hashed. It fails with ErrEmailTaken
+	// when another account uses the email.
+	Create(ctx context.Context, c *Customer) (*Customer, error)
+
+	// GetByID retrieves a customer with their addresses
+	GetByID(ctx context.Context, id int64) (*Customer, error)
+
+	// GetByEmail retrieves a customer by email, ignoring case
+	GetByEmail(ctx context.Context, email string) (*Customer, error)
+
+	// Update updates the profile of a customer
+	Update(ctx context.Context, id int64, input UpdateProfileInput) (*Customer, error)
+
+	// SetVerificationToken stores the hash of a customer's email verification token
+	SetVerificationToken(ctx context.Context, id int64, tokenHash string, expiresAt time.Time) error
+
+	// Verify marks the email of the customer holding an unexpired verification token as
+	// verified. It fails with ErrInvalidVerification for unknown or expired tokens.
+	Verify(ctx context.Context, tokenHash string, at time.Time) (*Customer, error)
+
+	// CreateAddress saves an address for a customer, making it the default when it is the
+	// first one or asked to
+	CreateAddress(ctx context.Context, customerID int64, input AddressInput) (*Address, error)
+
+	// UpdateAddress replaces a saved address of a customer
+	UpdateAddress(ctx context.Context, customerID, id int64, input AddressInput) (*Address, error)
+
+	// DeleteAddress removes a saved address of a customer, making the oldest remaining one the
+	// default when it was
+	DeleteAddress(ctx context.Context, customerID, id int64) error
+}
@@ -0,0 +1,213 @@
+package customer_test
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	password  = "una-clave-segura"
+	jwtSecret = "secreto-de-prueba"
+	verifyURL = "https://tienda.example/verificar"
+	holdFor   = 15 * time.Minute
+)
+
+type customerSuite struct {
+	suite.Suite
+	ctx    context.Context
+	db     *sql.DB
+	mailer *testdouble.Mailer
+	orders *order.Service
+	svc    *customer.Service
+	p      *product.Product
+}
+
+func TestCustomerSuite(t *testing.T) {
+	suite.Run(t, new(customerSuite))
+}
+
+func (s *customerSuite) SetupTest() {
+	s.db = testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.mailer = &testdouble.Mailer{}
+	products := product.NewService(product.NewSQLiteRepository(s.db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(s.db), products)
+	s.orders = order.NewService(order.NewSQLiteRepository(s.db), products, promotions, holdFor)
+	s.svc = customer.NewService(customer.NewSQLiteRepository(s.db), customer.NewJWTManager(jwtSecret, 1), s.orders, nil, s.mailer, verifyURL)
+
+	var err error
+	s.p, err = products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera", Price: 550000})
+	s.Require().NoError(err)
+}
+
+// register creates an account without verifying it
+func (s *customerSuite) register(email string) *customer.Customer {
+	c, err := s.svc.Register(s.ctx, customer.RegisterInput{Email: email, Password: password, Name: "Ana"})
+	s.Require().NoError(err)
+	return c
+}
+
+// verificationToken returns the token of the last verification link emailed to an address
+func (s *customerSuite) verificationToken(email string) string {
+	var token string
+	for _, sent := range s.mailer.Sent() {
+		if sent.To != email {
+			continue
+		}
+		for _, field := range strings.Fields(sent.Body) {
+			if !strings.HasPrefix(field, verifyURL) {
+				continue
+			}
+			link, err := url.Parse(field)
+			s.Require().NoError(err)
+			token = link.Query().Get("token")
+		}
+	}
+	s.Require().NotEmpty(token, "no verification email to %s", email)
+	return token
+}
+
+// signUp registers and verifies an account and returns its signed-in customer
+func (s *customerSuite) signUp(email string) *customer.Customer {
+	s.register(email)
+	response, err := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: s.verificationToken(email)})
+	s.Require().NoError(err)
+	return response.Customer
+}
+
+func (s *customerSuite) TestUnverifiedCustomerCannotLogIn() {
+	s.register("ana@example.com")
+
+	_, unverifiedErr := s.svc.Login(s.ctx, customer.LoginInput{Email: "ana@example.com", Password: password})
+	_, wrongPasswordErr := s.svc.Login(s.ctx, customer.LoginInput{Email: "ana@example.com", Password: "otra-clave"})
+	verified, verifyErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: s.verificationToken("ana@example.com")})
+	login, loginErr := s.svc.Login(s.ctx, customer.LoginInput{Email: "ana@example.com", Password: password})
+
+	s.ErrorIs(unverifiedErr, customer.ErrEmailNotVerified)
+	s.ErrorIs(wrongPasswordErr, customer.ErrInvalidCredentials)
+	s.Require().NoError(verifyErr)
+	s.NotEmpty(verified.Token)
+	s.True(verified.Customer.EmailVerified)
+	s.Require().NoError(loginErr)
+	s.NotEmpty(login.Token)
+}
+
+func (s *customerSuite) TestVerificationTokenCannotBeReused() {
+	s.register("ana@example.com")
+	token := s.verificationToken("ana@example.com")
+
+	_, firstErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: token})
+	_, againErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: token})
+
+	s.Require().NoError(firstErr)
+	s.ErrorIs(againErr, customer.ErrInvalidVerification)
+}
+
+func (s *customerSuite) TestExpiredVerificationToken() {
+	s.register("ana@example.com")
+	expired := s.verificationToken("ana@example.com")
+	_, err := s.db.Exec("UPDATE customers SET verification_expires_at = ?", time.Now().Add(-time.Minute))
+	s.Require().NoError(err)
+
+	_, expiredErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: expired})
+	s.Require().NoError(s.svc.ResendVerification(s.ctx, customer.ResendVerificationInput{Email: "ana@example.com"}))
+	fresh := s.verificationToken("ana@example.com")
+	_, replacedErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: expired})
+	_, freshErr := s.svc.VerifyEmail(s.ctx, customer.VerifyInput{Token: fresh})
+
+	s.ErrorIs(expiredErr, customer.ErrInvalidVerification)
+	s.NotEqual(expired, fresh)
+	s.ErrorIs(replacedErr, customer.ErrInvalidVerification)
+	s.NoError(freshErr)
+}
+
+func (s *customerSuite) TestResendVerificationRevealsNoAccount() {
+	s.signUp("ana@example.com")
+	sent := len(s.mailer.Sent())
+
+	unknownErr := s.svc.ResendVerification(s.ctx, customer.ResendVerificationInput{Email: "nadie@example.com"})
+	verifiedErr := s.svc.ResendVerification(s.ctx, customer.ResendVerificationInput{Email: "ana@example.com"})
+	invalidErr := s.svc.ResendVerification(s.ctx, customer.ResendVerificationInput{Email: "ana"})
+
+	s.NoError(unknownErr)
+	s.NoError(verifiedErr)
+	s.ErrorIs(invalidErr, customer.ErrValidation)
+	s.Len(s.mailer.Sent(), sent)
+}
+
+func (s *customerSuite) TestCustomersOnlyReachTheirOwnAddresses() {
+	ana := s.signUp("ana@example.com")
+	luis := s.signUp("luis@example.com")
+	address, err := s.svc.CreateAddress(s.ctx, luis.ID, customer.AddressInput{Address: "Av. Siempre Viva 742", City: "Córdoba"})
+	s.Require().NoError(err)
+
+	_, updateErr := s.svc.UpdateAddress(s.ctx, ana.ID, address.ID, customer.AddressInput{Address: "Otra calle 1"})
+	deleteErr := s.svc.DeleteAddress(s.ctx, ana.ID, address.ID)
+	anaProfile, anaErr := s.svc.GetProfile(s.ctx, ana.ID)
+	luisProfile, luisErr := s.svc.GetProfile(s.ctx, luis.ID)
+
+	s.ErrorIs(updateErr, customer.ErrAddressNotFound)
+	s.ErrorIs(deleteErr, customer.ErrAddressNotFound)
+	s.Require().NoError(anaErr)
+	s.Empty(anaProfile.Addresses)
+	s.Require().NoError(luisErr)
+	s.Require().Len(luisProfile.Addresses, 1)
+	s.Equal("Av. Siempre Viva 742", luisProfile.Addresses[0].Address)
+}
+
+func (s *customerSuite) TestCustomersOnlyReachTheirOwnOrders() {
+	ana := s.signUp("ana@example.com")
+	luis := s.signUp("luis@example.com")
+	var placed []*order.Order
+	for _, c := range []*customer.Customer{ana, luis} {
+		o, err := s.orders.CreateOrder(s.ctx, order.CreateOrderInput{
+			Customer:   order.Customer{Name: c.Name, Email: c.Email},
+			Items:      []order.CreateItemInput{{ProductID: s.p.ID, Quantity: 1}},
+			CustomerID: c.ID,
+		})
+		s.Require().NoError(err)
+		placed = append(placed, o)
+	}
+
+	orders, total, listErr := s.svc.GetOrders(s.ctx, ana.ID, 1, 10)
+	own, ownErr := s.svc.GetOrder(s.ctx, ana.ID, placed[0].Reference)
+	_, otherErr := s.svc.GetOrder(s.ctx, ana.ID, placed[1].Reference)
+
+	s.Require().NoError(listErr)
+	s.Equal(1, total)
+	s.Equal(placed[0].ID, orders[0].ID)
+	s.Require().NoError(ownErr)
+	s.Equal(placed[0].ID, own.ID)
+	s.ErrorIs(otherErr, order.ErrNotFound)
+}
+
+func (s *customerSuite) TestVerifyingClaimsGuestOrders() {
+	guest, err := s.orders.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer: order.Customer{Name: "Ana", Email: "Ana@Example.com"},
+		Items:    []order.CreateItemInput{{ProductID: s.p.ID, Quantity: 1}},
+	})
+	s.Require().NoError(err)
+	luis := s.signUp("luis@example.com")
+
+	ana := s.signUp("ana@example.com")
+	orders, _, anaErr := s.svc.GetOrders(s.ctx, ana.ID, 1, 10)
+	others, _, luisErr := s.svc.GetOrders(s.ctx, luis.ID, 1, 10)
+
+	s.Require().NoError(anaErr)
+	s.Require().Len(orders, 1)
+	s.Equal(guest.ID, orders[0].ID)
+	s.Require().NoError(luisErr)
+	s.Empty(others)
+}
@@ -0,0 +1,310 @@
+package customer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const customerColumns = `id, email, password_hash, name, phone, email_verified_at, created_at, updated_at`
+
+const addressColumns = `id, label, recipient, phone, address, city, province, postal_code, is_default, created_at, updated_at`
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite customer repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// Create stores a customer with the password already hashed
+func (r *SQLiteRepository) Create(ctx context.Context, c *Customer) (*Customer, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO customers (email, password_hash, name, phone, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, c.Email, c.PasswordHash, c.Name, c.Phone, now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID retrieves a customer with their addresses
+func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Customer, error) {
+	c, err := r.get(ctx, "id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+addressColumns+" FROM customer_addresses WHERE customer_id = ? ORDER BY is_default DESC, id ASC",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query addresses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan address: %w", err)
+		}
+		c.Addresses = append(c.Addresses, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return c, nil
+}
+
+// GetByEmail retrieves a customer by email, ignoring case
+func (r *SQLiteRepository) GetByEmail(ctx context.Context, email string) (*Customer, error) {
+	return r.get(ctx, "email = ?", email)
+}
+
+// get retrieves the customer matching a condition, without addresses
+func (r *SQLiteRepository) get(ctx context.Context, condition string, arg interface{}) (*Customer, error) {
+	row := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM customers WHERE %s", customerColumns, condition), arg)
+	c, err := scanCustomer(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	return c, nil
+}
+
+// Update updates the profile of a customer
+func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdateProfileInput) (*Customer, error) {
+	var setClauses []string
+	var args []interface{}
+
+	if input.Name != nil {
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, *input.Name)
+	}
+	if input.Phone != nil {
+		setClauses = append(setClauses, "phone = ?")
+		args = append(args, *input.Phone)
+	}
+
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, time.Now(), id)
+
+	query := fmt.Sprintf("UPDATE customers SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// SetVerificationToken stores the hash of a customer's email verification token
+func (r *SQLiteRepository) SetVerificationToken(ctx context.Context, id int64, tokenHash string, expiresAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE customers SET verification_token_hash = ?, verification_expires_at = ? WHERE id = ?",
+		tokenHash, expiresAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set verification token: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Verify marks the email of the customer holding an unexpired verification
+// token as verified, using the token up
+func (r *SQLiteRepository) Verify(ctx context.Context, tokenHash string, at time.Time) (*Customer, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE customers
+		SET email_verified_at = ?, verification_token_hash = '', verification_expires_at = NULL, updated_at = ?
+		WHERE verification_token_hash = ? AND verification_token_hash != '' AND verification_expires_at > ?
+		RETURNING id
+	`, at, at, tokenHash, at).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidVerification
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// CreateAddress saves an address for a customer, making it the default when
+// it is the first one or asked to
+func (r *SQLiteRepository) CreateAddress(ctx context.Context, customerID int64, input AddressInput) (*Address, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM customer_addresses WHERE customer_id = ?", customerID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count addresses: %w", err)
+	}
+	if count >= MaxAddresses {
+		return nil, ErrInvalidInput(fmt.Sprintf("up to %d addresses can be saved", MaxAddresses))
+	}
+
+	isDefault := input.IsDefault || count == 0
+	if isDefault {
+		if err := clearDefault(ctx, tx, customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO customer_addresses (customer_id, label, recipient, phone, address, city, province, postal_code,
+			is_default, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, customerID, input.Label, input.Recipient, input.Phone, input.Address, input.City, input.Province,
+		input.PostalCode, isDefault, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create address: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit address: %w", err)
+	}
+
+	return r.getAddress(ctx, customerID, id)
+}
+
+// UpdateAddress replaces a saved address of a customer. The default address
+// stays the default until another one takes its place.
+func (r *SQLiteRepository) UpdateAddress(ctx context.Context, customerID, id int64, input AddressInput) (*Address, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if input.IsDefault {
+		if err := clearDefault(ctx, tx, customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE customer_addresses
+		SET label = ?, recipient = ?, phone = ?, address = ?, city = ?, province = ?, postal_code = ?,
+			is_default = is_default OR ?, updated_at = ?
+		WHERE id = ? AND customer_id = ?
+	`, input.Label, input.Recipient, input.Phone, input.Address, input.City, input.Province, input.PostalCode,
+		input.IsDefault, time.Now(), id, customerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrAddressNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit address: %w", err)
+	}
+
+	return r.getAddress(ctx, customerID, id)
+}
+
+// DeleteAddress removes a saved address of a customer, making the oldest
+// remaining one the default when it was
+func (r *SQLiteRepository) DeleteAddress(ctx context.Context, customerID, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isDefault bool
+	err = tx.QueryRowContext(ctx,
+		"DELETE FROM customer_addresses WHERE id = ? AND customer_id = ? RETURNING is_default",
+		id, customerID,
+	).Scan(&isDefault)
+	if err == sql.ErrNoRows {
+		return ErrAddressNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+
+	if isDefault {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE customer_addresses SET is_default = 1
+			WHERE id = (SELECT MIN(id) FROM customer_addresses WHERE customer_id = ?)
+		`, customerID)
+		if err != nil {
+			return fmt.Errorf("failed to set default address: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit address deletion: %w", err)
+	}
+
+	return nil
+}
+
+// getAddress retrieves a saved address of a customer
+func (r *SQLiteRepository) getAddress(ctx context.Context, customerID, id int64) (*Address, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+addressColumns+" FROM customer_addresses WHERE id = ? AND customer_id = ?",
+		id, customerID,
+	)
+	a, err := scanAddress(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrAddressNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+
+	return a, nil
+}
+
+// clearDefault unsets the default address of a customer
+func clearDefault(ctx context.Context, tx *sql.Tx, customerID int64) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE customer_addresses SET is_default = 0 WHERE customer_id = ?", customerID); err != nil {
+		return fmt.Errorf("failed to clear default address: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package design
+
+import (
+	"strings"
+	"time"
+)
+
+// Color is a shirt base color offered by the designer
+type Color struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"` // Also a color of the product designs are printed on
+	Hex       string    `json:"hex"`
+	MockupURL string    `json:"mockup_url"` // Photo of a plain shirt in this color
+	Active    bool      `json:"active"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	mockupFilename string
+}
+
+// CreateColorInput represents input for creating a shirt color. The mockup
+// photo is uploaded once the color exists.
+type CreateColorInput struct {
+	Name      string `json:"name"`
+	Hex       string `json:"hex"`
+	Active    *bool  `json:"active,omitempty"` // Defaults to true
+	SortOrder int    `json:"sort_order"`
+}
+
+// UpdateColorInput represents input for updating a shirt color
+type UpdateColorInput struct {
+	Name      *string `json:"name,omitempty"`
+	Hex       *string `json:"hex,omitempty"`
+	Active    *bool   `json:"active,omitempty"`
+	SortOrder *int    `json:"sort_order,omitempty"`
+}
+
+// Validate validates shirt color creation input
+func (input *CreateColorInput) Validate() error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return ErrInvalidInput("name is required")
+	}
+	hex, ok := normalizeHex(input.Hex)
+	if !ok {
+		return ErrInvalidInput("hex must be a color like #1A2B3C")
+	}
+	input.Hex = hex
+	if input.Active == nil {
+		active := true
+		input.Active = &active
+	}
+	return nil
+}
+
+// Validate validates shirt color update input
+func (input *UpdateColorInput) Validate() error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return ErrInvalidInput("name cannot be empty")
+		}
+		input.Name = &name
+	}
+	if input.Hex != nil {
+		hex, ok := normalizeHex(*input.Hex)
+		if !ok {
+			return ErrInvalidInput("hex must be a color like #1A2B3C")
+		}
+		input.Hex = &hex
+	}
+	return nil
+}
+
+// scanColor scans a database row into a Color
+func scanColor(row interface{ Scan(...interface{}) error }) (*Color, error) {
+	var c Color
+
+	err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.Hex,
+		&c.MockupURL,
+		&c.mockupFilename,
+		&c.Active,
+		&c.SortOrder,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,241 @@
+package design
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+)
+
+// Limits of a design, in line with the storefront designer
+const (
+	MaxTextLayers  = 5
+	MaxTextLength  = 200
+	MaxFontSize    = 500
+	MaxCanvasBytes = 512 << 10 // Size of the canvas JSON
+	MinImageSide   = 100       // Smaller canvases would scale text far past the print area
+	MaxImageSide   = 4000      // Width and height of the rendered image, in pixels
+)
+
+// Design is a custom t-shirt design: a template with text layers on a shirt
+// color. Designs never change once saved, so a shared link or an order
+// always shows what the customer made; editing a design saves a new one.
+type Design struct {
+	ID           int64           `json:"-"`
+	CustomerID   int64           `json:"-"`                   // Customer account that saved the design, if any
+	Token        string          `json:"token"`               // Unguessable key of the share link
+	ShareURL     string          `json:"share_url,omitempty"` // Storefront page showing the design
+	TemplateID   string          `json:"template_id,omitempty"`
+	TemplateName string          `json:"template_name,omitempty"`
+	ShirtColor   ShirtColor      `json:"shirt_color"`
+	TextLayers   []*TextLayer    `json:"text_layers"`
+	Fonts        []*Font         `json:"fonts"`
+	Canvas       json.RawMessage `json:"canvas,omitempty"` // Editor state, stored as sent
+	Width        int             `json:"width"`            // Size of the canvas the layers are placed on, in pixels
+	Height       int             `json:"height"`
+	ImageURL     string          `json:"image_url"`            // Image rendered by the designer
+	ProductID    int64           `json:"product_id,omitempty"` // Product the design is printed on when ordered
+	Surcharge    int             `json:"surcharge,omitempty"`  // Charged per unit on top of the product price
+	Render       *Render         `json:"render,omitempty"`     // Print-ready image, once rendered
+	CreatedAt    time.Time       `json:"created_at"`
+
+	imageFilename string
+}
+
+// Render is the print-ready image of a design and its mockup on the shirt
+type Render struct {
+	PrintURL   string    `json:"print_url"`  // PNG of the print area at PrintDPI, transparent around the design
+	MockupURL  string    `json:"mockup_url"` // JPEG of the design on a photo of the shirt color
+	WidthCM    float64   `json:"width_cm"`
+	HeightCM   float64   `json:"height_cm"`
+	Width      int       `json:"width"` // Size of the print image, in pixels
+	Height     int       `json:"height"`
+	DPI        int       `json:"dpi"`
+	RenderedAt time.Time `json:"rendered_at"`
+
+	printFilename  string
+	mockupFilename string
+}
+
+// ShirtColor is the base color of the shirt, copied from the designer's
+// shirt colors when the design is saved
+type ShirtColor struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"` // Matched to the colors of the product when ordering, e.g. Negro
+	Hex  string `json:"hex,omitempty"`
+}
+
+// TextLayer is a block of text placed on the design
+type TextLayer struct {
+	ID         string  `json:"id,omitempty"`
+	Content    string  `json:"content"`
+	X          float64 `json:"x"`
+	Y          float64 `json:"y"`
+	FontSize   float64 `json:"font_size"`
+	FontFamily string  `json:"font_family"`
+	Color      string  `json:"color"`
+	Rotation   float64 `json:"rotation,omitempty"` // Degrees clockwise
+}
+
+// Font is a font family used by the text layers
+type Font struct {
+	Family string `json:"family"`
+	URL    string `json:"url,omitempty"` // Web font stylesheet, e.g. from Google Fonts
+}
+
+// CreateDesignInput represents input for saving a design
+type CreateDesignInput struct {
+	TemplateID   string          `json:"template_id,omitempty"`
+	TemplateName string          `json:"template_name,omitempty"`
+	ShirtColor   ShirtColor      `json:"shirt_color"`
+	TextLayers   []*TextLayer    `json:"text_layers"`
+	Fonts        []*Font         `json:"fonts"`
+	Canvas       json.RawMessage `json:"canvas,omitempty"`
+	Width        int             `json:"width,omitempty"` // Defaults to the size of the image
+	Height       int             `json:"height,omitempty"`
+	Image        string          `json:"image"` // PNG as base64 or a data URL, as exported by the canvas
+
+	CustomerID int64 `json:"-"` // Taken from the signed-in customer saving the design
+}
+
+// Validate validates design input, trimming text and listing every font the
+// text layers use
+func (input *CreateDesignInput) Validate() error {
+	input.TemplateID = strings.TrimSpace(input.TemplateID)
+	input.TemplateName = strings.TrimSpace(input.TemplateName)
+
+	c := &input.ShirtColor
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return ErrInvalidInput("shirt_color name is required")
+	}
+	if c.Hex != "" {
+		hex, ok := normalizeHex(c.Hex)
+		if !ok {
+			return ErrInvalidInput("shirt_color hex must be a color like #1A2B3C")
+		}
+		c.Hex = hex
+	}
+
+	if input.TemplateID == "" && len(input.TextLayers) == 0 {
+		return ErrInvalidInput("a design needs a template or text")
+	}
+	if len(input.TextLayers) > MaxTextLayers {
+		return ErrInvalidInput(fmt.Sprintf("a design can have up to %d text layers", MaxTextLayers))
+	}
+
+	families := make(map[string]bool, len(input.Fonts))
+	fonts := make([]*Font, 0, len(input.Fonts))
+	for _, f := range input.Fonts {
+		if f == nil {
+			continue
+		}
+		f.Family, f.URL = strings.TrimSpace(f.Family), strings.TrimSpace(f.URL)
+		if f.Family == "" || families[f.Family] {
+			continue
+		}
+		families[f.Family] = true
+		fonts = append(fonts, f)
+	}
+
+	for i, layer := range input.TextLayers {
+		if layer == nil {
+			return ErrInvalidInput(fmt.Sprintf("text layer %d is empty", i+1))
+		}
+		layer.Content = strings.TrimSpace(layer.Content)
+		layer.FontFamily = strings.TrimSpace(layer.FontFamily)
+		if layer.Content == "" {
+			return ErrInvalidInput(fmt.Sprintf("text layer %d has no text", i+1))
+		}
+		if len([]rune(layer.Content)) > MaxTextLength {
+			return ErrInvalidInput(fmt.Sprintf("text layers can have up to %d characters", MaxTextLength))
+		}
+		if layer.FontSize <= 0 || layer.FontSize > MaxFontSize {
+			return ErrInvalidInput(fmt.Sprintf("font_size must be between 1 and %d", MaxFontSize))
+		}
+		if layer.FontFamily == "" {
+			return ErrInvalidInput(fmt.Sprintf("text layer %d needs a font_family", i+1))
+		}
+		hex, ok := normalizeHex(layer.Color)
+		if !ok {
+			return ErrInvalidInput(fmt.Sprintf("text layer %d color must be a color like #1A2B3C", i+1))
+		}
+		layer.Color = hex
+
+		if !families[layer.FontFamily] {
+			families[layer.FontFamily] = true
+			fonts = append(fonts, &Font{Family: layer.FontFamily})
+		}
+	}
+	input.Fonts = fonts
+
+	if len(input.Canvas) > 0 {
+		if len(input.Canvas) > MaxCanvasBytes {
+			return ErrInvalidInput(fmt.Sprintf("canvas must be under %dKB", MaxCanvasBytes>>10))
+		}
+		if !json.Valid(input.Canvas) {
+			return ErrInvalidInput("canvas must be valid JSON")
+		}
+	}
+
+	for _, side := range []int{input.Width, input.Height} {
+		if side != 0 && (side < MinImageSide || side > MaxImageSide) {
+			return ErrInvalidInput(fmt.Sprintf("width and height must be between %d and %d", MinImageSide, MaxImageSide))
+		}
+	}
+	if strings.TrimSpace(input.Image) == "" {
+		return ErrInvalidInput("image is required")
+	}
+
+	return nil
+}
+
+// decodeImage decodes the PNG of a design sent as base64 or a data URL and
+// returns it with its width and height
+func decodeImage(image string) ([]byte, int, int, error) {
+	image = strings.TrimSpace(image)
+	if strings.HasPrefix(image, "data:") {
+		header, data, ok := strings.Cut(image, ",")
+		if !ok || header != "data:image/png;base64" {
+			return nil, 0, 0, ErrInvalidInput("image must be a PNG data URL")
+		}
+		image = data
+	}
+
+	data, err := base64.StdEncoding.DecodeString(image)
+	if err != nil {
+		return nil, 0, 0, ErrInvalidInput("image must be base64 encoded")
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, ErrInvalidInput("image must be a PNG")
+	}
+	if cfg.Width > MaxImageSide || cfg.Height > MaxImageSide {
+		return nil, 0, 0, ErrInvalidInput(fmt.Sprintf("image must be at most %dx%d pixels", MaxImageSide, MaxImageSide))
+	}
+	if cfg.Width < MinImageSide || cfg.Height < MinImageSide {
+		return nil, 0, 0, ErrInvalidInput(fmt.Sprintf("image must be at least %dx%d pixels", MinImageSide, MinImageSide))
+	}
+
+	return data, cfg.Width, cfg.Height, nil
+}
+
+// normalizeHex validates a color written as #RGB or #RRGGBB and returns it
+// as #RRGGBB in upper case
+func normalizeHex(color string) (string, bool) {
+	color = strings.ToUpper(strings.TrimSpace(color))
+	if len(color) != 4 && len(color) != 7 || color[0] != '#' {
+		return "", false
+	}
+	if strings.Trim(color[1:], "0123456789ABCDEF") != "" {
+		return "", false
+	}
+	if len(color) == 4 {
+		color = string([]byte{'#', color[1], color[1], color[2], color[2], color[3], color[3]})
+	}
+	return color, true
+}
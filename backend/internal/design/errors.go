@@ -0,0 +1,37 @@
+package design
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates a design was not found
+	ErrNotFound = errors.New("design not found")
+
+	// ErrTemplateNotFound indicates a design template was not found
+	ErrTemplateNotFound = errors.New("design template not found")
+
+	// ErrColorNotFound indicates a shirt color was not found
+	ErrColorNotFound = errors.New("shirt color not found")
+
+	// ErrDuplicateSlug indicates another template already uses the slug
+	ErrDuplicateSlug = errors.New("design template slug already exists")
+
+	// ErrDuplicateColor indicates another shirt color already has the name
+	ErrDuplicateColor = errors.New("shirt color already exists")
+
+	// ErrInUse indicates saved designs use the template or color, so it can only be deactivated
+	ErrInUse = errors.New("used by saved designs; deactivate it instead")
+
+	// ErrNotConfigured indicates there is no product to print designs on, so they cannot be ordered
+	ErrNotConfigured = errors.New("design orders are not configured")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
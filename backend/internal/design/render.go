@@ -0,0 +1,341 @@
+package design
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	_ "image/gif" // Template images and shirt photos may be GIF
+	"image/jpeg"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp" // Template images and shirt photos may be WebP
+)
+
+// Print settings
+const (
+	PrintDPI       = 300
+	MaxPrintSideCM = 50 // Larger print areas would take gigabytes to render
+
+	lineHeight   = 1.16 // Line spacing of the designer's text, relative to the font size
+	mockupWidth  = 1200 // Size of mockups of shirt colors without a photo
+	mockupHeight = 1400
+)
+
+// mockupArea is where the print goes on a shirt photo, as fractions of the
+// photo size: the chest of a shirt photographed from the front
+var mockupArea = struct{ X, Y, Width, Height float64 }{0.30, 0.22, 0.40, 0.50}
+
+// RenderInput represents input for rendering a design for print
+type RenderInput struct {
+	WidthCM  float64 `json:"width_cm,omitempty"` // Print area; defaults to DESIGN_PRINT_WIDTH_CM and DESIGN_PRINT_HEIGHT_CM
+	HeightCM float64 `json:"height_cm,omitempty"`
+}
+
+// Validate validates render input
+func (input *RenderInput) Validate() error {
+	for _, side := range []float64{input.WidthCM, input.HeightCM} {
+		if side != 0 && (side < 1 || side > MaxPrintSideCM) {
+			return ErrInvalidInput(fmt.Sprintf("width_cm and height_cm must be between 1 and %d", MaxPrintSideCM))
+		}
+	}
+	return nil
+}
+
+// Renderer draws designs at print resolution with the fonts found in a
+// directory of TTF and OTF files. Fonts are matched to the families of the
+// text layers ignoring case, spaces and a "Regular" suffix, so
+// Oswald-Regular.ttf serves "Oswald"; missing fonts fall back to Go Regular.
+type Renderer struct {
+	fontDir  string
+	widthCM  float64 // Default print area
+	heightCM float64
+
+	mu        sync.Mutex // Renders one design at a time, since print images take hundreds of megabytes
+	fontFiles map[string]string
+	fonts     map[string]*opentype.Font
+	fallback  *opentype.Font
+}
+
+// NewRenderer creates a renderer with the fonts in fontDir and a default
+// print area of widthCM x heightCM
+func NewRenderer(fontDir string, widthCM, heightCM float64) (*Renderer, error) {
+	input := RenderInput{WidthCM: widthCM, HeightCM: heightCM}
+	if widthCM == 0 || heightCM == 0 || input.Validate() != nil {
+		return nil, fmt.Errorf("print area must be between 1 and %d cm per side", MaxPrintSideCM)
+	}
+
+	fallback, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default font: %w", err)
+	}
+
+	r := &Renderer{
+		fontDir:   fontDir,
+		widthCM:   widthCM,
+		heightCM:  heightCM,
+		fontFiles: make(map[string]string),
+		fonts:     make(map[string]*opentype.Font),
+		fallback:  fallback,
+	}
+
+	entries, err := os.ReadDir(fontDir)
+	if err != nil {
+		log.Printf("Design fonts not loaded from %s, text will use the default font: %v", fontDir, err)
+		return r, nil
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || ext != ".ttf" && ext != ".otf" {
+			continue
+		}
+		r.fontFiles[fontKey(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())))] = filepath.Join(fontDir, entry.Name())
+	}
+
+	return r, nil
+}
+
+// Render draws a design for print and on the shirt. The print is a PNG of
+// the print area at PrintDPI, transparent around the design: the template
+// image fills the design canvas, which is scaled to fit the area and
+// centered, and the text layers go on top. The mockup is a JPEG of the print
+// on the shirt photo, or on a plain shirt-colored background when there is
+// no photo. template and photo may be nil.
+func (r *Renderer) Render(d *Design, template, photo image.Image, widthCM, heightCM float64) (printPNG, mockupJPEG []byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sheet := image.NewRGBA(image.Rect(0, 0, printPixels(widthCM), printPixels(heightCM)))
+	if err := r.drawDesign(sheet, d, template); err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode print image: %w", err)
+	}
+	printPNG = withDPI(buf.Bytes(), PrintDPI)
+
+	buf = bytes.Buffer{}
+	if err := jpeg.Encode(&buf, mockup(sheet, photo, d.ShirtColor.Hex), &jpeg.Options{Quality: 90}); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode mockup image: %w", err)
+	}
+
+	return printPNG, buf.Bytes(), nil
+}
+
+// drawDesign draws the template and text layers of a design scaled to fit
+// dst. Canvases under MinImageSide, saved before it was enforced, are
+// replaced by the template size so their text is not scaled past the sheet.
+func (r *Renderer) drawDesign(dst *image.RGBA, d *Design, template image.Image) error {
+	width, height := float64(d.Width), float64(d.Height)
+	if (width < MinImageSide || height < MinImageSide) && template != nil {
+		width, height = float64(template.Bounds().Dx()), float64(template.Bounds().Dy())
+	}
+	if width < MinImageSide || height < MinImageSide {
+		width, height = float64(dst.Bounds().Dx()), float64(dst.Bounds().Dy())
+	}
+
+	canvas := fitRect(image.Pt(int(width), int(height)), dst.Bounds())
+	scale := float64(canvas.Dx()) / width
+
+	if template != nil {
+		draw.CatmullRom.Scale(dst, fitRect(template.Bounds().Size(), canvas), template, template.Bounds(), draw.Over, nil)
+	}
+
+	for _, layer := range d.TextLayers {
+		x := float64(canvas.Min.X) + layer.X*scale
+		y := float64(canvas.Min.Y) + layer.Y*scale
+		if err := r.drawText(dst, layer, x, y, scale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drawText draws a text layer with its top left corner at x, y, rotated
+// around that corner as the designer does. The font size and the text
+// image are capped at the size of dst, since nothing larger fits the print.
+func (r *Renderer) drawText(dst *image.RGBA, layer *TextLayer, x, y, scale float64) error {
+	bounds := dst.Bounds()
+	size := math.Min(layer.FontSize*scale, float64(bounds.Dy()))
+	face, err := opentype.NewFace(r.font(layer.FontFamily), &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return fmt.Errorf("failed to load font %q: %w", layer.FontFamily, err)
+	}
+	defer face.Close()
+
+	lines := strings.Split(layer.Content, "\n")
+	width := 0
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > width {
+			width = w
+		}
+	}
+	if width == 0 {
+		return nil
+	}
+
+	// Leave room for glyphs reaching past their advance, like italics
+	pad := int(math.Ceil(size / 4))
+	step := size * lineHeight
+	textWidth := min(width+2*pad, bounds.Dx())
+	textHeight := min(int(math.Ceil(step*float64(len(lines))))+2*pad, bounds.Dy())
+	text := image.NewRGBA(image.Rect(0, 0, textWidth, textHeight))
+
+	// Each line is centered vertically within its line height
+	metrics := face.Metrics()
+	ascent, descent := float64(metrics.Ascent.Ceil()), float64(metrics.Descent.Ceil())
+	drawer := font.Drawer{Dst: text, Src: image.NewUniform(parseHex(layer.Color)), Face: face}
+	for i, line := range lines {
+		baseline := float64(pad) + step*float64(i) + (step-ascent-descent)/2 + ascent
+		drawer.Dot = fixed.P(pad, int(math.Round(baseline)))
+		drawer.DrawString(line)
+	}
+
+	if layer.Rotation == 0 {
+		origin := image.Pt(int(math.Round(x))-pad, int(math.Round(y))-pad)
+		draw.Draw(dst, text.Bounds().Add(origin), text, image.Point{}, draw.Over)
+		return nil
+	}
+
+	// Map the text so its point (pad, pad) lands on x, y rotated clockwise
+	sin, cos := math.Sincos(layer.Rotation * math.Pi / 180)
+	p := float64(pad)
+	transform := f64.Aff3{
+		cos, -sin, x - (cos*p - sin*p),
+		sin, cos, y - (sin*p + cos*p),
+	}
+	draw.BiLinear.Transform(dst, transform, text, text.Bounds(), draw.Over, nil)
+
+	return nil
+}
+
+// font returns the font of a family, loading it on first use
+func (r *Renderer) font(family string) *opentype.Font {
+	key := fontKey(family)
+	if f, ok := r.fonts[key]; ok {
+		return f
+	}
+
+	f := r.fallback
+	if path, ok := r.fontFiles[key]; ok {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var parsed *opentype.Font
+			if parsed, err = opentype.Parse(data); err == nil {
+				f = parsed
+			}
+		}
+		if err != nil {
+			log.Printf("Failed to load font %s, using the default font: %v", path, err)
+		}
+	} else {
+		log.Printf("Font %q not found in %s, using the default font", family, r.fontDir)
+	}
+
+	r.fonts[key] = f
+	return f
+}
+
+// fontKey normalizes a font family or file name for matching
+func fontKey(name string) string {
+	key := strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return unicode.ToLower(c)
+		}
+		return -1
+	}, name)
+	return strings.TrimSuffix(key, "regular")
+}
+
+// mockup draws the print on the chest of a shirt photo, or of a plain
+// background of the shirt color when there is no photo
+func mockup(sheet, photo image.Image, hex string) image.Image {
+	var shirt *image.RGBA
+	if photo != nil {
+		b := photo.Bounds()
+		shirt = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(shirt, shirt.Bounds(), photo, b.Min, draw.Src)
+	} else {
+		shirt = image.NewRGBA(image.Rect(0, 0, mockupWidth, mockupHeight))
+		draw.Draw(shirt, shirt.Bounds(), image.NewUniform(parseHex(hex)), image.Point{}, draw.Src)
+	}
+
+	w, h := float64(shirt.Bounds().Dx()), float64(shirt.Bounds().Dy())
+	area := image.Rect(
+		int(mockupArea.X*w), int(mockupArea.Y*h),
+		int((mockupArea.X+mockupArea.Width)*w), int((mockupArea.Y+mockupArea.Height)*h),
+	)
+	draw.BiLinear.Scale(shirt, fitRect(sheet.Bounds().Size(), area), sheet, sheet.Bounds(), draw.Over, nil)
+
+	return shirt
+}
+
+// fitRect returns the largest rectangle with the proportions of size that
+// fits in box, centered in it
+func fitRect(size image.Point, box image.Rectangle) image.Rectangle {
+	if size.X <= 0 || size.Y <= 0 {
+		return box
+	}
+
+	scale := math.Min(float64(box.Dx())/float64(size.X), float64(box.Dy())/float64(size.Y))
+	w, h := int(math.Round(float64(size.X)*scale)), int(math.Round(float64(size.Y)*scale))
+	origin := box.Min.Add(image.Pt((box.Dx()-w)/2, (box.Dy()-h)/2))
+
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(w, h))}
+}
+
+// printPixels converts a print length in centimeters to pixels at PrintDPI
+func printPixels(cm float64) int {
+	return int(math.Round(cm / 2.54 * PrintDPI))
+}
+
+// parseHex parses a color normalized by normalizeHex, defaulting to white
+func parseHex(hex string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
+
+// withDPI adds a pHYs chunk to a PNG so printers read its resolution,
+// which the standard encoder leaves out
+func withDPI(data []byte, dpi int) []byte {
+	const ihdrEnd = 8 + 25 // Signature and IHDR chunk, always first
+	if len(data) < ihdrEnd {
+		return data
+	}
+
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	ppm := uint32(math.Round(float64(dpi) / 0.0254)) // Pixels per meter
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1 // Unit is the meter
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...)
+}
@@ -0,0 +1,150 @@
+package design_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/design"
+)
+
+const (
+	sheetCM       = 10
+	sheetPixels   = 1181      // sheetCM at PrintDPI
+	maxRenderHeap = 100 << 20 // A few sheets; unbounded text takes gigabytes
+)
+
+type renderSuite struct {
+	suite.Suite
+	renderer *design.Renderer
+}
+
+func TestRenderSuite(t *testing.T) {
+	suite.Run(t, new(renderSuite))
+}
+
+func (s *renderSuite) SetupTest() {
+	var err error
+	s.renderer, err = design.NewRenderer(s.T().TempDir(), sheetCM, sheetCM)
+	s.Require().NoError(err)
+}
+
+// templateImage returns an opaque square template image
+func templateImage(side int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, side, side))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	return img
+}
+
+// textLayer returns a black text layer in the default font
+func textLayer(content string, x, y, fontSize, rotation float64) *design.TextLayer {
+	return &design.TextLayer{Content: content, X: x, Y: y, FontSize: fontSize, FontFamily: "Go", Color: "#000000", Rotation: rotation}
+}
+
+var renderBoundsCases = []struct {
+	name     string
+	width    int
+	height   int
+	template image.Image
+	layer    *design.TextLayer
+}{
+	{name: "One pixel canvas", width: 1, height: 1, layer: textLayer("Hola", 0, 0, design.MaxFontSize, 0)},
+	{name: "One pixel canvas with a template", width: 1, height: 1, template: templateImage(200), layer: textLayer("Hola", 10, 10, design.MaxFontSize, 0)},
+	{name: "Canvas without size", layer: textLayer("Hola", 0, 0, design.MaxFontSize, 0)},
+	{name: "Largest font on a small canvas", width: design.MinImageSide, height: design.MinImageSide, layer: textLayer(strings.Repeat("W", design.MaxTextLength), 0, 0, design.MaxFontSize, 0)},
+	{name: "Many lines", width: design.MinImageSide, height: design.MinImageSide, layer: textLayer(strings.Repeat("A\n", design.MaxTextLength/2-1)+"A", 0, 0, design.MaxFontSize, 0)},
+	{name: "Rotated", width: design.MinImageSide, height: design.MinImageSide, layer: textLayer(strings.Repeat("W", 20), 50, 50, design.MaxFontSize, 45)},
+	{name: "Outside the canvas", width: 400, height: 400, layer: textLayer("Hola", -1000, 5000, 40, 0)},
+}
+
+func (s *renderSuite) TestRenderStaysWithinTheSheet() {
+	for _, tc := range renderBoundsCases {
+		d := &design.Design{
+			Width:      tc.width,
+			Height:     tc.height,
+			ShirtColor: design.ShirtColor{Name: "Blanca", Hex: "#FFFFFF"},
+			TextLayers: []*design.TextLayer{tc.layer},
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		printPNG, mockupJPEG, err := s.renderer.Render(d, tc.template, nil, sheetCM, sheetCM)
+		runtime.ReadMemStats(&after)
+		s.Require().NoError(err, tc.name)
+		cfg, err := png.DecodeConfig(bytes.NewReader(printPNG))
+
+		s.Require().NoError(err, tc.name)
+		s.Equal(sheetPixels, cfg.Width, tc.name)
+		s.Equal(sheetPixels, cfg.Height, tc.name)
+		s.NotEmpty(mockupJPEG, tc.name)
+		s.Less(after.TotalAlloc-before.TotalAlloc, uint64(maxRenderHeap), tc.name)
+	}
+}
+
+func (s *renderSuite) TestTextIsDrawnAtItsPosition() {
+	d := &design.Design{
+		Width:      design.MinImageSide,
+		Height:     design.MinImageSide,
+		ShirtColor: design.ShirtColor{Name: "Blanca"},
+		TextLayers: []*design.TextLayer{textLayer("HOLA", 0, 0, 20, 0)}, // 20 of 100 pixels: the top fifth of the sheet
+	}
+
+	printPNG, _, err := s.renderer.Render(d, nil, nil, sheetCM, sheetCM)
+	s.Require().NoError(err)
+	img, err := png.Decode(bytes.NewReader(printPNG))
+	s.Require().NoError(err)
+
+	inked := map[bool]int{} // Opaque pixels above and below the text line
+	for y := 0; y < sheetPixels; y++ {
+		for x := 0; x < sheetPixels; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a > 0 {
+				inked[y < sheetPixels*3/10]++
+			}
+		}
+	}
+	s.NotZero(inked[true])
+	s.Zero(inked[false])
+}
+
+var canvasSizeCases = []struct {
+	name    string
+	width   int
+	height  int
+	wantErr bool
+}{
+	{name: "Image size", width: 0, height: 0},
+	{name: "Smallest canvas", width: design.MinImageSide, height: design.MinImageSide},
+	{name: "Largest canvas", width: design.MaxImageSide, height: design.MaxImageSide},
+	{name: "One pixel canvas", width: 1, height: 1, wantErr: true},
+	{name: "One pixel wide", width: 1, height: 500, wantErr: true},
+	{name: "Too large", width: design.MaxImageSide + 1, height: 500, wantErr: true},
+	{name: "Negative", width: -1, height: 500, wantErr: true},
+}
+
+func (s *renderSuite) TestCanvasSize() {
+	for _, tc := range canvasSizeCases {
+		input := design.CreateDesignInput{
+			ShirtColor: design.ShirtColor{Name: "Blanca"},
+			TextLayers: []*design.TextLayer{textLayer("Hola", 0, 0, 40, 0)},
+			Width:      tc.width,
+			Height:     tc.height,
+			Image:      "iVBORw0KGgo=",
+		}
+
+		err := input.Validate()
+
+		if tc.wantErr {
+			s.ErrorIs(err, design.ErrValidation, tc.name)
+		} else {
+			s.NoError(err, tc.name)
+		}
+	}
+}
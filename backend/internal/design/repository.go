@@ -0,0 +1,60 @@
+package design
+
+import "context"
+
+// Repository defines the interface for design data access
+type Repository interface {
+	// Create stores a design with its token and image already set
+	Create(ctx context.Context, d *Design) (*Design, error)
+
+	// GetByToken retrieves a design by its share token
+	GetByToken(ctx context.Context, token string) (*Design, error)
+
+	// GetByCustomer retrieves the designs saved by a customer, newest first
+	GetByCustomer(ctx context.Context, customerID int64) ([]*Design, error)
+
+	// SetRender stores the print and mockup images of a design
+	SetRender(ctx context.Context, id int64, render *Render) error
+
+	// GetTemplates retrieves templates ordered by sort order and name, only active ones when activeOnly is set
+	GetTemplates(ctx context.Context, activeOnly bool) ([]*Template, error)
+
+	// GetTemplate retrieves a single template by ID
+	GetTemplate(ctx context.Context, id int64) (*Template, error)
+
+	// GetTemplateBySlug retrieves a single template by slug
+	GetTemplateBySlug(ctx context.Context, slug string) (*Template, error)
+
+	// CreateTemplate creates a new template
+	CreateTemplate(ctx context.Context, input CreateTemplateInput) (*Template, error)
+
+	// UpdateTemplate updates an existing template
+	UpdateTemplate(ctx context.Context, id int64, input UpdateTemplateInput) (*Template, error)
+
+	// SetTemplateImage sets the preview or print image of a template
+	SetTemplateImage(ctx context.Context, id int64, kind, url, filename string) (*Template, error)
+
+	// DeleteTemplate removes a template no design uses
+	DeleteTemplate(ctx context.Context, id int64) error
+
+	// GetColors retrieves shirt colors ordered by sort order and name, only active ones when activeOnly is set
+	GetColors(ctx context.Context, activeOnly bool) ([]*Color, error)
+
+	// GetColor retrieves a single shirt color by ID
+	GetColor(ctx context.Context, id int64) (*Color, error)
+
+	// GetColorByName retrieves a single shirt color by name, ignoring case
+	GetColorByName(ctx context.Context, name string) (*Color, error)
+
+	// CreateColor creates a new shirt color
+	CreateColor(ctx context.Context, input CreateColorInput) (*Color, error)
+
+	// UpdateColor updates an existing shirt color
+	UpdateColor(ctx context.Context, id int64, input UpdateColorInput) (*Color, error)
+
+	// SetColorMockup sets the mockup photo of a shirt color
+	SetColorMockup(ctx context.Context, id int64, url, filename string) (*Color, error)
+
+	// DeleteColor removes a shirt color no design uses
+	DeleteColor(ctx context.Context, id int64) error
+}
@@ -0,0 +1,467 @@
+package design
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/upload"
+)
+
+// maxRenderSizeMB limits the size of print images, which are far larger than uploads
+const maxRenderSizeMB = 200
+
+// Service provides business logic for custom designs
+type Service struct {
+	repo      Repository
+	uploads   *upload.Service
+	assets    *upload.Service // Template images and shirt mockups
+	renders   *upload.Service // Print and mockup images, larger than uploads
+	renderer  *Renderer
+	productID int64
+	surcharge int
+	shareURL  string
+}
+
+// NewService creates a new design service. Designs are ordered as the
+// product productID in the design's shirt color, charging surcharge per unit
+// on top of its price; with no product they cannot be ordered. Share links
+// are shareURL followed by the design token.
+func NewService(repo Repository, uploadService *upload.Service, renderer *Renderer, productID int64, surcharge int, shareURL string) *Service {
+	return &Service{
+		repo:      repo,
+		uploads:   uploadService.Dir("designs"),
+		assets:    uploadService.Dir("design-assets"),
+		renders:   uploadService.Dir("designs").WithMaxSize(maxRenderSizeMB),
+		renderer:  renderer,
+		productID: productID,
+		surcharge: surcharge,
+		shareURL:  strings.TrimRight(shareURL, "/"),
+	}
+}
+
+// CreateDesign saves a design and its rendered image under a new random
+// token. The template and shirt color must be active in the designer.
+func (s *Service) CreateDesign(ctx context.Context, input CreateDesignInput) (*Design, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	if input.TemplateID != "" {
+		t, err := s.repo.GetTemplateBySlug(ctx, input.TemplateID)
+		if errors.Is(err, ErrTemplateNotFound) || err == nil && !t.Active {
+			return nil, ErrInvalidInput(fmt.Sprintf("template %q is not available", input.TemplateID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		input.TemplateName = t.Name
+	}
+
+	color, err := s.repo.GetColorByName(ctx, input.ShirtColor.Name)
+	if errors.Is(err, ErrColorNotFound) || err == nil && !color.Active {
+		return nil, ErrInvalidInput(fmt.Sprintf("shirt color %q is not available", input.ShirtColor.Name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	input.ShirtColor = ShirtColor{ID: color.ID, Name: color.Name, Hex: color.Hex}
+
+	data, width, height, err := decodeImage(input.Image)
+	if err != nil {
+		return nil, err
+	}
+	if input.Width == 0 || input.Height == 0 {
+		input.Width, input.Height = width, height
+	}
+
+	image, err := s.uploads.SaveBytes(data, ".png")
+	if err != nil {
+		return nil, ErrInvalidInput(err.Error())
+	}
+
+	d, err := s.repo.Create(ctx, &Design{
+		CustomerID:    input.CustomerID,
+		Token:         uuid.New().String(),
+		TemplateID:    input.TemplateID,
+		TemplateName:  input.TemplateName,
+		ShirtColor:    input.ShirtColor,
+		TextLayers:    input.TextLayers,
+		Fonts:         input.Fonts,
+		Canvas:        input.Canvas,
+		Width:         input.Width,
+		Height:        input.Height,
+		ImageURL:      image.URL,
+		imageFilename: image.Filename,
+	})
+	if err != nil {
+		if deleteErr := s.uploads.DeleteFile(image.Filename); deleteErr != nil {
+			log.Printf("Failed to delete image of unsaved design: %v", deleteErr)
+		}
+		return nil, err
+	}
+
+	return s.present(d), nil
+}
+
+// GetDesign retrieves a design by its share token
+func (s *Service) GetDesign(ctx context.Context, token string) (*Design, error) {
+	d, err := s.repo.GetByToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.present(d), nil
+}
+
+// GetCustomerDesigns retrieves the designs saved by a customer, newest first
+func (s *Service) GetCustomerDesigns(ctx context.Context, customerID int64) ([]*Design, error) {
+	designs, err := s.repo.GetByCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range designs {
+		s.present(d)
+	}
+	return designs, nil
+}
+
+// OrderItem turns a design into an order line for the product designs are
+// printed on, in the given size and the design's shirt color. The order
+// prices it with the design surcharge.
+func (s *Service) OrderItem(ctx context.Context, token, size string, quantity int) (*order.CreateItemInput, error) {
+	if s.productID == 0 {
+		return nil, ErrNotConfigured
+	}
+
+	d, err := s.repo.GetByToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	return &order.CreateItemInput{
+		ProductID: s.productID,
+		Size:      size,
+		Color:     d.ShirtColor.Name,
+		Quantity:  quantity,
+		Design: &order.ItemDesign{
+			ID:        d.ID,
+			Token:     d.Token,
+			ImageURL:  d.ImageURL,
+			Surcharge: s.surcharge,
+		},
+	}, nil
+}
+
+// RenderDesign draws the print-ready image of a design for the print area
+// and its mockup on the photo of the shirt color, replacing any previous
+// render. The template is drawn from its print image.
+func (s *Service) RenderDesign(ctx context.Context, token string, input RenderInput) (*Design, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if input.WidthCM == 0 {
+		input.WidthCM = s.renderer.widthCM
+	}
+	if input.HeightCM == 0 {
+		input.HeightCM = s.renderer.heightCM
+	}
+
+	d, err := s.repo.GetByToken(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	var template image.Image
+	if d.TemplateID != "" {
+		t, err := s.repo.GetTemplateBySlug(ctx, d.TemplateID)
+		if errors.Is(err, ErrTemplateNotFound) {
+			return nil, ErrInvalidInput(fmt.Sprintf("template %q no longer exists", d.TemplateID))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t.printFilename == "" {
+			return nil, ErrInvalidInput(fmt.Sprintf("template %q has no print image", d.TemplateID))
+		}
+		if template, err = s.loadAsset(t.printFilename); err != nil {
+			return nil, err
+		}
+	}
+
+	var photo image.Image
+	if d.ShirtColor.ID > 0 {
+		c, err := s.repo.GetColor(ctx, d.ShirtColor.ID)
+		if err != nil && !errors.Is(err, ErrColorNotFound) {
+			return nil, err
+		}
+		if err == nil && c.mockupFilename != "" {
+			if photo, err = s.loadAsset(c.mockupFilename); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	printPNG, mockupJPEG, err := s.renderer.Render(d, template, photo, input.WidthCM, input.HeightCM)
+	if err != nil {
+		return nil, err
+	}
+
+	printImage, err := s.renders.SaveBytes(printPNG, ".png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to save print image: %w", err)
+	}
+	mockupImage, err := s.renders.SaveBytes(mockupJPEG, ".jpg")
+	if err != nil {
+		s.deleteRender(printImage.Filename)
+		return nil, fmt.Errorf("failed to save mockup image: %w", err)
+	}
+
+	render := &Render{
+		PrintURL:       printImage.URL,
+		MockupURL:      mockupImage.URL,
+		WidthCM:        input.WidthCM,
+		HeightCM:       input.HeightCM,
+		RenderedAt:     time.Now(),
+		printFilename:  printImage.Filename,
+		mockupFilename: mockupImage.Filename,
+	}
+	if err := s.repo.SetRender(ctx, d.ID, render); err != nil {
+		s.deleteRender(printImage.Filename)
+		s.deleteRender(mockupImage.Filename)
+		return nil, err
+	}
+	if previous := d.Render; previous != nil {
+		s.deleteRender(previous.printFilename)
+		s.deleteRender(previous.mockupFilename)
+	}
+
+	render.Width, render.Height = printPixels(render.WidthCM), printPixels(render.HeightCM)
+	render.DPI = PrintDPI
+	d.Render = render
+
+	return s.present(d), nil
+}
+
+// loadAsset decodes a template image or shirt photo
+func (s *Service) loadAsset(filename string) (image.Image, error) {
+	f, err := s.assets.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open designer asset: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode designer asset %s: %w", filename, err)
+	}
+
+	return img, nil
+}
+
+// deleteRender removes a print or mockup image no longer used, logging
+// failures since the render is already saved or failed
+func (s *Service) deleteRender(filename string) {
+	if filename == "" {
+		return
+	}
+	if err := s.renders.DeleteFile(filename); err != nil {
+		log.Printf("Failed to delete design render %s: %v", filename, err)
+	}
+}
+
+// GetCatalog retrieves the active templates and shirt colors for the
+// designer. Print images are left out.
+func (s *Service) GetCatalog(ctx context.Context) (*Catalog, error) {
+	templates, err := s.repo.GetTemplates(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		t.PrintURL = ""
+	}
+
+	colors, err := s.repo.GetColors(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Catalog{Templates: templates, Colors: colors}, nil
+}
+
+// GetTemplates retrieves every template, including inactive ones
+func (s *Service) GetTemplates(ctx context.Context) ([]*Template, error) {
+	return s.repo.GetTemplates(ctx, false)
+}
+
+// GetTemplate retrieves a single template by ID
+func (s *Service) GetTemplate(ctx context.Context, id int64) (*Template, error) {
+	return s.repo.GetTemplate(ctx, id)
+}
+
+// CreateTemplate creates a new template with validation
+func (s *Service) CreateTemplate(ctx context.Context, input CreateTemplateInput) (*Template, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateTemplate(ctx, input)
+}
+
+// UpdateTemplate updates a template
+func (s *Service) UpdateTemplate(ctx context.Context, id int64, input UpdateTemplateInput) (*Template, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateTemplate(ctx, id, input)
+}
+
+// SetTemplateImage uploads the preview or print image of a template,
+// replacing the previous one. Print images must be PNG to keep their
+// transparency.
+func (s *Service) SetTemplateImage(ctx context.Context, id int64, kind string, file multipart.File, header *multipart.FileHeader) (*Template, error) {
+	if kind != ImagePreview && kind != ImagePrint {
+		return nil, ErrInvalidInput("image must be preview or print")
+	}
+	if kind == ImagePrint && !strings.EqualFold(filepath.Ext(header.Filename), ".png") {
+		return nil, ErrInvalidInput("print image must be a PNG")
+	}
+
+	t, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := t.previewFilename
+	if kind == ImagePrint {
+		previous = t.printFilename
+	}
+
+	image, err := s.assets.SaveFile(file, header)
+	if err != nil {
+		return nil, ErrInvalidInput(err.Error())
+	}
+
+	t, err = s.repo.SetTemplateImage(ctx, id, kind, image.URL, image.Filename)
+	if err != nil {
+		s.deleteAsset(image.Filename)
+		return nil, err
+	}
+	s.deleteAsset(previous)
+
+	return t, nil
+}
+
+// DeleteTemplate removes a template and its images
+func (s *Service) DeleteTemplate(ctx context.Context, id int64) error {
+	t, err := s.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTemplate(ctx, id); err != nil {
+		return err
+	}
+	s.deleteAsset(t.previewFilename)
+	s.deleteAsset(t.printFilename)
+
+	return nil
+}
+
+// GetColors retrieves every shirt color, including inactive ones
+func (s *Service) GetColors(ctx context.Context) ([]*Color, error) {
+	return s.repo.GetColors(ctx, false)
+}
+
+// GetColor retrieves a single shirt color by ID
+func (s *Service) GetColor(ctx context.Context, id int64) (*Color, error) {
+	return s.repo.GetColor(ctx, id)
+}
+
+// CreateColor creates a new shirt color with validation
+func (s *Service) CreateColor(ctx context.Context, input CreateColorInput) (*Color, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateColor(ctx, input)
+}
+
+// UpdateColor updates a shirt color. Saved designs keep the name and hex
+// they were made with.
+func (s *Service) UpdateColor(ctx context.Context, id int64, input UpdateColorInput) (*Color, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateColor(ctx, id, input)
+}
+
+// SetColorMockup uploads the mockup photo of a shirt color, replacing the previous one
+func (s *Service) SetColorMockup(ctx context.Context, id int64, file multipart.File, header *multipart.FileHeader) (*Color, error) {
+	c, err := s.repo.GetColor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := s.assets.SaveFile(file, header)
+	if err != nil {
+		return nil, ErrInvalidInput(err.Error())
+	}
+
+	updated, err := s.repo.SetColorMockup(ctx, id, image.URL, image.Filename)
+	if err != nil {
+		s.deleteAsset(image.Filename)
+		return nil, err
+	}
+	s.deleteAsset(c.mockupFilename)
+
+	return updated, nil
+}
+
+// DeleteColor removes a shirt color and its mockup photo
+func (s *Service) DeleteColor(ctx context.Context, id int64) error {
+	c, err := s.repo.GetColor(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteColor(ctx, id); err != nil {
+		return err
+	}
+	s.deleteAsset(c.mockupFilename)
+
+	return nil
+}
+
+// deleteAsset removes a template image or mockup photo no longer used,
+// logging failures since the change is already saved
+func (s *Service) deleteAsset(filename string) {
+	if filename == "" {
+		return
+	}
+	if err := s.assets.DeleteFile(filename); err != nil {
+		log.Printf("Failed to delete designer asset %s: %v", filename, err)
+	}
+}
+
+// present fills in the share link and ordering details of a design
+func (s *Service) present(d *Design) *Design {
+	if s.shareURL != "" {
+		d.ShareURL = s.shareURL + "/" + d.Token
+	}
+	if s.productID > 0 {
+		d.ProductID, d.Surcharge = s.productID, s.surcharge
+	}
+	return d
+}
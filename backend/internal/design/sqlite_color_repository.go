@@ -0,0 +1,166 @@
+package design
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const colorColumns = `id, name, hex, mockup_url, mockup_filename, active, sort_order, created_at, updated_at`
+
+// GetColors retrieves shirt colors ordered by sort order and name, only active ones when activeOnly is set
+func (r *SQLiteRepository) GetColors(ctx context.Context, activeOnly bool) ([]*Color, error) {
+	query := "SELECT " + colorColumns + " FROM shirt_colors"
+	if activeOnly {
+		query += " WHERE active = 1"
+	}
+	query += " ORDER BY sort_order ASC, name ASC"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shirt colors: %w", err)
+	}
+	defer rows.Close()
+
+	colors := []*Color{}
+	for rows.Next() {
+		c, err := scanColor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shirt color: %w", err)
+		}
+		colors = append(colors, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return colors, nil
+}
+
+// GetColor retrieves a single shirt color by ID
+func (r *SQLiteRepository) GetColor(ctx context.Context, id int64) (*Color, error) {
+	return r.getColor(ctx, "id = ?", id)
+}
+
+// GetColorByName retrieves a single shirt color by name, ignoring case
+func (r *SQLiteRepository) GetColorByName(ctx context.Context, name string) (*Color, error) {
+	return r.getColor(ctx, "name = ?", name)
+}
+
+// getColor retrieves the shirt color matching a condition
+func (r *SQLiteRepository) getColor(ctx context.Context, condition string, arg interface{}) (*Color, error) {
+	row := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM shirt_colors WHERE %s", colorColumns, condition), arg)
+	c, err := scanColor(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrColorNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shirt color: %w", err)
+	}
+
+	return c, nil
+}
+
+// CreateColor creates a new shirt color
+func (r *SQLiteRepository) CreateColor(ctx context.Context, input CreateColorInput) (*Color, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO shirt_colors (name, hex, active, sort_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Hex, *input.Active, input.SortOrder, now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateColor
+		}
+		return nil, fmt.Errorf("failed to create shirt color: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return r.GetColor(ctx, id)
+}
+
+// UpdateColor updates an existing shirt color
+func (r *SQLiteRepository) UpdateColor(ctx context.Context, id int64, input UpdateColorInput) (*Color, error) {
+	var setClauses []string
+	var args []interface{}
+
+	if input.Name != nil {
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, *input.Name)
+	}
+	if input.Hex != nil {
+		setClauses = append(setClauses, "hex = ?")
+		args = append(args, *input.Hex)
+	}
+	if input.Active != nil {
+		setClauses = append(setClauses, "active = ?")
+		args = append(args, *input.Active)
+	}
+	if input.SortOrder != nil {
+		setClauses = append(setClauses, "sort_order = ?")
+		args = append(args, *input.SortOrder)
+	}
+
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, time.Now(), id)
+
+	query := fmt.Sprintf("UPDATE shirt_colors SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateColor
+		}
+		return nil, fmt.Errorf("failed to update shirt color: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrColorNotFound
+	}
+
+	return r.GetColor(ctx, id)
+}
+
+// SetColorMockup sets the mockup photo of a shirt color
+func (r *SQLiteRepository) SetColorMockup(ctx context.Context, id int64, url, filename string) (*Color, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE shirt_colors SET mockup_url = ?, mockup_filename = ?, updated_at = ? WHERE id = ?",
+		url, filename, time.Now(), id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set shirt color mockup: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrColorNotFound
+	}
+
+	return r.GetColor(ctx, id)
+}
+
+// DeleteColor removes a shirt color no design uses
+func (r *SQLiteRepository) DeleteColor(ctx context.Context, id int64) error {
+	if _, err := r.GetColor(ctx, id); err != nil {
+		return err
+	}
+
+	var designs int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM designs WHERE shirt_color_id = ?", id).Scan(&designs); err != nil {
+		return fmt.Errorf("failed to count designs: %w", err)
+	}
+	if designs > 0 {
+		return ErrInUse
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM shirt_colors WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete shirt color: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package design
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SQLiteRepository implements Repository using SQLite. Text layers and fonts
+// are kept as JSON.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite design repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// designColumns lists the columns scanned by scanDesign
+const designColumns = `id, customer_id, token, template_id, template_name, shirt_color_id, shirt_color, shirt_color_hex, text_layers,
+	fonts, canvas, width, height, image_url, image_filename, print_url, print_filename, print_width_cm, print_height_cm,
+	mockup_url, mockup_filename, rendered_at, created_at`
+
+// Create stores a design with its token and image already set
+func (r *SQLiteRepository) Create(ctx context.Context, d *Design) (*Design, error) {
+	layers, err := json.Marshal(d.TextLayers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode text layers: %w", err)
+	}
+	fonts, err := json.Marshal(d.Fonts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fonts: %w", err)
+	}
+	var canvas interface{}
+	if len(d.Canvas) > 0 {
+		canvas = string(d.Canvas)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO designs (customer_id, token, template_id, template_name, shirt_color_id, shirt_color, shirt_color_hex,
+			text_layers, fonts, canvas, width, height, image_url, image_filename, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, nullID(d.CustomerID), d.Token, d.TemplateID, d.TemplateName, nullID(d.ShirtColor.ID), d.ShirtColor.Name, d.ShirtColor.Hex,
+		string(layers), string(fonts), canvas, d.Width, d.Height, d.ImageURL, d.imageFilename, time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create design: %w", err)
+	}
+
+	return r.GetByToken(ctx, d.Token)
+}
+
+// GetByToken retrieves a design by its share token
+func (r *SQLiteRepository) GetByToken(ctx context.Context, token string) (*Design, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+designColumns+" FROM designs WHERE token = ?", token)
+	d, err := scanDesign(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get design: %w", err)
+	}
+
+	return d, nil
+}
+
+// GetByCustomer retrieves the designs saved by a customer, newest first
+func (r *SQLiteRepository) GetByCustomer(ctx context.Context, customerID int64) ([]*Design, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+designColumns+" FROM designs WHERE customer_id = ? ORDER BY created_at DESC, id DESC",
+		customerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query designs: %w", err)
+	}
+	defer rows.Close()
+
+	designs := []*Design{}
+	for rows.Next() {
+		d, err := scanDesign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan design: %w", err)
+		}
+		designs = append(designs, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return designs, nil
+}
+
+// SetRender stores the print and mockup images of a design
+func (r *SQLiteRepository) SetRender(ctx context.Context, id int64, render *Render) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE designs
+		SET print_url = ?, print_filename = ?, print_width_cm = ?, print_height_cm = ?,
+			mockup_url = ?, mockup_filename = ?, rendered_at = ?
+		WHERE id = ?
+	`, render.PrintURL, render.printFilename, render.WidthCM, render.HeightCM,
+		render.MockupURL, render.mockupFilename, render.RenderedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set design render: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// scanDesign scans a database row into a Design
+func scanDesign(row interface{ Scan(...interface{}) error }) (*Design, error) {
+	var d Design
+	var layers, fonts string
+	var canvas sql.NullString
+	var customerID, colorID sql.NullInt64
+	var render Render
+	var renderedAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID,
+		&customerID,
+		&d.Token,
+		&d.TemplateID,
+		&d.TemplateName,
+		&colorID,
+		&d.ShirtColor.Name,
+		&d.ShirtColor.Hex,
+		&layers,
+		&fonts,
+		&canvas,
+		&d.Width,
+		&d.Height,
+		&d.ImageURL,
+		&d.imageFilename,
+		&render.PrintURL,
+		&render.printFilename,
+		&render.WidthCM,
+		&render.HeightCM,
+		&render.MockupURL,
+		&render.mockupFilename,
+		&renderedAt,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(layers), &d.TextLayers); err != nil {
+		return nil, fmt.Errorf("failed to decode text layers: %w", err)
+	}
+	if err := json.Unmarshal([]byte(fonts), &d.Fonts); err != nil {
+		return nil, fmt.Errorf("failed to decode fonts: %w", err)
+	}
+	if canvas.Valid {
+		d.Canvas = json.RawMessage(canvas.String)
+	}
+	d.CustomerID = customerID.Int64
+	d.ShirtColor.ID = colorID.Int64
+	if renderedAt.Valid {
+		render.RenderedAt = renderedAt.Time
+		render.Width, render.Height = printPixels(render.WidthCM), printPixels(render.HeightCM)
+		render.DPI = PrintDPI
+		d.Render = &render
+	}
+
+	return &d, nil
+}
+
+// nullID stores an optional ID, with 0 meaning none
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id > 0}
+}
@@ -0,0 +1,185 @@
+package design
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const templateColumns = `id, slug, name, category, description, tags, preview_url, preview_filename, print_url,
+	print_filename, active, sort_order, created_at, updated_at`
+
+// GetTemplates retrieves templates ordered by sort order and name, only active ones when activeOnly is set
+func (r *SQLiteRepository) GetTemplates(ctx context.Context, activeOnly bool) ([]*Template, error) {
+	query := "SELECT " + templateColumns + " FROM design_templates"
+	if activeOnly {
+		query += " WHERE active = 1"
+	}
+	query += " ORDER BY sort_order ASC, name ASC"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query design templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan design template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetTemplate retrieves a single template by ID
+func (r *SQLiteRepository) GetTemplate(ctx context.Context, id int64) (*Template, error) {
+	return r.getTemplate(ctx, "id = ?", id)
+}
+
+// GetTemplateBySlug retrieves a single template by slug
+func (r *SQLiteRepository) GetTemplateBySlug(ctx context.Context, slug string) (*Template, error) {
+	return r.getTemplate(ctx, "slug = ?", slug)
+}
+
+// getTemplate retrieves the template matching a condition
+func (r *SQLiteRepository) getTemplate(ctx context.Context, condition string, arg interface{}) (*Template, error) {
+	row := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM design_templates WHERE %s", templateColumns, condition), arg)
+	t, err := scanTemplate(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get design template: %w", err)
+	}
+
+	return t, nil
+}
+
+// CreateTemplate creates a new template
+func (r *SQLiteRepository) CreateTemplate(ctx context.Context, input CreateTemplateInput) (*Template, error) {
+	tags, err := json.Marshal(input.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template tags: %w", err)
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO design_templates (slug, name, category, description, tags, active, sort_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Slug, input.Name, input.Category, input.Description, string(tags), *input.Active, input.SortOrder, now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateSlug
+		}
+		return nil, fmt.Errorf("failed to create design template: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return r.GetTemplate(ctx, id)
+}
+
+// UpdateTemplate updates an existing template
+func (r *SQLiteRepository) UpdateTemplate(ctx context.Context, id int64, input UpdateTemplateInput) (*Template, error) {
+	var setClauses []string
+	var args []interface{}
+
+	if input.Name != nil {
+		setClauses = append(setClauses, "name = ?")
+		args = append(args, *input.Name)
+	}
+	if input.Category != nil {
+		setClauses = append(setClauses, "category = ?")
+		args = append(args, *input.Category)
+	}
+	if input.Description != nil {
+		setClauses = append(setClauses, "description = ?")
+		args = append(args, *input.Description)
+	}
+	if input.Tags != nil {
+		tags, err := json.Marshal(*input.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode template tags: %w", err)
+		}
+		setClauses = append(setClauses, "tags = ?")
+		args = append(args, string(tags))
+	}
+	if input.Active != nil {
+		setClauses = append(setClauses, "active = ?")
+		args = append(args, *input.Active)
+	}
+	if input.SortOrder != nil {
+		setClauses = append(setClauses, "sort_order = ?")
+		args = append(args, *input.SortOrder)
+	}
+
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, time.Now(), id)
+
+	query := fmt.Sprintf("UPDATE design_templates SET %s WHERE id = ?", strings.Join(setClauses, ", "))
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update design template: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	return r.GetTemplate(ctx, id)
+}
+
+// SetTemplateImage sets the preview or print image of a template
+func (r *SQLiteRepository) SetTemplateImage(ctx context.Context, id int64, kind, url, filename string) (*Template, error) {
+	query := "UPDATE design_templates SET preview_url = ?, preview_filename = ?, updated_at = ? WHERE id = ?"
+	if kind == ImagePrint {
+		query = "UPDATE design_templates SET print_url = ?, print_filename = ?, updated_at = ? WHERE id = ?"
+	}
+
+	result, err := r.db.ExecContext(ctx, query, url, filename, time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set design template image: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	return r.GetTemplate(ctx, id)
+}
+
+// DeleteTemplate removes a template no design uses
+func (r *SQLiteRepository) DeleteTemplate(ctx context.Context, id int64) error {
+	t, err := r.GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var designs int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM designs WHERE template_id = ?", t.Slug).Scan(&designs); err != nil {
+		return fmt.Errorf("failed to count designs: %w", err)
+	}
+	if designs > 0 {
+		return ErrInUse
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM design_templates WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete design template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package design
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Template images
+const (
+	ImagePreview = "preview" // Shown in the designer gallery and canvas
+	ImagePrint   = "print"   // High resolution PNG sent to the printer
+)
+
+// Template is a base image customers start a design from
+type Template struct {
+	ID          int64     `json:"id"`
+	Slug        string    `json:"slug"` // Designs refer to the template by slug, so it never changes
+	Name        string    `json:"name"`
+	Category    string    `json:"category,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags"`
+	PreviewURL  string    `json:"preview_url"`
+	PrintURL    string    `json:"print_url,omitempty"` // Left out of the public listing
+	Active      bool      `json:"active"`
+	SortOrder   int       `json:"sort_order"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	previewFilename string
+	printFilename   string
+}
+
+// Catalog is what the designer offers: the active templates and shirt colors
+type Catalog struct {
+	Templates []*Template `json:"templates"`
+	Colors    []*Color    `json:"colors"`
+}
+
+// CreateTemplateInput represents input for creating a template. Images are
+// uploaded once the template exists.
+type CreateTemplateInput struct {
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Active      *bool    `json:"active,omitempty"` // Defaults to true
+	SortOrder   int      `json:"sort_order"`
+}
+
+// UpdateTemplateInput represents input for updating a template
+type UpdateTemplateInput struct {
+	Name        *string   `json:"name,omitempty"`
+	Category    *string   `json:"category,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+	SortOrder   *int      `json:"sort_order,omitempty"`
+}
+
+// Validate validates template creation input and derives the slug from the name when empty
+func (input *CreateTemplateInput) Validate() error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return ErrInvalidInput("name is required")
+	}
+	if input.Slug == "" {
+		input.Slug = input.Name
+	}
+	input.Slug = slug.Make(input.Slug)
+	if input.Slug == "" {
+		return ErrInvalidInput("slug must contain letters or digits")
+	}
+	input.Category = strings.TrimSpace(input.Category)
+	input.Description = strings.TrimSpace(input.Description)
+	input.Tags = normalizeTags(input.Tags)
+	if input.Active == nil {
+		active := true
+		input.Active = &active
+	}
+	return nil
+}
+
+// Validate validates template update input
+func (input *UpdateTemplateInput) Validate() error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return ErrInvalidInput("name cannot be empty")
+		}
+		input.Name = &name
+	}
+	for _, field := range []*string{input.Category, input.Description} {
+		if field != nil {
+			*field = strings.TrimSpace(*field)
+		}
+	}
+	if input.Tags != nil {
+		tags := normalizeTags(*input.Tags)
+		input.Tags = &tags
+	}
+	return nil
+}
+
+// normalizeTags trims tags, dropping empty and repeated ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// scanTemplate scans a database row into a Template
+func scanTemplate(row interface{ Scan(...interface{}) error }) (*Template, error) {
+	var t Template
+	var tags string
+
+	err := row.Scan(
+		&t.ID,
+		&t.Slug,
+		&t.Name,
+		&t.Category,
+		&t.Description,
+		&tags,
+		&t.PreviewURL,
+		&t.previewFilename,
+		&t.PrintURL,
+		&t.printFilename,
+		&t.Active,
+		&t.SortOrder,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &t.Tags); err != nil {
+		return nil, fmt.Errorf("failed to decode template tags: %w", err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,19 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrProductNotFound indicates the product or variant of a movement does not exist
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrInsufficientStock indicates a movement would leave stock below zero
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("invalid input: %s", msg)
+	}
+)
@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MovementType classifies a stock movement
+type MovementType string
+
+const (
+	// MovementPurchase adds stock received from a supplier
+	MovementPurchase MovementType = "purchase"
+	// MovementSale removes stock sold to a customer
+	MovementSale MovementType = "sale"
+	// MovementAdjustment corrects stock after a count, in either direction
+	MovementAdjustment MovementType = "adjustment"
+	// MovementReturn adds stock returned by a customer
+	MovementReturn MovementType = "return"
+)
+
+// Movement represents an append-only stock ledger entry.
+// Quantity is signed: positive adds stock, negative removes it.
+type Movement struct {
+	ID        int64        `json:"id"`
+	ProductID int64        `json:"product_id"`
+	VariantID *int64       `json:"variant_id,omitempty"`
+	Type      MovementType `json:"type"`
+	Quantity  int          `json:"quantity"`
+	Reason    string       `json:"reason"`
+	AdminID   *int64       `json:"admin_id,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// RecordMovementInput represents input for recording a stock movement.
+// Quantity is always positive for purchases, sales and returns; the sign
+// is derived from the type. Adjustments take a signed, non-zero quantity.
+type RecordMovementInput struct {
+	ProductID int64        `json:"product_id"`
+	VariantID *int64       `json:"variant_id,omitempty"`
+	Type      MovementType `json:"type"`
+	Quantity  int          `json:"quantity"`
+	Reason    string       `json:"reason"`
+	AdminID   int64        `json:"-"` // Taken from the authenticated admin
+}
+
+// StockLevel represents the current on-hand quantity of a product
+type StockLevel struct {
+	ProductID   int64  `json:"product_id"`
+	ProductName string `json:"product_name"`
+	OnHand      int    `json:"on_hand"`
+}
+
+// ListMovementsFilters contains filters for movement listing
+type ListMovementsFilters struct {
+	Page      int
+	Limit     int
+	ProductID int64
+	Type      MovementType
+}
+
+// Validate validates movement input
+func (input *RecordMovementInput) Validate() error {
+	if input.ProductID <= 0 {
+		return ErrInvalidInput("product_id is required")
+	}
+
+	switch input.Type {
+	case MovementPurchase, MovementSale, MovementReturn:
+		if input.Quantity <= 0 {
+			return ErrInvalidInput("quantity must be greater than 0")
+		}
+	case MovementAdjustment:
+		if input.Quantity == 0 {
+			return ErrInvalidInput("adjustment quantity cannot be 0")
+		}
+		if input.Reason == "" {
+			return ErrInvalidInput("adjustments require a reason")
+		}
+	default:
+		return ErrInvalidInput("type must be one of purchase, sale, adjustment, return")
+	}
+
+	return nil
+}
+
+// delta returns the signed stock change of the movement
+func (input *RecordMovementInput) delta() int {
+	if input.Type == MovementSale {
+		return -input.Quantity
+	}
+	return input.Quantity
+}
+
+// scanMovement scans a database row into a Movement
+func scanMovement(row interface{ Scan(...interface{}) error }) (*Movement, error) {
+	var m Movement
+	var variantID, adminID sql.NullInt64
+	var reason sql.NullString
+
+	err := row.Scan(
+		&m.ID,
+		&m.ProductID,
+		&variantID,
+		&m.Type,
+		&m.Quantity,
+		&reason,
+		&adminID,
+		&m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantID.Valid {
+		m.VariantID = &variantID.Int64
+	}
+	if adminID.Valid {
+		m.AdminID = &adminID.Int64
+	}
+	m.Reason = reason.String
+
+	return &m, nil
+}
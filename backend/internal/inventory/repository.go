@@ -0,0 +1,18 @@
+package inventory
+
+import "context"
+
+// Repository defines the interface for inventory data access
+type Repository interface {
+	// Record appends a movement to the ledger
+	Record(ctx context.Context, input RecordMovementInput) (*Movement, error)
+
+	// ListMovements retrieves ledger entries with pagination and filters, newest first
+	ListMovements(ctx context.Context, filters ListMovementsFilters) ([]*Movement, int, error)
+
+	// GetStockLevel computes the current on-hand quantity of a product
+	GetStockLevel(ctx context.Context, productID int64) (*StockLevel, error)
+
+	// ListBelowThreshold retrieves tracked products whose on-hand is at or below threshold
+	ListBelowThreshold(ctx context.Context, threshold int) ([]*StockLevel, error)
+}
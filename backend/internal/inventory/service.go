@@ -0,0 +1,47 @@
+package inventory
+
+import "context"
+
+// Service provides business logic for inventory
+type Service struct {
+	repo              Repository
+	lowStockThreshold int
+}
+
+// NewService creates a new inventory service.
+// lowStockThreshold is used when a low-stock query does not specify one.
+func NewService(repo Repository, lowStockThreshold int) *Service {
+	return &Service{
+		repo:              repo,
+		lowStockThreshold: lowStockThreshold,
+	}
+}
+
+// RecordMovement validates and appends a stock movement to the ledger
+func (s *Service) RecordMovement(ctx context.Context, input RecordMovementInput) (*Movement, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Record(ctx, input)
+}
+
+// GetMovements retrieves ledger entries with filters
+func (s *Service) GetMovements(ctx context.Context, filters ListMovementsFilters) ([]*Movement, int, error) {
+	return s.repo.ListMovements(ctx, filters)
+}
+
+// GetStockLevel retrieves the current on-hand quantity of a product
+func (s *Service) GetStockLevel(ctx context.Context, productID int64) (*StockLevel, error) {
+	return s.repo.GetStockLevel(ctx, productID)
+}
+
+// GetLowStock retrieves products at or below the threshold.
+// A threshold below zero falls back to the configured default.
+func (s *Service) GetLowStock(ctx context.Context, threshold int) ([]*StockLevel, error) {
+	if threshold < 0 {
+		threshold = s.lowStockThreshold
+	}
+
+	return s.repo.ListBelowThreshold(ctx, threshold)
+}
@@ -0,0 +1,214 @@
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/inventory"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	productPrice      = 550000
+	variantSize       = "M"
+	variantColor      = "Negro"
+	variantStock      = 10
+	lowStockThreshold = 3
+	countReason       = "Conteo mensual"
+	missingID         = int64(9999)
+)
+
+type inventoryServiceSuite struct {
+	suite.Suite
+	ctx      context.Context
+	products *product.Service
+	svc      *inventory.Service
+}
+
+func TestInventoryServiceSuite(t *testing.T) {
+	suite.Run(t, new(inventoryServiceSuite))
+}
+
+func (s *inventoryServiceSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.products = product.NewService(product.NewSQLiteRepository(db))
+	s.svc = inventory.NewService(inventory.NewSQLiteRepository(db), lowStockThreshold)
+}
+
+// createVariantProduct creates a product with one variant holding variantStock
+func (s *inventoryServiceSuite) createVariantProduct(name string) *product.Product {
+	p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     name,
+		Price:    productPrice,
+		Sizes:    []string{variantSize},
+		Colors:   []string{variantColor},
+		Variants: []product.CreateVariantInput{{Size: variantSize, Color: variantColor, Stock: variantStock}},
+	})
+	s.Require().NoError(err)
+	return p
+}
+
+// stock returns the on-hand of a product and the stock of its first variant
+func (s *inventoryServiceSuite) stock(productID int64) (int, int) {
+	level, err := s.svc.GetStockLevel(s.ctx, productID)
+	s.Require().NoError(err)
+	variants, err := s.products.GetVariants(s.ctx, productID)
+	s.Require().NoError(err)
+	return level.OnHand, variants[0].Stock
+}
+
+var variantMovementCases = []struct {
+	name             string
+	movementType     inventory.MovementType
+	quantity         int
+	reason           string
+	wantErr          error
+	wantVariantStock int
+}{
+	{name: "Sale within variant stock", movementType: inventory.MovementSale, quantity: 1, wantVariantStock: 9},
+	{name: "Sale of all variant stock", movementType: inventory.MovementSale, quantity: variantStock, wantVariantStock: 0},
+	{name: "Sale above variant stock", movementType: inventory.MovementSale, quantity: variantStock + 1, wantErr: inventory.ErrInsufficientStock, wantVariantStock: variantStock},
+	{name: "Purchase", movementType: inventory.MovementPurchase, quantity: 5, wantVariantStock: 15},
+	{name: "Return", movementType: inventory.MovementReturn, quantity: 2, wantVariantStock: 12},
+	{name: "Negative adjustment", movementType: inventory.MovementAdjustment, quantity: -4, reason: countReason, wantVariantStock: 6},
+	{name: "Adjustment below zero", movementType: inventory.MovementAdjustment, quantity: -11, reason: countReason, wantErr: inventory.ErrInsufficientStock, wantVariantStock: variantStock},
+}
+
+func (s *inventoryServiceSuite) TestRecordMovement_Variant() {
+	for _, tc := range variantMovementCases {
+		p := s.createVariantProduct(tc.name)
+
+		_, err := s.svc.RecordMovement(s.ctx, inventory.RecordMovementInput{
+			ProductID: p.ID,
+			VariantID: &p.Variants[0].ID,
+			Type:      tc.movementType,
+			Quantity:  tc.quantity,
+			Reason:    tc.reason,
+		})
+
+		onHand, variantStock := s.stock(p.ID)
+		s.ErrorIs(err, tc.wantErr, tc.name)
+		s.Equal(tc.wantVariantStock, variantStock, tc.name)
+		s.Equal(tc.wantVariantStock, onHand, tc.name)
+	}
+}
+
+var productMovementCases = []struct {
+	name         string
+	movements    []inventory.RecordMovementInput
+	wantErr      error
+	wantOnHand   int
+	wantMovement int // Movements in the ledger after the last one
+}{
+	{
+		name:         "Purchase starts tracking",
+		movements:    []inventory.RecordMovementInput{{Type: inventory.MovementPurchase, Quantity: 4}},
+		wantOnHand:   4,
+		wantMovement: 1,
+	},
+	{
+		name:         "Sale within on-hand",
+		movements:    []inventory.RecordMovementInput{{Type: inventory.MovementPurchase, Quantity: 4}, {Type: inventory.MovementSale, Quantity: 3}},
+		wantOnHand:   1,
+		wantMovement: 2,
+	},
+	{
+		name:         "Sale above on-hand",
+		movements:    []inventory.RecordMovementInput{{Type: inventory.MovementPurchase, Quantity: 4}, {Type: inventory.MovementSale, Quantity: 5}},
+		wantErr:      inventory.ErrInsufficientStock,
+		wantOnHand:   4,
+		wantMovement: 1,
+	},
+	{
+		name:         "Adjustment without reason",
+		movements:    []inventory.RecordMovementInput{{Type: inventory.MovementAdjustment, Quantity: 2}},
+		wantErr:      inventory.ErrInvalidInput("adjustments require a reason"),
+		wantOnHand:   0,
+		wantMovement: 0,
+	},
+}
+
+func (s *inventoryServiceSuite) TestRecordMovement_Product() {
+	for _, tc := range productMovementCases {
+		p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: tc.name, Price: productPrice})
+		s.Require().NoError(err)
+
+		for _, movement := range tc.movements {
+			movement.ProductID = p.ID
+			_, err = s.svc.RecordMovement(s.ctx, movement)
+		}
+
+		level, levelErr := s.svc.GetStockLevel(s.ctx, p.ID)
+		s.Require().NoError(levelErr)
+		_, total, listErr := s.svc.GetMovements(s.ctx, inventory.ListMovementsFilters{ProductID: p.ID})
+		s.Require().NoError(listErr)
+		s.Equal(tc.wantErr, err, tc.name)
+		s.Equal(tc.wantOnHand, level.OnHand, tc.name)
+		s.Equal(tc.wantMovement, total, tc.name)
+	}
+}
+
+func (s *inventoryServiceSuite) TestRecordMovement_NotFound() {
+	p := s.createVariantProduct("Remera sin variante")
+	missing := missingID
+
+	_, productErr := s.svc.RecordMovement(s.ctx, inventory.RecordMovementInput{ProductID: missingID, Type: inventory.MovementPurchase, Quantity: 1})
+	_, variantErr := s.svc.RecordMovement(s.ctx, inventory.RecordMovementInput{ProductID: p.ID, VariantID: &missing, Type: inventory.MovementPurchase, Quantity: 1})
+
+	s.ErrorIs(productErr, inventory.ErrProductNotFound)
+	s.ErrorIs(variantErr, inventory.ErrProductNotFound)
+}
+
+func (s *inventoryServiceSuite) TestVariantStockFollowsLedger() {
+	p := s.createVariantProduct("Remera con variantes")
+	newStock := 25
+	second, err := s.products.CreateVariant(s.ctx, p.ID, product.CreateVariantInput{Size: "L", Color: variantColor, Stock: 7})
+	s.Require().NoError(err)
+
+	_, err = s.products.UpdateVariant(s.ctx, p.ID, p.Variants[0].ID, product.UpdateVariantInput{Stock: &newStock})
+	s.Require().NoError(err)
+	updated, err := s.products.GetProduct(s.ctx, p.ID)
+	s.Require().NoError(err)
+	s.Equal(newStock+7, *updated.Stock)
+
+	s.Require().NoError(s.products.DeleteVariant(s.ctx, p.ID, second.ID))
+	afterDelete, err := s.products.GetProduct(s.ctx, p.ID)
+	s.Require().NoError(err)
+	s.Equal(newStock, *afterDelete.Stock)
+	s.False(afterDelete.OutOfStock)
+	onHand, _ := s.stock(p.ID)
+	s.Equal(newStock, onHand)
+
+	movements, _, err := s.svc.GetMovements(s.ctx, inventory.ListMovementsFilters{Page: 1, Limit: 20, ProductID: p.ID})
+	s.Require().NoError(err)
+	var detached int
+	for _, m := range movements {
+		if m.VariantID == nil {
+			detached++
+		}
+	}
+	s.Equal(2, detached) // The deleted variant's initial stock and its removal
+}
+
+func (s *inventoryServiceSuite) TestGetLowStock() {
+	low := s.createVariantProduct("Remera casi agotada")
+	s.createVariantProduct("Remera con stock")
+	_, err := s.svc.RecordMovement(s.ctx, inventory.RecordMovementInput{
+		ProductID: low.ID,
+		VariantID: &low.Variants[0].ID,
+		Type:      inventory.MovementSale,
+		Quantity:  variantStock - lowStockThreshold,
+	})
+	s.Require().NoError(err)
+
+	levels, err := s.svc.GetLowStock(s.ctx, -1)
+
+	s.Require().NoError(err)
+	s.Len(levels, 1)
+	s.Equal(low.ID, levels[0].ProductID)
+	s.Equal(lowStockThreshold, levels[0].OnHand)
+}
@@ -0,0 +1,233 @@
+package inventory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite inventory repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// Record appends a movement to the ledger and keeps the variant stock in sync
+func (r *SQLiteRepository) Record(ctx context.Context, input RecordMovementInput) (*Movement, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check if product exists and get its current on-hand
+	var onHand int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT SUM(quantity) FROM inventory_movements WHERE product_id = p.id), 0)
+		FROM products p
+		WHERE p.id = ? AND p.deleted_at IS NULL
+	`, input.ProductID).Scan(&onHand)
+	if err == sql.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock level: %w", err)
+	}
+
+	// Variant movements only draw on the variant's own stock, which is part
+	// of the product's on-hand
+	delta := input.delta()
+	if input.VariantID == nil && onHand+delta < 0 {
+		return nil, ErrInsufficientStock
+	}
+
+	if input.VariantID != nil {
+		var variantStock int
+		err = tx.QueryRowContext(ctx,
+			"SELECT stock FROM product_variants WHERE id = ? AND product_id = ?",
+			*input.VariantID, input.ProductID,
+		).Scan(&variantStock)
+		if err == sql.ErrNoRows {
+			return nil, ErrProductNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get variant stock: %w", err)
+		}
+		if variantStock+delta < 0 {
+			return nil, ErrInsufficientStock
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE product_variants SET stock = stock + ?, updated_at = ? WHERE id = ?",
+			delta, time.Now(), *input.VariantID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update variant stock: %w", err)
+		}
+	}
+
+	var adminID interface{}
+	if input.AdminID > 0 {
+		adminID = input.AdminID
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO inventory_movements (product_id, variant_id, type, quantity, reason, admin_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, input.ProductID, input.VariantID, input.Type, delta, input.Reason, adminID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to record movement: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT id, product_id, variant_id, type, quantity, reason, admin_id, created_at
+		FROM inventory_movements
+		WHERE id = ?
+	`, id)
+	movement, err := scanMovement(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit movement: %w", err)
+	}
+
+	return movement, nil
+}
+
+// ListMovements retrieves ledger entries with pagination and filters, newest first
+func (r *SQLiteRepository) ListMovements(ctx context.Context, filters ListMovementsFilters) ([]*Movement, int, error) {
+	var whereClauses []string
+	var args []interface{}
+
+	if filters.ProductID > 0 {
+		whereClauses = append(whereClauses, "product_id = ?")
+		args = append(args, filters.ProductID)
+	}
+	if filters.Type != "" {
+		whereClauses = append(whereClauses, "type = ?")
+		args = append(args, filters.Type)
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM inventory_movements %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count movements: %w", err)
+	}
+
+	// Pagination
+	limit := filters.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	offset := 0
+	if filters.Page > 1 {
+		offset = (filters.Page - 1) * limit
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, product_id, variant_id, type, quantity, reason, admin_id, created_at
+		FROM inventory_movements
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, whereClause)
+
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := []*Movement{}
+	for rows.Next() {
+		movement, err := scanMovement(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan movement: %w", err)
+		}
+		movements = append(movements, movement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return movements, total, nil
+}
+
+// GetStockLevel computes the current on-hand quantity of a product
+func (r *SQLiteRepository) GetStockLevel(ctx context.Context, productID int64) (*StockLevel, error) {
+	query := `
+		SELECT p.id, p.name, COALESCE(SUM(m.quantity), 0)
+		FROM products p
+		LEFT JOIN inventory_movements m ON m.product_id = p.id
+		WHERE p.id = ? AND p.deleted_at IS NULL
+		GROUP BY p.id
+	`
+
+	var level StockLevel
+	err := r.db.QueryRowContext(ctx, query, productID).Scan(&level.ProductID, &level.ProductName, &level.OnHand)
+	if err == sql.ErrNoRows {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock level: %w", err)
+	}
+
+	return &level, nil
+}
+
+// ListBelowThreshold retrieves tracked products whose on-hand is at or below threshold.
+// Products without any movement are not tracked and never reported.
+func (r *SQLiteRepository) ListBelowThreshold(ctx context.Context, threshold int) ([]*StockLevel, error) {
+	query := `
+		SELECT p.id, p.name, SUM(m.quantity) AS on_hand
+		FROM products p
+		JOIN inventory_movements m ON m.product_id = p.id
+		WHERE p.deleted_at IS NULL
+		GROUP BY p.id
+		HAVING on_hand <= ?
+		ORDER BY on_hand ASC, p.name ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query low stock: %w", err)
+	}
+	defer rows.Close()
+
+	levels := []*StockLevel{}
+	for rows.Next() {
+		var level StockLevel
+		if err := rows.Scan(&level.ProductID, &level.ProductName, &level.OnHand); err != nil {
+			return nil, fmt.Errorf("failed to scan stock level: %w", err)
+		}
+		levels = append(levels, &level)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return levels, nil
+}
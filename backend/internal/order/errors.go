@@ -0,0 +1,28 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates an order was not found
+	ErrNotFound = errors.New("order not found")
+
+	// ErrTransition indicates the order cannot move to the requested status
+	ErrTransition = errors.New("invalid status transition")
+
+	// ErrOutOfStock indicates there is not enough stock for an order item
+	ErrOutOfStock = errors.New("not enough stock")
+
+	// errDuplicateReference indicates the generated reference is taken; the order is retried with another
+	errDuplicateReference = errors.New("order reference already exists")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
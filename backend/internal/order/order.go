@@ -0,0 +1,357 @@
+package order
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Order statuses
+const (
+	StatusPending   = "pending"   // Placed, waiting for the shop to confirm it
+	StatusConfirmed = "confirmed" // Accepted by the shop, waiting for payment
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+)
+
+// Order sources
+const (
+	SourceAdmin    = "admin"    // Recorded by the shop
+	SourceWhatsApp = "whatsapp" // Placed through the WhatsApp checkout
+)
+
+// transitions lists the statuses each status can move to
+var transitions = map[string][]string{
+	StatusPending:   {StatusConfirmed, StatusPaid, StatusCancelled}, // Paid online without confirmation
+	StatusConfirmed: {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered},
+}
+
+// Order is a purchase with a snapshot of the products bought
+type Order struct {
+	ID            int64           `json:"id"`
+	Reference     string          `json:"reference"` // Short code the customer quotes, e.g. K7Q2MX
+	Status        string          `json:"status"`
+	PaymentStatus string          `json:"payment_status,omitempty"` // Status of the latest online payment
+	Source        string          `json:"source"`
+	Customer      Customer        `json:"customer"`
+	Notes         string          `json:"notes,omitempty"`
+	Coupon        string          `json:"coupon,omitempty"`
+	Items         []*Item         `json:"items,omitempty"` // Left out of listings
+	ItemCount     int             `json:"item_count"`
+	Subtotal      int             `json:"subtotal"`
+	Discount      int             `json:"discount"`
+	Total         int             `json:"total"`
+	AdminID       *int64          `json:"admin_id,omitempty"`    // Admin who recorded the order, if any
+	CustomerID    *int64          `json:"customer_id,omitempty"` // Customer account that placed the order, if any
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	ConfirmedAt   *time.Time      `json:"confirmed_at,omitempty"`
+	PaidAt        *time.Time      `json:"paid_at,omitempty"`
+	ShippedAt     *time.Time      `json:"shipped_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CancelledAt   *time.Time      `json:"cancelled_at,omitempty"`
+	History       []*StatusChange `json:"history,omitempty"`      // Left out of listings
+	Reservations  []*Reservation  `json:"reservations,omitempty"` // Left out of listings
+}
+
+// Customer holds the contact and shipping data of an order
+type Customer struct {
+	Name       string `json:"name"`
+	Phone      string `json:"phone,omitempty"`
+	Email      string `json:"email,omitempty"`
+	Address    string `json:"address,omitempty"`
+	City       string `json:"city,omitempty"`
+	Province   string `json:"province,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+}
+
+// Item is an order line. Product data is copied at purchase time so later
+// catalog changes do not alter the order.
+type Item struct {
+	ID            int64       `json:"id"`
+	ProductID     int64       `json:"product_id"`
+	VariantID     *int64      `json:"variant_id,omitempty"`
+	Name          string      `json:"name"`
+	SKU           string      `json:"sku,omitempty"`
+	Size          string      `json:"size,omitempty"`
+	Color         string      `json:"color,omitempty"`
+	Quantity      int         `json:"quantity"`
+	UnitPrice     int         `json:"unit_price"`
+	Subtotal      int         `json:"subtotal"`
+	Discount      int         `json:"discount"`
+	Total         int         `json:"total"`
+	PromotionID   *int64      `json:"promotion_id,omitempty"`
+	PromotionName string      `json:"promotion_name,omitempty"`
+	Design        *ItemDesign `json:"design,omitempty"` // Custom design printed on the product, if any
+}
+
+// ItemDesign is a custom design printed on an order item. The surcharge is
+// charged per unit on top of the product price and promotions do not
+// discount it.
+type ItemDesign struct {
+	ID        int64  `json:"id"`
+	Token     string `json:"token"`
+	ImageURL  string `json:"image_url"`
+	Surcharge int    `json:"surcharge"`
+}
+
+// StatusChange is an entry of an order's status history
+type StatusChange struct {
+	ID        int64     `json:"id"`
+	From      string    `json:"from,omitempty"` // Empty for the creation of the order
+	To        string    `json:"to"`
+	Note      string    `json:"note,omitempty"`
+	AdminID   *int64    `json:"admin_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOrderInput represents input for placing an order
+type CreateOrderInput struct {
+	Customer Customer          `json:"customer"`
+	Items    []CreateItemInput `json:"items"`
+	Coupon   string            `json:"coupon,omitempty"`
+	Notes    string            `json:"notes,omitempty"`
+
+	Source     string `json:"-"` // Defaults to SourceAdmin
+	AdminID    int64  `json:"-"` // Taken from the authenticated admin when recorded by the shop
+	CustomerID int64  `json:"-"` // Taken from the signed-in customer placing the order
+}
+
+// CreateItemInput is a product, size and color to order
+type CreateItemInput struct {
+	ProductID int64  `json:"product_id"`
+	Size      string `json:"size,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Quantity  int    `json:"quantity"`
+
+	Design *ItemDesign `json:"-"` // Set when ordering a custom design
+}
+
+// TransitionInput represents input for changing the status of an order
+type TransitionInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note,omitempty"`
+
+	AdminID int64 `json:"-"` // Taken from the authenticated admin
+
+	// allowShortage confirms the order even when stock ran out after its
+	// hold expired, as for payments already taken; the history notes the
+	// items left short
+	allowShortage bool
+}
+
+// ListFilters represents filters for listing orders
+type ListFilters struct {
+	Page       int
+	Limit      int
+	Statuses   []string
+	Search     string     // Matches the order ID and reference, customer name, phone and email, and product names
+	From       *time.Time // Placed at or after
+	To         *time.Time // Placed before
+	CustomerID int64      // Placed by the customer account
+}
+
+// Validate validates order input, trimming the customer data
+func (input *CreateOrderInput) Validate() error {
+	c := &input.Customer
+	for _, field := range []*string{&c.Name, &c.Phone, &c.Email, &c.Address, &c.City, &c.Province, &c.PostalCode} {
+		*field = strings.TrimSpace(*field)
+	}
+	if c.Name == "" {
+		return ErrInvalidInput("customer name is required")
+	}
+	if input.Source == "" {
+		input.Source = SourceAdmin
+	}
+	// WhatsApp orders are followed up in the chat they open
+	if c.Phone == "" && c.Email == "" && input.Source != SourceWhatsApp {
+		return ErrInvalidInput("customer phone or email is required")
+	}
+	if c.Email != "" && !strings.Contains(c.Email, "@") {
+		return ErrInvalidInput("customer email is invalid")
+	}
+
+	if len(input.Items) == 0 {
+		return ErrInvalidInput("items are required")
+	}
+	for _, item := range input.Items {
+		if item.ProductID <= 0 {
+			return ErrInvalidInput("product_id is required")
+		}
+		if item.Quantity <= 0 || item.Quantity > promotion.MaxQuantity {
+			return ErrInvalidInput(fmt.Sprintf("quantity must be between 1 and %d", promotion.MaxQuantity))
+		}
+	}
+
+	input.Coupon = promotion.NormalizeCode(input.Coupon)
+	input.Notes = strings.TrimSpace(input.Notes)
+	return nil
+}
+
+// Validate validates transition input
+func (input *TransitionInput) Validate() error {
+	switch input.Status {
+	case StatusConfirmed, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled:
+	default:
+		return ErrInvalidInput("status must be confirmed, paid, shipped, delivered or cancelled")
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	return nil
+}
+
+// CanTransition reports whether an order in status from can move to status to
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceAlphabet leaves out characters easily confused when read aloud or typed: 0/O, 1/I
+const referenceAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// newReference generates a random order reference
+func newReference() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate order reference: %w", err)
+	}
+	for i := range b {
+		b[i] = referenceAlphabet[int(b[i])%len(referenceAlphabet)]
+	}
+	return string(b), nil
+}
+
+// scanOrder scans a database row into an Order
+func scanOrder(row interface{ Scan(...interface{}) error }) (*Order, error) {
+	var o Order
+	var adminID, customerID sql.NullInt64
+	var confirmedAt, paidAt, shippedAt, deliveredAt, cancelledAt sql.NullTime
+
+	err := row.Scan(
+		&o.ID,
+		&o.Reference,
+		&o.Status,
+		&o.PaymentStatus,
+		&o.Source,
+		&o.Customer.Name,
+		&o.Customer.Phone,
+		&o.Customer.Email,
+		&o.Customer.Address,
+		&o.Customer.City,
+		&o.Customer.Province,
+		&o.Customer.PostalCode,
+		&o.Notes,
+		&o.Coupon,
+		&o.ItemCount,
+		&o.Subtotal,
+		&o.Discount,
+		&o.Total,
+		&adminID,
+		&customerID,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+		&confirmedAt,
+		&paidAt,
+		&shippedAt,
+		&deliveredAt,
+		&cancelledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if adminID.Valid {
+		o.AdminID = &adminID.Int64
+	}
+	if customerID.Valid {
+		o.CustomerID = &customerID.Int64
+	}
+	for _, t := range []struct {
+		value sql.NullTime
+		field **time.Time
+	}{
+		{confirmedAt, &o.ConfirmedAt},
+		{paidAt, &o.PaidAt},
+		{shippedAt, &o.ShippedAt},
+		{deliveredAt, &o.DeliveredAt},
+		{cancelledAt, &o.CancelledAt},
+	} {
+		if t.value.Valid {
+			at := t.value.Time
+			*t.field = &at
+		}
+	}
+
+	return &o, nil
+}
+
+// scanItem scans a database row into an Item
+func scanItem(row interface{ Scan(...interface{}) error }) (*Item, error) {
+	var item Item
+	var variantID, promotionID, designID sql.NullInt64
+	var design ItemDesign
+
+	err := row.Scan(
+		&item.ID,
+		&item.ProductID,
+		&variantID,
+		&item.Name,
+		&item.SKU,
+		&item.Size,
+		&item.Color,
+		&item.Quantity,
+		&item.UnitPrice,
+		&item.Subtotal,
+		&item.Discount,
+		&item.Total,
+		&promotionID,
+		&item.PromotionName,
+		&designID,
+		&design.Token,
+		&design.ImageURL,
+		&design.Surcharge,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantID.Valid {
+		item.VariantID = &variantID.Int64
+	}
+	if promotionID.Valid {
+		item.PromotionID = &promotionID.Int64
+	}
+	if designID.Valid {
+		design.ID = designID.Int64
+		item.Design = &design
+	}
+
+	return &item, nil
+}
+
+// scanStatusChange scans a database row into a StatusChange
+func scanStatusChange(row interface{ Scan(...interface{}) error }) (*StatusChange, error) {
+	var c StatusChange
+	var adminID sql.NullInt64
+
+	if err := row.Scan(&c.ID, &c.From, &c.To, &c.Note, &adminID, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if adminID.Valid {
+		c.AdminID = &adminID.Int64
+	}
+
+	return &c, nil
+}
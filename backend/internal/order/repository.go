@@ -0,0 +1,39 @@
+package order
+
+import (
+	"context"
+	"time"
+)
+
+// Repository defines the interface for order data access
+type Repository interface {
+	// GetAll retrieves orders without items or history, newest first, and the total count
+	GetAll(ctx context.Context, filters ListFilters) ([]*Order, int, error)
+
+	// GetByID retrieves an order with its items and status history
+	GetByID(ctx context.Context, id int64) (*Order, error)
+
+	// GetByReference retrieves an order by its reference with its items and status history
+	GetByReference(ctx context.Context, reference string) (*Order, error)
+
+	// Create stores a pending order with its items, holds their stock until holdUntil, unless
+	// it is zero, and redeems their promotions. It fails with errDuplicateReference when the order's
+	// reference is taken, with ErrOutOfStock when an item is short and with
+	// promotion.ErrUsageLimit when a promotion ran out.
+	Create(ctx context.Context, o *Order, holdUntil time.Time) (*Order, error)
+
+	// Transition moves an order from one status to another, recording when and by whom.
+	// Confirming or paying a pending order takes its stock and cancelling puts it back,
+	// along with the uses of its promotions.
+	// It fails with ErrTransition when the order is no longer in status from.
+	Transition(ctx context.Context, id int64, from string, input TransitionInput, at time.Time) error
+
+	// ReleaseExpired releases the stock held by pending orders past their hold
+	ReleaseExpired(ctx context.Context, now time.Time) (int, error)
+
+	// SetPaymentStatus records the status of the latest online payment of an order
+	SetPaymentStatus(ctx context.Context, id int64, status string) error
+
+	// ClaimGuestOrders links the orders placed without an account using an email to the customer
+	ClaimGuestOrders(ctx context.Context, customerID int64, email string) (int, error)
+}
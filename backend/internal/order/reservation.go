@@ -0,0 +1,55 @@
+package order
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Reservation statuses
+const (
+	ReservationHeld      = "held"      // Holding stock for a pending order until it expires
+	ReservationReleased  = "released"  // Expired or cancelled before the order was confirmed
+	ReservationCommitted = "committed" // Taken from stock when the order was confirmed or paid
+	ReservationReturned  = "returned"  // Put back in stock when the confirmed order was cancelled
+)
+
+// Reservation is the stock an order item holds or took. Pending orders hold
+// stock until ExpiresAt; confirming or paying the order takes it for good.
+type Reservation struct {
+	ID        int64     `json:"id"`
+	ItemID    int64     `json:"item_id"`
+	ProductID int64     `json:"product_id"`
+	VariantID *int64    `json:"variant_id,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// scanReservation scans a database row into a Reservation
+func scanReservation(row interface{ Scan(...interface{}) error }) (*Reservation, error) {
+	var res Reservation
+	var variantID sql.NullInt64
+
+	err := row.Scan(
+		&res.ID,
+		&res.ItemID,
+		&res.ProductID,
+		&variantID,
+		&res.Quantity,
+		&res.Status,
+		&res.ExpiresAt,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantID.Valid {
+		res.VariantID = &variantID.Int64
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,104 @@
+package order_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// expiredHold makes every hold expire as soon as the order is placed
+const expiredHold = -time.Minute
+
+type expirySuite struct {
+	suite.Suite
+	ctx       context.Context
+	svc       *order.Service
+	shopperID int64
+	p         *product.Product
+}
+
+func TestExpirySuite(t *testing.T) {
+	suite.Run(t, new(expirySuite))
+}
+
+func (s *expirySuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	products := product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), products)
+	s.svc = order.NewService(order.NewSQLiteRepository(db), products, promotions, expiredHold)
+
+	shopper, err := customer.NewSQLiteRepository(db).Create(s.ctx, &customer.Customer{Email: "cliente@example.com", Name: "Cliente"})
+	s.Require().NoError(err)
+	s.shopperID = shopper.ID
+
+	s.p, err = products.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     "Remera Lisa",
+		Price:    orderPrice,
+		Sizes:    []string{orderSize},
+		Variants: []product.CreateVariantInput{{Size: orderSize, Stock: orderStock}},
+	})
+	s.Require().NoError(err)
+}
+
+// place orders quantity units of the test product as a signed-in shopper
+func (s *expirySuite) place(quantity int) *order.Order {
+	o, err := s.svc.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer:   order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:      []order.CreateItemInput{{ProductID: s.p.ID, Size: orderSize, Quantity: quantity}},
+		CustomerID: s.shopperID,
+	})
+	s.Require().NoError(err)
+	return o
+}
+
+func (s *expirySuite) TestExpiredHoldsAreReleased() {
+	o := s.place(orderStock)
+
+	released, err := s.svc.ReleaseExpiredReservations(s.ctx)
+	s.Require().NoError(err)
+	again, againErr := s.svc.ReleaseExpiredReservations(s.ctx)
+	saved, getErr := s.svc.GetOrder(s.ctx, o.ID)
+
+	s.Equal(1, released)
+	s.Require().NoError(againErr)
+	s.Zero(again)
+	s.Require().NoError(getErr)
+	s.Equal(order.StatusPending, saved.Status)
+	s.Equal(order.ReservationReleased, saved.Reservations[0].Status)
+}
+
+func (s *expirySuite) TestConfirmAfterExpiryTakesStockIfLeft() {
+	first := s.place(orderStock)
+	second := s.place(orderStock) // The first hold expired, so the stock looks free
+
+	confirmed, confirmErr := s.svc.TransitionOrder(s.ctx, first.ID, order.TransitionInput{Status: order.StatusConfirmed})
+	_, shortErr := s.svc.TransitionOrder(s.ctx, second.ID, order.TransitionInput{Status: order.StatusConfirmed})
+	paid, payErr := s.svc.RecordPayment(s.ctx, second.ID, "approved", true, "payment approved")
+
+	s.Require().NoError(confirmErr)
+	s.Equal(order.StatusConfirmed, confirmed.Status)
+	s.Contains(reservationStatuses(confirmed), order.ReservationCommitted)
+	s.ErrorIs(shortErr, order.ErrOutOfStock)
+	s.Require().NoError(payErr)
+	s.Equal(order.StatusPaid, paid.Status)
+	s.Contains(paid.History[len(paid.History)-1].Note, "not enough stock for Remera Lisa (M)")
+	s.NotContains(reservationStatuses(paid), order.ReservationCommitted)
+}
+
+// reservationStatuses lists the statuses of the reservations of an order
+func reservationStatuses(o *order.Order) []string {
+	statuses := make([]string, 0, len(o.Reservations))
+	for _, res := range o.Reservations {
+		statuses = append(statuses, res.Status)
+	}
+	return statuses
+}
@@ -0,0 +1,219 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Service provides business logic for orders
+type Service struct {
+	repo       Repository
+	products   *product.Service
+	promotions *promotion.Service
+	holdFor    time.Duration
+}
+
+// NewService creates a new order service. Pending orders hold their stock
+// for holdFor.
+func NewService(repo Repository, productService *product.Service, promotionService *promotion.Service, holdFor time.Duration) *Service {
+	return &Service{repo: repo, products: productService, promotions: promotionService, holdFor: holdFor}
+}
+
+// GetOrders retrieves orders matching the filters and the total count
+func (s *Service) GetOrders(ctx context.Context, filters ListFilters) ([]*Order, int, error) {
+	for _, status := range filters.Statuses {
+		if status != StatusPending && statusColumns[status] == "" {
+			return nil, 0, ErrInvalidInput(fmt.Sprintf("unknown status %q", status))
+		}
+	}
+
+	return s.repo.GetAll(ctx, filters)
+}
+
+// GetOrder retrieves an order with its items and status history
+func (s *Service) GetOrder(ctx context.Context, id int64) (*Order, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetOrderByReference retrieves an order by its reference, ignoring case
+func (s *Service) GetOrderByReference(ctx context.Context, reference string) (*Order, error) {
+	return s.repo.GetByReference(ctx, strings.ToUpper(strings.TrimSpace(reference)))
+}
+
+// ClaimGuestOrders links the orders placed without an account using email to
+// the customer, once the customer proved they own the address
+func (s *Service) ClaimGuestOrders(ctx context.Context, customerID int64, email string) (int, error) {
+	return s.repo.ClaimGuestOrders(ctx, customerID, strings.TrimSpace(email))
+}
+
+// CreateOrder places a pending order and holds its stock. Items are priced
+// at the current catalog prices with the current promotions, plus the
+// surcharge of custom designs, and the promotions used are redeemed so usage
+// limits hold.
+func (s *Service) CreateOrder(ctx context.Context, input CreateOrderInput) (*Order, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(input.Items))
+	quoteItems := make([]promotion.QuoteItemInput, 0, len(input.Items))
+	for _, in := range input.Items {
+		p, err := s.products.GetProduct(ctx, in.ProductID)
+		if errors.Is(err, product.ErrNotFound) {
+			return nil, ErrInvalidInput(fmt.Sprintf("product %d not found", in.ProductID))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sel, err := p.Select(in.Size, in.Color)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &Item{ProductID: p.ID, Name: p.Name, Size: sel.Size, Color: sel.Color, Quantity: in.Quantity, Design: in.Design}
+		if sel.Variant != nil {
+			item.VariantID = &sel.Variant.ID
+			item.SKU = sel.Variant.SKU
+		}
+		items = append(items, item)
+		quoteItems = append(quoteItems, promotion.QuoteItemInput{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	q, err := s.promotions.Quote(ctx, promotion.QuoteInput{Items: quoteItems, Coupon: input.Coupon})
+	if err != nil {
+		return nil, err
+	}
+	if q.Coupon != nil && !q.Coupon.Valid {
+		return nil, ErrInvalidInput(q.Coupon.Error)
+	}
+
+	o := &Order{
+		Source:   input.Source,
+		Customer: input.Customer,
+		Notes:    input.Notes,
+		Coupon:   input.Coupon,
+		Items:    items,
+		Subtotal: q.Subtotal,
+		Discount: q.Discount,
+		Total:    q.Total,
+	}
+	if input.AdminID > 0 {
+		o.AdminID = &input.AdminID
+	}
+	if input.CustomerID > 0 {
+		o.CustomerID = &input.CustomerID
+	}
+	for i, line := range q.Lines {
+		item := items[i]
+		item.UnitPrice = line.UnitPrice
+		item.Subtotal = line.Subtotal
+		item.Discount = line.Discount
+		item.Total = line.Total
+		if line.Promotion != nil {
+			item.PromotionID = &line.Promotion.ID
+			item.PromotionName = line.Promotion.Name
+		}
+		if item.Design != nil {
+			surcharge := item.Design.Surcharge * item.Quantity
+			item.UnitPrice += item.Design.Surcharge
+			item.Subtotal += surcharge
+			item.Total += surcharge
+			o.Subtotal += surcharge
+			o.Total += surcharge
+		}
+		o.ItemCount += item.Quantity
+	}
+
+	// Anyone can place an anonymous order, so holding their stock would let
+	// enough of them empty the catalog. Their stock is taken when they are
+	// confirmed or paid instead.
+	var holdUntil time.Time
+	if o.AdminID != nil || o.CustomerID != nil {
+		holdUntil = time.Now().Add(s.holdFor)
+	}
+
+	// References are random, so retry the rare collision with a new one
+	var created *Order
+	for attempt := 0; ; attempt++ {
+		if o.Reference, err = newReference(); err != nil {
+			return nil, err
+		}
+		created, err = s.repo.Create(ctx, o, holdUntil)
+		if errors.Is(err, errDuplicateReference) && attempt < 5 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	return created, nil
+}
+
+// TransitionOrder moves an order to a new status when the workflow allows it
+func (s *Service) TransitionOrder(ctx context.Context, id int64, input TransitionInput) (*Order, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	o, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanTransition(o.Status, input.Status) {
+		return nil, fmt.Errorf("%w: an order cannot go from %s to %s", ErrTransition, o.Status, input.Status)
+	}
+
+	if err := s.repo.Transition(ctx, id, o.Status, input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// RecordPayment stores the status of an online payment of an order and, when
+// paid is set, moves the order to paid unless it already got there. It can
+// run any number of times for the same payment.
+func (s *Service) RecordPayment(ctx context.Context, id int64, paymentStatus string, paid bool, note string) (*Order, error) {
+	if err := s.repo.SetPaymentStatus(ctx, id, paymentStatus); err != nil {
+		return nil, err
+	}
+
+	o, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if paid && CanTransition(o.Status, StatusPaid) {
+		// The money is in, so the order is paid even if its hold expired and stock ran out
+		input := TransitionInput{Status: StatusPaid, Note: note, allowShortage: true}
+		err := s.repo.Transition(ctx, id, o.Status, input, time.Now())
+		// A concurrent notification for the same payment may have moved it first
+		if err != nil && !errors.Is(err, ErrTransition) {
+			return nil, err
+		}
+		return s.repo.GetByID(ctx, id)
+	}
+
+	return o, nil
+}
+
+// ReleaseExpiredReservations releases the stock held by pending orders past
+// their hold and returns how many holds were released. The orders stay
+// pending and take stock again if confirmed while it lasts.
+func (s *Service) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	return s.repo.ReleaseExpired(ctx, time.Now())
+}
@@ -0,0 +1,408 @@
+package order_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	orderPrice  = 550000
+	orderStock  = 3
+	orderSize   = "M"
+	couponCode  = "UNICO"
+	couponValue = 50000
+	holdFor     = 15 * time.Minute
+	concurrency = 8
+)
+
+type orderSuite struct {
+	suite.Suite
+	ctx        context.Context
+	svc        *order.Service
+	promotions *promotion.Service
+	customers  *customer.SQLiteRepository
+	shopperID  int64 // Signed-in customer placing the test orders
+	p          *product.Product
+}
+
+func TestOrderSuite(t *testing.T) {
+	suite.Run(t, new(orderSuite))
+}
+
+func (s *orderSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	products := product.NewService(product.NewSQLiteRepository(db))
+	s.promotions = promotion.NewService(promotion.NewSQLiteRepository(db), products)
+	s.svc = order.NewService(order.NewSQLiteRepository(db), products, s.promotions, holdFor)
+	s.customers = customer.NewSQLiteRepository(db)
+
+	shopper, err := s.customers.Create(s.ctx, &customer.Customer{Email: "cliente@example.com", Name: "Cliente"})
+	s.Require().NoError(err)
+	s.shopperID = shopper.ID
+
+	s.p, err = products.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     "Remera Lisa",
+		Price:    orderPrice,
+		Sizes:    []string{orderSize},
+		Variants: []product.CreateVariantInput{{Size: orderSize, Stock: orderStock}},
+	})
+	s.Require().NoError(err)
+}
+
+// place orders quantity units of the test product with a coupon as the
+// signed-in shopper
+func (s *orderSuite) place(quantity int, coupon string) (*order.Order, error) {
+	return s.svc.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer:   order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:      []order.CreateItemInput{{ProductID: s.p.ID, Size: orderSize, Quantity: quantity}},
+		Coupon:     coupon,
+		CustomerID: s.shopperID,
+	})
+}
+
+// transition moves an order to a status
+func (s *orderSuite) transition(id int64, status string) (*order.Order, error) {
+	return s.svc.TransitionOrder(s.ctx, id, order.TransitionInput{Status: status})
+}
+
+// createCoupon creates a fixed amount coupon and returns its ID
+func (s *orderSuite) createCoupon(limit *int) int64 {
+	p, err := s.promotions.CreatePromotion(s.ctx, promotion.PromotionInput{
+		Name:       "Cupón único",
+		Code:       couponCode,
+		Type:       promotion.TypeFixed,
+		Value:      couponValue,
+		UsageLimit: limit,
+	})
+	s.Require().NoError(err)
+	return p.ID
+}
+
+// usage returns how many times a promotion was redeemed
+func (s *orderSuite) usage(id int64) int {
+	p, err := s.promotions.GetPromotion(s.ctx, id)
+	s.Require().NoError(err)
+	return p.UsageCount
+}
+
+var canTransitionCases = []struct {
+	name string
+	from string
+	to   string
+	want bool
+}{
+	{name: "Pending to confirmed", from: order.StatusPending, to: order.StatusConfirmed, want: true},
+	{name: "Pending to paid", from: order.StatusPending, to: order.StatusPaid, want: true},
+	{name: "Confirmed to paid", from: order.StatusConfirmed, to: order.StatusPaid, want: true},
+	{name: "Paid to shipped", from: order.StatusPaid, to: order.StatusShipped, want: true},
+	{name: "Paid to cancelled", from: order.StatusPaid, to: order.StatusCancelled, want: true},
+	{name: "Shipped to delivered", from: order.StatusShipped, to: order.StatusDelivered, want: true},
+	{name: "Pending to shipped", from: order.StatusPending, to: order.StatusShipped},
+	{name: "Confirmed back to pending", from: order.StatusConfirmed, to: order.StatusPending},
+	{name: "Shipped to cancelled", from: order.StatusShipped, to: order.StatusCancelled},
+	{name: "Out of cancelled", from: order.StatusCancelled, to: order.StatusConfirmed},
+	{name: "Out of delivered", from: order.StatusDelivered, to: order.StatusCancelled},
+}
+
+func (s *orderSuite) TestCanTransition() {
+	for _, tc := range canTransitionCases {
+		s.Equal(tc.want, order.CanTransition(tc.from, tc.to), tc.name)
+	}
+}
+
+var reservationCases = []struct {
+	name       string
+	statuses   []string
+	wantStatus string
+	wantFree   bool // Whether the stock is free again for a new order
+}{
+	{name: "Pending holds", wantStatus: order.ReservationHeld},
+	{name: "Cancelled while pending releases", statuses: []string{order.StatusCancelled}, wantStatus: order.ReservationReleased, wantFree: true},
+	{name: "Confirmed takes", statuses: []string{order.StatusConfirmed}, wantStatus: order.ReservationCommitted},
+	{name: "Paid takes", statuses: []string{order.StatusPaid}, wantStatus: order.ReservationCommitted},
+	{name: "Cancelled after confirmed returns", statuses: []string{order.StatusConfirmed, order.StatusCancelled}, wantStatus: order.ReservationReturned, wantFree: true},
+}
+
+func (s *orderSuite) TestReservations() {
+	for _, tc := range reservationCases {
+		s.SetupTest()
+		o, err := s.place(orderStock, "")
+		s.Require().NoError(err, tc.name)
+
+		for _, status := range tc.statuses {
+			o, err = s.transition(o.ID, status)
+			s.Require().NoError(err, tc.name)
+		}
+		_, againErr := s.place(1, "")
+
+		s.Require().Len(o.Reservations, 1, tc.name)
+		s.Equal(tc.wantStatus, o.Reservations[0].Status, tc.name)
+		// Held stock is short; taken stock leaves the variant unavailable
+		if tc.wantFree {
+			s.NoError(againErr, tc.name)
+		} else {
+			s.Error(againErr, tc.name)
+		}
+	}
+}
+
+func (s *orderSuite) TestAnonymousOrdersHoldNothing() {
+	input := order.CreateOrderInput{
+		Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:    []order.CreateItemInput{{ProductID: s.p.ID, Size: orderSize, Quantity: orderStock}},
+	}
+	first, err := s.svc.CreateOrder(s.ctx, input)
+	s.Require().NoError(err)
+	second, err := s.svc.CreateOrder(s.ctx, input)
+	s.Require().NoError(err)
+	_, tooManyErr := s.svc.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer: input.Customer,
+		Items:    []order.CreateItemInput{{ProductID: s.p.ID, Size: orderSize, Quantity: orderStock + 1}},
+	})
+	held, heldErr := s.place(orderStock, "")
+
+	s.Empty(first.Reservations)
+	s.Empty(second.Reservations)
+	s.ErrorIs(tooManyErr, order.ErrOutOfStock)
+	s.Require().NoError(heldErr)
+	s.Equal(order.ReservationHeld, held.Reservations[0].Status)
+
+	// The signed-in shopper's hold comes first; the anonymous orders find nothing left
+	_, confirmErr := s.transition(first.ID, order.StatusConfirmed)
+	s.ErrorIs(confirmErr, order.ErrOutOfStock)
+	_, err = s.transition(held.ID, order.StatusCancelled)
+	s.Require().NoError(err)
+	confirmed, confirmErr := s.transition(first.ID, order.StatusConfirmed)
+	s.Require().NoError(confirmErr)
+	s.Equal(order.ReservationCommitted, confirmed.Reservations[0].Status)
+	_, secondErr := s.transition(second.ID, order.StatusConfirmed)
+	s.ErrorIs(secondErr, order.ErrOutOfStock)
+}
+
+func (s *orderSuite) TestInvalidTransition() {
+	o, err := s.place(1, "")
+	s.Require().NoError(err)
+
+	_, shipErr := s.transition(o.ID, order.StatusShipped)
+	_, err = s.transition(o.ID, order.StatusCancelled)
+	s.Require().NoError(err)
+	_, againErr := s.transition(o.ID, order.StatusCancelled)
+
+	s.ErrorIs(shipErr, order.ErrTransition)
+	s.ErrorIs(againErr, order.ErrTransition)
+}
+
+func (s *orderSuite) TestCouponUsageFollowsOrder() {
+	id := s.createCoupon(nil)
+
+	o, err := s.place(1, couponCode)
+	s.Require().NoError(err)
+	redeemed := s.usage(id)
+	_, err = s.transition(o.ID, order.StatusCancelled)
+	s.Require().NoError(err)
+
+	s.Equal(couponValue, o.Discount)
+	s.Equal(1, redeemed)
+	s.Zero(s.usage(id))
+}
+
+func (s *orderSuite) TestCouponUsageLimit() {
+	limit := 1
+	id := s.createCoupon(&limit)
+
+	first, firstErr := s.place(1, couponCode)
+	_, secondErr := s.place(1, couponCode)
+	s.Require().NoError(firstErr)
+	_, err := s.transition(first.ID, order.StatusCancelled)
+	s.Require().NoError(err)
+	_, thirdErr := s.place(1, couponCode)
+
+	s.ErrorIs(secondErr, order.ErrValidation)
+	s.NoError(thirdErr)
+	s.Equal(1, s.usage(id))
+}
+
+func (s *orderSuite) TestOrderShortOfStockKeepsCoupon() {
+	limit := 1
+	id := s.createCoupon(&limit)
+
+	_, shortErr := s.place(orderStock+1, couponCode)
+	_, err := s.place(1, couponCode)
+
+	s.ErrorIs(shortErr, order.ErrOutOfStock)
+	s.NoError(err)
+	s.Equal(1, s.usage(id))
+}
+
+func (s *orderSuite) TestConcurrentOrdersDoNotOversell() {
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.place(1, "")
+		}(i)
+	}
+	wg.Wait()
+
+	placed := 0
+	for _, err := range errs {
+		if err == nil {
+			placed++
+			continue
+		}
+		s.ErrorIs(err, order.ErrOutOfStock)
+	}
+	s.Equal(orderStock, placed)
+}
+
+func (s *orderSuite) TestConcurrentCouponRedemptionsHonourLimit() {
+	limit := 2
+	id := s.createCoupon(&limit)
+
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.place(1, couponCode)
+		}(i)
+	}
+	wg.Wait()
+
+	placed := 0
+	for _, err := range errs {
+		if err == nil {
+			placed++
+		}
+	}
+	s.Equal(limit, placed)
+	s.Equal(limit, s.usage(id))
+}
+
+func (s *orderSuite) TestConcurrentPaymentNotifications() {
+	o, err := s.place(1, "")
+	s.Require().NoError(err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.svc.RecordPayment(s.ctx, o.ID, "approved", true, "payment approved")
+		}(i)
+	}
+	wg.Wait()
+	paid, getErr := s.svc.GetOrder(s.ctx, o.ID)
+
+	for _, err := range errs {
+		s.NoError(err)
+	}
+	s.Require().NoError(getErr)
+	s.Equal(order.StatusPaid, paid.Status)
+	s.Equal("approved", paid.PaymentStatus)
+	s.Len(paid.History, 2)
+	s.Equal(order.ReservationCommitted, paid.Reservations[0].Status)
+}
+
+var listCases = []struct {
+	name       string
+	filters    order.ListFilters
+	byCustomer bool  // Filter by the account of the first order
+	want       []int // Orders by position in placing order, newest first
+}{
+	{name: "All", want: []int{2, 1, 0}},
+	{name: "By status", filters: order.ListFilters{Statuses: []string{order.StatusCancelled}}, want: []int{1}},
+	{name: "By customer name", filters: order.ListFilters{Search: "bea"}, want: []int{2}},
+	{name: "By product name", filters: order.ListFilters{Search: "remera"}, want: []int{2, 1, 0}},
+	{name: "By customer account", byCustomer: true, want: []int{0}},
+	{name: "Before the first", filters: order.ListFilters{To: &time.Time{}}, want: []int{}},
+}
+
+func (s *orderSuite) TestGetOrders() {
+	account, err := s.customers.Create(s.ctx, &customer.Customer{Email: "ana@example.com", Name: "Ana"})
+	s.Require().NoError(err)
+
+	var placed []*order.Order
+	for _, input := range []order.CreateOrderInput{
+		{Customer: order.Customer{Name: "Ana", Email: "ana@example.com"}, CustomerID: account.ID},
+		{Customer: order.Customer{Name: "Luis", Email: "luis@example.com"}},
+		{Customer: order.Customer{Name: "Beatriz", Phone: "+5491100000000"}},
+	} {
+		input.Items = []order.CreateItemInput{{ProductID: s.p.ID, Size: orderSize, Quantity: 1}}
+		o, err := s.svc.CreateOrder(s.ctx, input)
+		s.Require().NoError(err)
+		placed = append(placed, o)
+	}
+	_, err = s.transition(placed[1].ID, order.StatusCancelled)
+	s.Require().NoError(err)
+
+	for _, tc := range listCases {
+		tc.filters.Page, tc.filters.Limit = 1, 10
+		if tc.byCustomer {
+			tc.filters.CustomerID = account.ID
+		}
+		orders, total, err := s.svc.GetOrders(s.ctx, tc.filters)
+		s.Require().NoError(err, tc.name)
+
+		ids := make([]int64, 0, len(orders))
+		for _, o := range orders {
+			ids = append(ids, o.ID)
+		}
+		want := make([]int64, 0, len(tc.want))
+		for _, i := range tc.want {
+			want = append(want, placed[i].ID)
+		}
+		s.Equal(want, ids, tc.name)
+		s.Equal(len(want), total, tc.name)
+	}
+
+	byReference, referenceErr := s.svc.GetOrderByReference(s.ctx, " "+strings.ToLower(placed[0].Reference)+" ")
+	_, _, unknownStatusErr := s.svc.GetOrders(s.ctx, order.ListFilters{Statuses: []string{"lost"}})
+	_, missingErr := s.svc.GetOrderByReference(s.ctx, "NOPE00")
+
+	s.Require().NoError(referenceErr)
+	s.Equal(placed[0].ID, byReference.ID)
+	s.ErrorIs(unknownStatusErr, order.ErrValidation)
+	s.ErrorIs(missingErr, order.ErrNotFound)
+}
+
+var createValidationCases = []struct {
+	name  string
+	input order.CreateOrderInput
+}{
+	{name: "Missing name", input: order.CreateOrderInput{Customer: order.Customer{Phone: "+5491100000000"}, Items: []order.CreateItemInput{{ProductID: 1, Quantity: 1}}}},
+	{name: "No way to contact", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana"}, Items: []order.CreateItemInput{{ProductID: 1, Quantity: 1}}}},
+	{name: "Invalid email", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Email: "ana"}, Items: []order.CreateItemInput{{ProductID: 1, Quantity: 1}}}},
+	{name: "No items", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"}}},
+	{name: "Missing product", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"}, Items: []order.CreateItemInput{{Quantity: 1}}}},
+	{name: "Zero quantity", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"}, Items: []order.CreateItemInput{{ProductID: 1}}}},
+	{name: "Unknown product", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"}, Items: []order.CreateItemInput{{ProductID: 9999, Quantity: 1}}}},
+	{name: "Unknown coupon", input: order.CreateOrderInput{Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"}, Items: []order.CreateItemInput{{ProductID: 1, Size: orderSize, Quantity: 1}}, Coupon: "NADA"}},
+}
+
+func (s *orderSuite) TestCreateValidation() {
+	for _, tc := range createValidationCases {
+		_, err := s.svc.CreateOrder(s.ctx, tc.input)
+
+		s.ErrorIs(err, order.ErrValidation, tc.name)
+	}
+
+	_, statusErr := s.svc.TransitionOrder(s.ctx, 1, order.TransitionInput{Status: order.StatusPending})
+	s.ErrorIs(statusErr, order.ErrValidation)
+}
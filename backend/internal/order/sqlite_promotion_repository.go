@@ -0,0 +1,82 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// redeemPromotions counts one use of every promotion applied to the items of
+// an order. It fails with promotion.ErrUsageLimit when one ran out since the
+// order was quoted.
+func redeemPromotions(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	ids, err := orderPromotionIDs(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE promotions SET usage_count = usage_count + 1
+			WHERE id = ? AND (usage_limit IS NULL OR usage_count < usage_limit)
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to redeem promotion: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("%w: promotion %d", promotion.ErrUsageLimit, id)
+		}
+	}
+
+	return nil
+}
+
+// returnPromotions gives back the use of every promotion a cancelled order redeemed
+func returnPromotions(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET usage_count = usage_count - 1
+		WHERE usage_count > 0 AND id IN (
+			SELECT promotion_id FROM order_items WHERE order_id = ? AND promotion_id IS NOT NULL
+		)
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to return promotion usage: %w", err)
+	}
+
+	return nil
+}
+
+// orderPromotionIDs returns the distinct promotions applied to the items of an order
+func orderPromotionIDs(ctx context.Context, tx *sql.Tx, orderID int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT promotion_id FROM order_items
+		WHERE order_id = ? AND promotion_id IS NOT NULL
+		ORDER BY promotion_id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order promotions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan order promotion: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,413 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const orderColumns = `id, reference, status, payment_status, source, customer_name, customer_phone, customer_email, address, city, province, postal_code,
+	notes, coupon, item_count, subtotal, discount, total, admin_id, customer_id, created_at, updated_at,
+	confirmed_at, paid_at, shipped_at, delivered_at, cancelled_at`
+
+// statusColumns maps each status reached through a transition to the column recording when
+var statusColumns = map[string]string{
+	StatusConfirmed: "confirmed_at",
+	StatusPaid:      "paid_at",
+	StatusShipped:   "shipped_at",
+	StatusDelivered: "delivered_at",
+	StatusCancelled: "cancelled_at",
+}
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite order repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// GetAll retrieves orders without items or history, newest first, and the total count
+func (r *SQLiteRepository) GetAll(ctx context.Context, filters ListFilters) ([]*Order, int, error) {
+	var whereClauses []string
+	var args []interface{}
+
+	if len(filters.Statuses) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filters.Statuses)), ",")
+		whereClauses = append(whereClauses, fmt.Sprintf("status IN (%s)", placeholders))
+		for _, status := range filters.Statuses {
+			args = append(args, status)
+		}
+	}
+	if search := strings.TrimSpace(filters.Search); search != "" {
+		pattern := "%" + search + "%"
+		clause := `(reference = ? OR customer_name LIKE ? OR customer_phone LIKE ? OR customer_email LIKE ?
+			OR id IN (SELECT order_id FROM order_items WHERE name LIKE ?)`
+		args = append(args, strings.ToUpper(search), pattern, pattern, pattern, pattern)
+		if id, err := strconv.ParseInt(strings.TrimPrefix(search, "#"), 10, 64); err == nil {
+			clause += " OR id = ?"
+			args = append(args, id)
+		}
+		whereClauses = append(whereClauses, clause+")")
+	}
+	if filters.CustomerID > 0 {
+		whereClauses = append(whereClauses, "customer_id = ?")
+		args = append(args, filters.CustomerID)
+	}
+	if filters.From != nil {
+		whereClauses = append(whereClauses, "created_at >= ?")
+		args = append(args, *filters.From)
+	}
+	if filters.To != nil {
+		whereClauses = append(whereClauses, "created_at < ?")
+		args = append(args, *filters.To)
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	// Get total count
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM orders %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	// Pagination
+	limit := filters.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset := 0
+	if filters.Page > 1 {
+		offset = (filters.Page - 1) * limit
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM orders
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, orderColumns, whereClause)
+
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return orders, total, nil
+}
+
+// GetByID retrieves an order with its items and status history
+func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Order, error) {
+	return r.get(ctx, "id = ?", id)
+}
+
+// GetByReference retrieves an order by its reference with its items and status history
+func (r *SQLiteRepository) GetByReference(ctx context.Context, reference string) (*Order, error) {
+	return r.get(ctx, "reference = ?", reference)
+}
+
+// get retrieves the order matching a condition with its items and status history
+func (r *SQLiteRepository) get(ctx context.Context, condition string, arg interface{}) (*Order, error) {
+	row := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM orders WHERE %s", orderColumns, condition), arg)
+	o, err := scanOrder(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, name, sku, size, color, quantity, unit_price, subtotal, discount, total,
+			promotion_id, promotion_name, design_id, design_token, design_image_url, design_surcharge
+		FROM order_items
+		WHERE order_id = ?
+		ORDER BY id
+	`, o.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	o.Items = []*Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		o.Items = append(o.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	historyRows, err := r.db.QueryContext(ctx, `
+		SELECT id, from_status, to_status, note, admin_id, created_at
+		FROM order_status_history
+		WHERE order_id = ?
+		ORDER BY id
+	`, o.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order history: %w", err)
+	}
+	defer historyRows.Close()
+
+	o.History = []*StatusChange{}
+	for historyRows.Next() {
+		change, err := scanStatusChange(historyRows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order history: %w", err)
+		}
+		o.History = append(o.History, change)
+	}
+	if err := historyRows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if o.Reservations, err = r.getReservations(ctx, o.ID); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Create stores a pending order with its items and holds their stock until
+// holdUntil, or holds nothing when holdUntil is zero. It fails with
+// ErrOutOfStock when an item is short.
+func (r *SQLiteRepository) Create(ctx context.Context, o *Order, holdUntil time.Time) (*Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var adminID, customerID interface{}
+	if o.AdminID != nil {
+		adminID = *o.AdminID
+	}
+	if o.CustomerID != nil {
+		customerID = *o.CustomerID
+	}
+
+	now := time.Now()
+	c := o.Customer
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO orders (reference, status, source, customer_name, customer_phone, customer_email, address, city,
+			province, postal_code, notes, coupon, item_count, subtotal, discount, total, admin_id, customer_id, created_at,
+			updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, o.Reference, StatusPending, o.Source, c.Name, c.Phone, c.Email, c.Address, c.City, c.Province, c.PostalCode,
+		o.Notes, o.Coupon, o.ItemCount, o.Subtotal, o.Discount, o.Total, adminID, customerID, now, now,
+	)
+	if database.IsUniqueViolation(err) {
+		return nil, errDuplicateReference
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	lines := make([]stockLine, 0, len(o.Items))
+	for _, item := range o.Items {
+		var variantID, promotionID, designID interface{}
+		if item.VariantID != nil {
+			variantID = *item.VariantID
+		}
+		if item.PromotionID != nil {
+			promotionID = *item.PromotionID
+		}
+		design := ItemDesign{}
+		if item.Design != nil {
+			design = *item.Design
+			designID = design.ID
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO order_items (order_id, product_id, variant_id, name, sku, size, color, quantity, unit_price,
+				subtotal, discount, total, promotion_id, promotion_name, design_id, design_token, design_image_url,
+				design_surcharge)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, item.ProductID, variantID, item.Name, item.SKU, item.Size, item.Color, item.Quantity, item.UnitPrice,
+			item.Subtotal, item.Discount, item.Total, promotionID, item.PromotionName, designID, design.Token,
+			design.ImageURL, design.Surcharge,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order item: %w", err)
+		}
+
+		line := newStockLine(item)
+		if line.itemID, err = result.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if err := reserveStock(ctx, tx, id, lines, now, holdUntil); err != nil {
+		return nil, err
+	}
+
+	// Redeeming last keeps orders short of stock from using up promotions
+	if err := redeemPromotions(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := insertStatusChange(ctx, tx, id, "", StatusPending, "", adminID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit order: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Transition moves an order from one status to another, recording when and
+// by whom. Confirming a pending order takes its stock and cancelling puts it
+// back, along with the uses of its promotions. It fails with ErrTransition
+// when the order is no longer in status from.
+func (r *SQLiteRepository) Transition(ctx context.Context, id int64, from string, input TransitionInput, at time.Time) error {
+	column, ok := statusColumns[input.Status]
+	if !ok {
+		return fmt.Errorf("%w: unknown status %s", ErrTransition, input.Status)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		fmt.Sprintf("UPDATE orders SET status = ?, %s = ?, updated_at = ? WHERE id = ? AND status = ?", column),
+		input.Status, at, at, id, from,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: order is no longer %s", ErrTransition, from)
+	}
+
+	note := input.Note
+	switch {
+	case from == StatusPending && (input.Status == StatusConfirmed || input.Status == StatusPaid):
+		shortages, err := commitStock(ctx, tx, id, input.allowShortage, at)
+		if err != nil {
+			return err
+		}
+		if len(shortages) > 0 {
+			note = strings.TrimSpace(note + " (not enough stock for " + strings.Join(shortages, ", ") + ")")
+		}
+	case input.Status == StatusCancelled:
+		if err := releaseStock(ctx, tx, id, at); err != nil {
+			return err
+		}
+		if err := returnPromotions(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	var adminID interface{}
+	if input.AdminID > 0 {
+		adminID = input.AdminID
+	}
+	if err := insertStatusChange(ctx, tx, id, from, input.Status, note, adminID, at); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order status: %w", err)
+	}
+
+	return nil
+}
+
+// SetPaymentStatus records the status of the latest online payment of an order
+func (r *SQLiteRepository) SetPaymentStatus(ctx context.Context, id int64, status string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE orders SET payment_status = ?, updated_at = ? WHERE id = ?",
+		status, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update order payment status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimGuestOrders links the orders placed without an account using an email
+// to the customer with that email, ignoring case
+func (r *SQLiteRepository) ClaimGuestOrders(ctx context.Context, customerID int64, email string) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE orders SET customer_id = ? WHERE customer_id IS NULL AND customer_email = ? COLLATE NOCASE",
+		customerID, email,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim guest orders: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+// insertStatusChange records an entry of the status history of an order
+func insertStatusChange(ctx context.Context, tx *sql.Tx, orderID int64, from, to, note string, adminID interface{}, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, note, admin_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, orderID, from, to, note, adminID, at)
+	if err != nil {
+		return fmt.Errorf("failed to record order status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/inventory"
+)
+
+// Stock is tracked on variants, in product_variants.stock, and on products
+// with inventory movements, in the ledger. Items of products tracked in
+// neither place are never short and hold nothing.
+
+// stockLine is an order item as far as stock is concerned
+type stockLine struct {
+	itemID    int64
+	productID int64
+	variantID *int64
+	quantity  int
+	label     string // Names the item in messages, e.g. "Remera (M, Negro)"
+}
+
+// newStockLine builds the stock line of an order item
+func newStockLine(item *Item) stockLine {
+	label := item.Name
+	var options []string
+	for _, option := range []string{item.Size, item.Color} {
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	if len(options) > 0 {
+		label += " (" + strings.Join(options, ", ") + ")"
+	}
+
+	return stockLine{
+		itemID:    item.ID,
+		productID: item.ProductID,
+		variantID: item.VariantID,
+		quantity:  item.Quantity,
+		label:     label,
+	}
+}
+
+// ReleaseExpired releases the stock held by pending orders past their hold
+// and returns how many holds were released
+func (r *SQLiteRepository) ReleaseExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE stock_reservations SET status = ?, updated_at = ? WHERE status = ? AND expires_at <= ?",
+		ReservationReleased, now, ReservationHeld, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release expired reservations: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rows), nil
+}
+
+// getReservations retrieves the reservations of an order
+func (r *SQLiteRepository) getReservations(ctx context.Context, orderID int64) ([]*Reservation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, order_item_id, product_id, variant_id, quantity, status, expires_at, created_at, updated_at
+		FROM stock_reservations
+		WHERE order_id = ?
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stock reservations: %w", err)
+	}
+	defer rows.Close()
+
+	reservations := []*Reservation{}
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan stock reservation: %w", err)
+		}
+		reservations = append(reservations, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return reservations, nil
+}
+
+// reserveStock holds stock for the tracked items of a new order until
+// holdUntil, failing with ErrOutOfStock when an item is short. With a zero
+// holdUntil it only checks the items are not short.
+func reserveStock(ctx context.Context, tx *sql.Tx, orderID int64, lines []stockLine, now, holdUntil time.Time) error {
+	for _, line := range lines {
+		tracked, available, err := availableStock(ctx, tx, line, now)
+		if err != nil {
+			return err
+		}
+		if !tracked {
+			continue
+		}
+		if available < line.quantity {
+			return outOfStock(line, available)
+		}
+		if holdUntil.IsZero() {
+			continue
+		}
+
+		if err := insertReservation(ctx, tx, orderID, line, ReservationHeld, nil, holdUntil, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitStock takes the stock of an order being confirmed. Items still held
+// take their hold; the others take stock if there is enough. Short items fail
+// with ErrOutOfStock unless allowShortage is set, in which case they are
+// skipped and returned.
+func commitStock(ctx context.Context, tx *sql.Tx, orderID int64, allowShortage bool, now time.Time) ([]string, error) {
+	reference, lines, err := orderStockLines(ctx, tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	reason := "Order " + reference
+
+	var shortages []string
+	for _, line := range lines {
+		var holdID int64
+		err := tx.QueryRowContext(ctx,
+			"SELECT id FROM stock_reservations WHERE order_item_id = ? AND status = ? AND expires_at > ?",
+			line.itemID, ReservationHeld, now,
+		).Scan(&holdID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get stock reservation: %w", err)
+		}
+
+		if holdID > 0 {
+			movementID, err := takeStock(ctx, tx, line, inventory.MovementSale, reason, now)
+			if err != nil {
+				return nil, err
+			}
+			_, err = tx.ExecContext(ctx,
+				"UPDATE stock_reservations SET status = ?, movement_id = ?, updated_at = ? WHERE id = ?",
+				ReservationCommitted, movementID, now, holdID,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to commit stock reservation: %w", err)
+			}
+			continue
+		}
+
+		tracked, available, err := availableStock(ctx, tx, line, now)
+		if err != nil {
+			return nil, err
+		}
+		if !tracked {
+			continue
+		}
+		if available < line.quantity {
+			if !allowShortage {
+				return nil, outOfStock(line, available)
+			}
+			shortages = append(shortages, line.label)
+			continue
+		}
+
+		movementID, err := takeStock(ctx, tx, line, inventory.MovementSale, reason, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertReservation(ctx, tx, orderID, line, ReservationCommitted, movementID, now, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Holds past their expiry that the sweeper has not released yet
+	_, err = tx.ExecContext(ctx,
+		"UPDATE stock_reservations SET status = ?, updated_at = ? WHERE order_id = ? AND status = ?",
+		ReservationReleased, now, orderID, ReservationHeld,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release stock reservations: %w", err)
+	}
+
+	return shortages, nil
+}
+
+// releaseStock releases the holds of a cancelled order and puts back the
+// stock it took
+func releaseStock(ctx context.Context, tx *sql.Tx, orderID int64, now time.Time) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE stock_reservations SET status = ?, updated_at = ? WHERE order_id = ? AND status = ?",
+		ReservationReleased, now, orderID, ReservationHeld,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to release stock reservations: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT r.id, r.order_item_id, r.product_id, r.variant_id, r.quantity, r.movement_id, o.reference
+		FROM stock_reservations r
+		JOIN orders o ON o.id = r.order_id
+		WHERE r.order_id = ? AND r.status = ?
+	`, orderID, ReservationCommitted)
+	if err != nil {
+		return fmt.Errorf("failed to query stock reservations: %w", err)
+	}
+
+	type taken struct {
+		id       int64
+		line     stockLine
+		movement sql.NullInt64
+	}
+	var committed []taken
+	var reference string
+	for rows.Next() {
+		var t taken
+		var variantID sql.NullInt64
+		if err := rows.Scan(&t.id, &t.line.itemID, &t.line.productID, &variantID, &t.line.quantity, &t.movement, &reference); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan stock reservation: %w", err)
+		}
+		if variantID.Valid {
+			t.line.variantID = &variantID.Int64
+		}
+		committed = append(committed, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	for _, t := range committed {
+		if t.line.variantID != nil {
+			_, err := tx.ExecContext(ctx,
+				"UPDATE product_variants SET stock = stock + ?, updated_at = ? WHERE id = ?",
+				t.line.quantity, now, *t.line.variantID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to restock variant: %w", err)
+			}
+		}
+		// Only stock taken through the ledger goes back through it
+		if t.movement.Valid {
+			if _, err := insertMovement(ctx, tx, t.line, inventory.MovementReturn, "Order "+reference+" cancelled", now); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx,
+			"UPDATE stock_reservations SET status = ?, updated_at = ? WHERE id = ?",
+			ReservationReturned, now, t.id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to return stock reservation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// availableStock computes the stock of an item not held by pending orders.
+// It reports false when the item's stock is not tracked.
+func availableStock(ctx context.Context, tx *sql.Tx, line stockLine, now time.Time) (bool, int, error) {
+	tracked, available := false, math.MaxInt
+
+	if line.variantID != nil {
+		var stock int
+		err := tx.QueryRowContext(ctx, "SELECT stock FROM product_variants WHERE id = ?", *line.variantID).Scan(&stock)
+		if err != nil && err != sql.ErrNoRows {
+			return false, 0, fmt.Errorf("failed to get variant stock: %w", err)
+		}
+		held, err := heldStock(ctx, tx, "variant_id = ?", *line.variantID, now)
+		if err != nil {
+			return false, 0, err
+		}
+		tracked, available = true, stock-held
+	}
+
+	movements, onHand, err := ledgerStock(ctx, tx, line.productID)
+	if err != nil {
+		return false, 0, err
+	}
+	if movements > 0 {
+		held, err := heldStock(ctx, tx, "product_id = ?", line.productID, now)
+		if err != nil {
+			return false, 0, err
+		}
+		tracked, available = true, min(available, onHand-held)
+	}
+
+	return tracked, available, nil
+}
+
+// heldStock sums the unexpired holds matching a condition
+func heldStock(ctx context.Context, tx *sql.Tx, condition string, arg interface{}, now time.Time) (int, error) {
+	var held int
+	err := tx.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations WHERE status = ? AND expires_at > ? AND %s", condition),
+		ReservationHeld, now, arg,
+	).Scan(&held)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get held stock: %w", err)
+	}
+	return held, nil
+}
+
+// ledgerStock counts the inventory movements of a product and sums its on-hand
+func ledgerStock(ctx context.Context, tx *sql.Tx, productID int64) (int, int, error) {
+	var movements, onHand int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM inventory_movements WHERE product_id = ?",
+		productID,
+	).Scan(&movements, &onHand)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get stock level: %w", err)
+	}
+	return movements, onHand, nil
+}
+
+// takeStock removes the quantity of an item from its variant and, when the
+// product is tracked in the ledger, records the movement and returns its ID
+func takeStock(ctx context.Context, tx *sql.Tx, line stockLine, movementType inventory.MovementType, reason string, now time.Time) (interface{}, error) {
+	if line.variantID != nil {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE product_variants SET stock = stock - ?, updated_at = ? WHERE id = ?",
+			line.quantity, now, *line.variantID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update variant stock: %w", err)
+		}
+	}
+
+	movements, _, err := ledgerStock(ctx, tx, line.productID)
+	if err != nil || movements == 0 {
+		return nil, err
+	}
+
+	return insertMovement(ctx, tx, line, movementType, reason, now)
+}
+
+// insertMovement appends a movement of the item's quantity to the inventory
+// ledger, negative for sales
+func insertMovement(ctx context.Context, tx *sql.Tx, line stockLine, movementType inventory.MovementType, reason string, now time.Time) (int64, error) {
+	quantity := line.quantity
+	if movementType == inventory.MovementSale {
+		quantity = -quantity
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO inventory_movements (product_id, variant_id, type, quantity, reason, admin_id, created_at)
+		VALUES (?, ?, ?, ?, ?, NULL, ?)
+	`, line.productID, line.variantID, movementType, quantity, reason, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record movement: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// insertReservation records the stock an order item holds or took
+func insertReservation(ctx context.Context, tx *sql.Tx, orderID int64, line stockLine, status string, movementID interface{}, expiresAt, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_reservations (order_id, order_item_id, product_id, variant_id, quantity, status, movement_id,
+			expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, orderID, line.itemID, line.productID, line.variantID, line.quantity, status, movementID, expiresAt, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to create stock reservation: %w", err)
+	}
+	return nil
+}
+
+// orderStockLines retrieves the reference of an order and the stock lines of its items
+func orderStockLines(ctx context.Context, tx *sql.Tx, orderID int64) (string, []stockLine, error) {
+	var reference string
+	if err := tx.QueryRowContext(ctx, "SELECT reference FROM orders WHERE id = ?", orderID).Scan(&reference); err != nil {
+		return "", nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, name, sku, size, color, quantity, unit_price, subtotal, discount, total,
+			promotion_id, promotion_name, design_id, design_token, design_image_url, design_surcharge
+		FROM order_items
+		WHERE order_id = ?
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []stockLine
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to scan order item: %w", err)
+		}
+		lines = append(lines, newStockLine(item))
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return reference, lines, nil
+}
+
+// outOfStock describes an item short of stock
+func outOfStock(line stockLine, available int) error {
+	return fmt.Errorf("%w: %d of %s left", ErrOutOfStock, max(available, 0), line.label)
+}
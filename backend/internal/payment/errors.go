@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotConfigured indicates no payment provider is configured
+	ErrNotConfigured = errors.New("online payments are not configured")
+
+	// ErrSignature indicates a webhook notification failed signature verification
+	ErrSignature = errors.New("invalid webhook signature")
+
+	// ErrAlreadyPaid indicates the order has an approved payment
+	ErrAlreadyPaid = errors.New("order is already paid")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
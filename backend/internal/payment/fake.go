@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/tomas/tienda-backend/internal/order"
+)
+
+// Fake is an in-memory provider for development and tests. Nothing serves
+// its checkout URLs: Pay simulates the customer paying, and notifications
+// are plain {"payment_id": "..."} bodies without a signature.
+type Fake struct {
+	mu        sync.Mutex
+	checkouts map[string]*order.Order
+	payments  map[string]*ProviderPayment
+}
+
+// NewFake creates a fake provider
+func NewFake() *Fake {
+	return &Fake{checkouts: make(map[string]*order.Order), payments: make(map[string]*ProviderPayment)}
+}
+
+// Name identifies the provider in stored payments
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// CreateCheckout records a checkout for the order
+func (f *Fake) CreateCheckout(ctx context.Context, o *order.Order) (*Checkout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := fmt.Sprintf("fake-checkout-%d", len(f.checkouts)+1)
+	f.checkouts[id] = o
+
+	return &Checkout{ID: id, URL: "https://payments.invalid/checkout/" + id}, nil
+}
+
+// Pay simulates a payment with the given status on a checkout and returns
+// the payment ID to notify
+func (f *Fake) Pay(checkoutID, status string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	o, ok := f.checkouts[checkoutID]
+	if !ok {
+		return "", fmt.Errorf("unknown checkout %s", checkoutID)
+	}
+
+	id := fmt.Sprintf("fake-payment-%d", len(f.payments)+1)
+	f.payments[id] = &ProviderPayment{ID: id, Reference: o.Reference, Status: status, Amount: o.Total}
+
+	return id, nil
+}
+
+// SetStatus changes the status of a simulated payment, e.g. to refund it
+func (f *Fake) SetStatus(paymentID, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("unknown payment %s", paymentID)
+	}
+	p.Status = status
+
+	return nil
+}
+
+// VerifyNotification reads the payment ID of a fake notification
+func (f *Fake) VerifyNotification(r *http.Request, body []byte) (string, error) {
+	var notification struct {
+		PaymentID string `json:"payment_id"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return "", ErrSignature
+	}
+
+	return notification.PaymentID, nil
+}
+
+// GetPayment returns a simulated payment
+func (f *Fake) GetPayment(ctx context.Context, id string) (*ProviderPayment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.payments[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment %s", id)
+	}
+
+	snapshot := *p
+	return &snapshot, nil
+}
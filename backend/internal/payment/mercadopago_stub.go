@@ -0,0 +1,224 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// MercadoPagoStub imitates the parts of the Mercado Pago API that the
+// MercadoPago provider uses, so the payment flow runs offline. Its checkout
+// page pays at once: GET /checkout/{id}?status=approved records a payment and
+// sends a signed notification to the preference's notification_url.
+type MercadoPagoStub struct {
+	accessToken   string
+	webhookSecret string
+	client        *http.Client
+	router        *mux.Router
+
+	mu          sync.Mutex
+	preferences map[string]*mpPreference
+	payments    map[int64]*mpPayment
+	notifyURLs  map[int64]string
+	nextID      int64
+}
+
+// NewMercadoPagoStub creates a stub accepting accessToken and signing
+// notifications with webhookSecret
+func NewMercadoPagoStub(accessToken, webhookSecret string) *MercadoPagoStub {
+	s := &MercadoPagoStub{
+		accessToken:   accessToken,
+		webhookSecret: webhookSecret,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		router:        mux.NewRouter(),
+		preferences:   make(map[string]*mpPreference),
+		payments:      make(map[int64]*mpPayment),
+		notifyURLs:    make(map[int64]string),
+		nextID:        1000,
+	}
+
+	s.router.HandleFunc("/checkout/preferences", s.authorized(s.createPreference)).Methods("POST")
+	s.router.HandleFunc("/v1/payments/{id}", s.authorized(s.getPayment)).Methods("GET")
+	s.router.HandleFunc("/checkout/{id}", s.pay).Methods("GET")
+	s.router.HandleFunc("/payments/{id}/status", s.setStatus).Methods("POST")
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *MercadoPagoStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// authorized rejects API requests without the stub's access token
+func (s *MercadoPagoStub) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.accessToken {
+			stubJSON(w, http.StatusUnauthorized, map[string]string{"message": "invalid access token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// createPreference handles POST /checkout/preferences
+func (s *MercadoPagoStub) createPreference(w http.ResponseWriter, r *http.Request) {
+	var pref mpPreference
+	if err := json.NewDecoder(r.Body).Decode(&pref); err != nil || len(pref.Items) == 0 {
+		stubJSON(w, http.StatusBadRequest, map[string]string{"message": "invalid preference"})
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	pref.ID = fmt.Sprintf("stub-%d", s.nextID)
+	pref.InitPoint = fmt.Sprintf("http://%s/checkout/%s", r.Host, pref.ID)
+	s.preferences[pref.ID] = &pref
+	s.mu.Unlock()
+
+	stubJSON(w, http.StatusCreated, pref)
+}
+
+// getPayment handles GET /v1/payments/{id}
+func (s *MercadoPagoStub) getPayment(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+
+	s.mu.Lock()
+	p, ok := s.payments[id]
+	var snapshot mpPayment
+	if ok {
+		snapshot = *p
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		stubJSON(w, http.StatusNotFound, map[string]string{"message": "payment not found"})
+		return
+	}
+	stubJSON(w, http.StatusOK, snapshot)
+}
+
+// pay handles GET /checkout/{id}?status=approved, standing in for the
+// customer paying on the checkout page
+func (s *MercadoPagoStub) pay(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "approved"
+	}
+
+	s.mu.Lock()
+	pref, ok := s.preferences[mux.Vars(r)["id"]]
+	var p *mpPayment
+	if ok {
+		amount := 0.0
+		for _, item := range pref.Items {
+			amount += item.UnitPrice * float64(item.Quantity)
+		}
+		s.nextID++
+		p = &mpPayment{
+			ID:                s.nextID,
+			Status:            status,
+			StatusDetail:      "stub",
+			ExternalReference: pref.ExternalReference,
+			TransactionAmount: math.Round(amount*100) / 100,
+		}
+		s.payments[p.ID] = p
+		s.notifyURLs[p.ID] = pref.NotificationURL
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		stubJSON(w, http.StatusNotFound, map[string]string{"message": "preference not found"})
+		return
+	}
+
+	stubJSON(w, http.StatusOK, map[string]interface{}{
+		"payment_id":   p.ID,
+		"status":       status,
+		"notification": s.notify(p.ID),
+	})
+}
+
+// setStatus handles POST /payments/{id}/status?status=refunded, changing a
+// payment after the fact and notifying again
+func (s *MercadoPagoStub) setStatus(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	status := r.URL.Query().Get("status")
+
+	s.mu.Lock()
+	p, ok := s.payments[id]
+	if ok && status != "" {
+		p.Status = status
+	}
+	s.mu.Unlock()
+
+	if !ok || status == "" {
+		stubJSON(w, http.StatusNotFound, map[string]string{"message": "payment not found or status missing"})
+		return
+	}
+
+	stubJSON(w, http.StatusOK, map[string]interface{}{
+		"payment_id":   id,
+		"status":       status,
+		"notification": s.notify(id),
+	})
+}
+
+// notify sends a signed payment notification the way Mercado Pago does and
+// describes the outcome
+func (s *MercadoPagoStub) notify(paymentID int64) string {
+	s.mu.Lock()
+	target := s.notifyURLs[paymentID]
+	s.mu.Unlock()
+	if target == "" {
+		return "skipped: preference has no notification_url"
+	}
+
+	dataID := strconv.FormatInt(paymentID, 10)
+	requestID := uuid.New().String()
+	ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	signature := hex.EncodeToString(mercadoPagoSignature(s.webhookSecret, dataID, requestID, ts))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"action": "payment.updated",
+		"type":   "payment",
+		"data":   map[string]string{"id": dataID},
+	})
+
+	notifyURL := fmt.Sprintf("%s?data.id=%s&type=payment", target, url.QueryEscape(dataID))
+	req, err := http.NewRequest(http.MethodPost, notifyURL, bytes.NewReader(body))
+	if err != nil {
+		return "failed: " + err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-request-id", requestID)
+	req.Header.Set("x-signature", fmt.Sprintf("ts=%s,v1=%s", ts, signature))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("Stub notification for payment %d failed: %v", paymentID, err)
+		return "failed: " + err.Error()
+	}
+	resp.Body.Close()
+
+	return resp.Status
+}
+
+// stubJSON writes a JSON response the way the Mercado Pago API does, without
+// the store's response envelope
+func stubJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
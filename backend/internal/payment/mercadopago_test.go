@@ -0,0 +1,164 @@
+package payment_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/payment"
+)
+
+const (
+	notifiedID = "123456"
+	requestID  = "bb56a2f1-6aae-46ac-982e-9dcd3581d08e"
+	timestamp  = "1742505638683"
+)
+
+type signatureSuite struct {
+	suite.Suite
+	provider *payment.MercadoPago
+}
+
+func TestSignatureSuite(t *testing.T) {
+	suite.Run(t, new(signatureSuite))
+}
+
+func (s *signatureSuite) SetupTest() {
+	s.provider = payment.NewMercadoPago(payment.MercadoPagoConfig{AccessToken: accessToken, WebhookSecret: webhookSecret})
+}
+
+// sign computes the v1 part of x-signature for a manifest, as documented by Mercado Pago
+func sign(secret, manifest string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(manifest))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var validSignature = sign(webhookSecret, fmt.Sprintf("id:%s;request-id:%s;ts:%s;", notifiedID, requestID, timestamp))
+
+var signatureCases = []struct {
+	name      string
+	query     string
+	body      string
+	requestID string
+	signature string
+	wantID    string
+	wantErr   error
+}{
+	{
+		name:      "Valid",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=" + validSignature,
+		wantID:    notifiedID,
+	},
+	{
+		name:      "Valid with spaces between parts",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ", v1=" + validSignature,
+		wantID:    notifiedID,
+	},
+	{
+		name:      "Data in the body only",
+		body:      `{"type":"payment","data":{"id":` + notifiedID + `}}`,
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=" + validSignature,
+		wantID:    notifiedID,
+	},
+	{
+		name:      "Uppercase ID signed in lowercase",
+		query:     "?data.id=ABC&type=payment",
+		signature: "ts=" + timestamp + ",v1=" + sign(webhookSecret, "id:abc;ts:"+timestamp+";"),
+		wantID:    "ABC",
+	},
+	{
+		name:      "Not about a payment",
+		query:     "?data.id=" + notifiedID + "&type=merchant_order",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=" + validSignature,
+	},
+	{
+		name:      "Missing signature",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Missing timestamp",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "v1=" + validSignature,
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Signature not hex",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=zz",
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Signed with another secret",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=" + sign("another-secret", fmt.Sprintf("id:%s;request-id:%s;ts:%s;", notifiedID, requestID, timestamp)),
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Another payment ID",
+		query:     "?data.id=999999&type=payment",
+		requestID: requestID,
+		signature: "ts=" + timestamp + ",v1=" + validSignature,
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Another request ID",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: "another-request",
+		signature: "ts=" + timestamp + ",v1=" + validSignature,
+		wantErr:   payment.ErrSignature,
+	},
+	{
+		name:      "Replayed with another timestamp",
+		query:     "?data.id=" + notifiedID + "&type=payment",
+		requestID: requestID,
+		signature: "ts=1742505999999,v1=" + validSignature,
+		wantErr:   payment.ErrSignature,
+	},
+}
+
+func (s *signatureSuite) TestVerifyNotification() {
+	for _, tc := range signatureCases {
+		r := httptest.NewRequest(http.MethodPost, "/api/payments/webhook"+tc.query, strings.NewReader(tc.body))
+		if tc.requestID != "" {
+			r.Header.Set("x-request-id", tc.requestID)
+		}
+		if tc.signature != "" {
+			r.Header.Set("x-signature", tc.signature)
+		}
+
+		id, err := s.provider.VerifyNotification(r, []byte(tc.body))
+
+		s.ErrorIs(err, tc.wantErr, tc.name)
+		s.Equal(tc.wantID, id, tc.name)
+	}
+}
+
+func (s *signatureSuite) TestWithoutSecretEverythingIsForged() {
+	provider := payment.NewMercadoPago(payment.MercadoPagoConfig{AccessToken: accessToken})
+	r := httptest.NewRequest(http.MethodPost, "/api/payments/webhook?data.id="+notifiedID+"&type=payment", nil)
+	r.Header.Set("x-request-id", requestID)
+	r.Header.Set("x-signature", "ts="+timestamp+",v1="+sign("", fmt.Sprintf("id:%s;request-id:%s;ts:%s;", notifiedID, requestID, timestamp)))
+
+	_, err := provider.VerifyNotification(r, nil)
+
+	s.ErrorIs(err, payment.ErrSignature)
+}
@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/order"
+)
+
+// Payment statuses, normalized across providers
+const (
+	StatusPending   = "pending" // Checkout created or payment in process
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded" // Also charged back
+)
+
+// Payment is an online payment attempt for an order
+type Payment struct {
+	ID                int64     `json:"id"`
+	OrderID           int64     `json:"order_id"`
+	Provider          string    `json:"provider"`
+	CheckoutID        string    `json:"checkout_id"`  // Provider checkout, e.g. a Mercado Pago preference
+	CheckoutURL       string    `json:"checkout_url"` // Page where the customer pays
+	ProviderPaymentID string    `json:"provider_payment_id,omitempty"`
+	Status            string    `json:"status"`
+	StatusDetail      string    `json:"status_detail,omitempty"`
+	Amount            int       `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Provider is an online payment gateway
+type Provider interface {
+	// Name identifies the provider in stored payments
+	Name() string
+
+	// CreateCheckout creates the page where the customer pays for an order
+	CreateCheckout(ctx context.Context, o *order.Order) (*Checkout, error)
+
+	// VerifyNotification checks the signature of a webhook request and returns
+	// the provider ID of the payment it is about, or "" for notifications
+	// about anything else. It fails with ErrSignature for forged requests.
+	VerifyNotification(r *http.Request, body []byte) (string, error)
+
+	// GetPayment fetches the current state of a payment
+	GetPayment(ctx context.Context, id string) (*ProviderPayment, error)
+}
+
+// Checkout is a provider page where the customer pays
+type Checkout struct {
+	ID  string
+	URL string
+}
+
+// ProviderPayment is the state of a payment as reported by a provider
+type ProviderPayment struct {
+	ID           string
+	Reference    string // Order reference sent with the checkout
+	Status       string // One of the Status constants
+	StatusDetail string
+	Amount       int
+}
+
+// CheckoutInput represents input for paying an order online
+type CheckoutInput struct {
+	Reference string `json:"reference"`
+}
+
+// scanPayment scans a database row into a Payment
+func scanPayment(row interface{ Scan(...interface{}) error }) (*Payment, error) {
+	var p Payment
+	var providerPaymentID sql.NullString
+
+	err := row.Scan(
+		&p.ID,
+		&p.OrderID,
+		&p.Provider,
+		&p.CheckoutID,
+		&p.CheckoutURL,
+		&providerPaymentID,
+		&p.Status,
+		&p.StatusDetail,
+		&p.Amount,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.ProviderPaymentID = providerPaymentID.String
+
+	return &p, nil
+}
@@ -0,0 +1,18 @@
+package payment
+
+import "context"
+
+// Repository defines the interface for payment data access
+type Repository interface {
+	// GetByOrder retrieves the payments of an order, newest first
+	GetByOrder(ctx context.Context, orderID int64) ([]*Payment, error)
+
+	// Create stores a new checkout
+	Create(ctx context.Context, p *Payment) (*Payment, error)
+
+	// Record stores the state of a provider payment of an order. The payment
+	// is matched by provider payment ID, then to the latest checkout of the
+	// order not yet matched, and is otherwise added. It reports whether
+	// anything changed, so repeated notifications are no-ops.
+	Record(ctx context.Context, orderID int64, provider string, state *ProviderPayment) (*Payment, bool, error)
+}
@@ -0,0 +1,123 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/tomas/tienda-backend/internal/order"
+)
+
+// Service provides business logic for online payments
+type Service struct {
+	repo     Repository
+	orders   *order.Service
+	provider Provider
+}
+
+// NewService creates a new payment service. A nil provider disables online
+// payments.
+func NewService(repo Repository, orderService *order.Service, provider Provider) *Service {
+	return &Service{repo: repo, orders: orderService, provider: provider}
+}
+
+// CreateCheckout creates the provider page where the customer pays an order,
+// identified by its reference
+func (s *Service) CreateCheckout(ctx context.Context, input CheckoutInput) (*Payment, error) {
+	if s.provider == nil {
+		return nil, ErrNotConfigured
+	}
+	if input.Reference == "" {
+		return nil, ErrInvalidInput("reference is required")
+	}
+
+	o, err := s.orders.GetOrderByReference(ctx, input.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if o.PaymentStatus == StatusApproved {
+		return nil, ErrAlreadyPaid
+	}
+	if o.Status != order.StatusPending && o.Status != order.StatusConfirmed {
+		return nil, ErrInvalidInput(fmt.Sprintf("a %s order cannot be paid", o.Status))
+	}
+
+	checkout, err := s.provider.CreateCheckout(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, &Payment{
+		OrderID:     o.ID,
+		Provider:    s.provider.Name(),
+		CheckoutID:  checkout.ID,
+		CheckoutURL: checkout.URL,
+		Status:      StatusPending,
+		Amount:      o.Total,
+	})
+}
+
+// GetPayments retrieves the payments of an order, newest first
+func (s *Service) GetPayments(ctx context.Context, orderID int64) ([]*Payment, error) {
+	if _, err := s.orders.GetOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByOrder(ctx, orderID)
+}
+
+// HandleNotification processes a provider webhook. The payment is fetched
+// from the provider rather than trusted from the request, and repeated
+// notifications leave the payment and order unchanged. An approved payment
+// covering the order total marks the order paid.
+func (s *Service) HandleNotification(ctx context.Context, r *http.Request, body []byte) error {
+	if s.provider == nil {
+		return ErrNotConfigured
+	}
+
+	paymentID, err := s.provider.VerifyNotification(r, body)
+	if err != nil {
+		return err
+	}
+	if paymentID == "" {
+		return nil
+	}
+
+	state, err := s.provider.GetPayment(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+
+	o, err := s.orders.GetOrderByReference(ctx, state.Reference)
+	if errors.Is(err, order.ErrNotFound) {
+		// Retrying will not help, e.g. a payment made outside the store
+		log.Printf("Ignoring %s payment %s for unknown order %q", s.provider.Name(), state.ID, state.Reference)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	p, changed, err := s.repo.Record(ctx, o.ID, s.provider.Name(), state)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	// A later failed attempt does not hide an approved payment
+	if o.PaymentStatus == StatusApproved && p.Status != StatusApproved && p.Status != StatusRefunded {
+		return nil
+	}
+
+	paid := p.Status == StatusApproved && p.Amount >= o.Total
+	if p.Status == StatusApproved && !paid {
+		log.Printf("Payment %d of order %s covers %d of %d; not marking it paid", p.ID, o.Reference, p.Amount, o.Total)
+	}
+
+	note := fmt.Sprintf("%s payment %s %s", s.provider.Name(), p.ProviderPaymentID, p.Status)
+	_, err = s.orders.RecordPayment(ctx, o.ID, p.Status, paid, note)
+	return err
+}
@@ -0,0 +1,323 @@
+package payment_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/payment"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	orderPrice    = 1250050 // $12.500,50, so cents must survive the round trip
+	accessToken   = "TEST-token"
+	webhookSecret = "webhook-secret"
+	holdFor       = 15 * time.Minute
+)
+
+type paymentSuite struct {
+	suite.Suite
+	ctx    context.Context
+	repo   payment.Repository
+	orders *order.Service
+	order  *order.Order
+}
+
+func TestPaymentSuite(t *testing.T) {
+	suite.Run(t, new(paymentSuite))
+}
+
+func (s *paymentSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.repo = payment.NewSQLiteRepository(db)
+	products := product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), products)
+	s.orders = order.NewService(order.NewSQLiteRepository(db), products, promotions, holdFor)
+
+	p, err := products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera", Price: orderPrice})
+	s.Require().NoError(err)
+	s.order, err = s.orders.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:    []order.CreateItemInput{{ProductID: p.ID, Quantity: 1}},
+	})
+	s.Require().NoError(err)
+}
+
+// reload returns the current state of the test order
+func (s *paymentSuite) reload() *order.Order {
+	o, err := s.orders.GetOrder(s.ctx, s.order.ID)
+	s.Require().NoError(err)
+	return o
+}
+
+// notifyFake sends a fake provider notification about a payment
+func (s *paymentSuite) notifyFake(svc *payment.Service, paymentID string) error {
+	body, err := json.Marshal(map[string]string{"payment_id": paymentID})
+	s.Require().NoError(err)
+	r := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", bytes.NewReader(body))
+	return svc.HandleNotification(s.ctx, r, body)
+}
+
+var fakePaymentCases = []struct {
+	name              string
+	statuses          []string // Statuses the payment goes through, each notified
+	wantStatus        string
+	wantPaymentStatus string
+}{
+	{name: "Approved", statuses: []string{payment.StatusApproved}, wantStatus: order.StatusPaid, wantPaymentStatus: payment.StatusApproved},
+	{name: "Rejected", statuses: []string{payment.StatusRejected}, wantStatus: order.StatusPending, wantPaymentStatus: payment.StatusRejected},
+	{name: "Pending then approved", statuses: []string{payment.StatusPending, payment.StatusApproved}, wantStatus: order.StatusPaid, wantPaymentStatus: payment.StatusApproved},
+	{name: "Approved then refunded", statuses: []string{payment.StatusApproved, payment.StatusRefunded}, wantStatus: order.StatusPaid, wantPaymentStatus: payment.StatusRefunded},
+}
+
+func (s *paymentSuite) TestFakeNotifications() {
+	for _, tc := range fakePaymentCases {
+		s.SetupTest()
+		fake := payment.NewFake()
+		svc := payment.NewService(s.repo, s.orders, fake)
+		created, err := svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+		s.Require().NoError(err, tc.name)
+
+		paymentID, err := fake.Pay(created.CheckoutID, tc.statuses[0])
+		s.Require().NoError(err, tc.name)
+		for i, status := range tc.statuses {
+			if i > 0 {
+				s.Require().NoError(fake.SetStatus(paymentID, status), tc.name)
+			}
+			s.Require().NoError(s.notifyFake(svc, paymentID), tc.name)
+		}
+		o := s.reload()
+		payments, err := svc.GetPayments(s.ctx, s.order.ID)
+		s.Require().NoError(err, tc.name)
+
+		s.Equal(tc.wantStatus, o.Status, tc.name)
+		s.Equal(tc.wantPaymentStatus, o.PaymentStatus, tc.name)
+		s.Len(payments, 1, tc.name)
+		s.Equal(paymentID, payments[0].ProviderPaymentID, tc.name)
+		s.Equal(orderPrice, payments[0].Amount, tc.name)
+	}
+}
+
+func (s *paymentSuite) TestRepeatedNotificationChangesNothing() {
+	fake := payment.NewFake()
+	svc := payment.NewService(s.repo, s.orders, fake)
+	created, err := svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	s.Require().NoError(err)
+	paymentID, err := fake.Pay(created.CheckoutID, payment.StatusApproved)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.notifyFake(svc, paymentID))
+	paid := s.reload()
+	againErr := s.notifyFake(svc, paymentID)
+	again := s.reload()
+	_, checkoutErr := svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+
+	s.NoError(againErr)
+	s.Equal(order.StatusPaid, again.Status)
+	s.Equal(paid.History, again.History)
+	s.Equal(paid.UpdatedAt, again.UpdatedAt)
+	s.ErrorIs(checkoutErr, payment.ErrAlreadyPaid)
+}
+
+func (s *paymentSuite) TestFakeNotificationErrors() {
+	svc := payment.NewService(s.repo, s.orders, payment.NewFake())
+	r := httptest.NewRequest(http.MethodPost, "/api/payments/webhook", nil)
+
+	forgedErr := svc.HandleNotification(s.ctx, r, []byte("not json"))
+	unknownErr := s.notifyFake(svc, "fake-payment-404")
+	_, disabledErr := payment.NewService(s.repo, s.orders, nil).CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+
+	s.ErrorIs(forgedErr, payment.ErrSignature)
+	s.Error(unknownErr)
+	s.ErrorIs(disabledErr, payment.ErrNotConfigured)
+	s.Equal(order.StatusPending, s.reload().Status)
+}
+
+// stubFlow wires the Mercado Pago provider to a stub and the stub's
+// notifications to the webhook, the way the app does
+type stubFlow struct {
+	svc     *payment.Service
+	stub    *httptest.Server
+	webhook *httptest.Server
+}
+
+// newStubFlow starts a Mercado Pago stub and a webhook handling its notifications
+func (s *paymentSuite) newStubFlow() *stubFlow {
+	f := &stubFlow{stub: httptest.NewServer(payment.NewMercadoPagoStub(accessToken, webhookSecret))}
+	f.webhook = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := f.svc.HandleNotification(r.Context(), r, body)
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusOK)
+		case errors.Is(err, payment.ErrSignature):
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	s.T().Cleanup(f.stub.Close)
+	s.T().Cleanup(f.webhook.Close)
+
+	f.svc = payment.NewService(s.repo, s.orders, payment.NewMercadoPago(payment.MercadoPagoConfig{
+		APIURL:          f.stub.URL,
+		AccessToken:     accessToken,
+		WebhookSecret:   webhookSecret,
+		NotificationURL: f.webhook.URL,
+	}))
+	return f
+}
+
+// stubCall sends a request to the stub and returns the outcome of the notification it sent
+func (s *paymentSuite) stubCall(method, url string) (int64, string) {
+	req, err := http.NewRequest(method, url, nil)
+	s.Require().NoError(err)
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	var result struct {
+		PaymentID    int64  `json:"payment_id"`
+		Notification string `json:"notification"`
+	}
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&result))
+	return result.PaymentID, result.Notification
+}
+
+func (s *paymentSuite) TestMercadoPagoStubPaysInPesos() {
+	f := s.newStubFlow()
+	created, err := f.svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	s.Require().NoError(err)
+
+	paymentID, notification := s.stubCall(http.MethodGet, created.CheckoutURL)
+	paid := s.reload()
+	payments, err := f.svc.GetPayments(s.ctx, s.order.ID)
+	s.Require().NoError(err)
+
+	s.Equal("200 OK", notification)
+	s.Equal(order.StatusPaid, paid.Status)
+	s.Equal(payment.StatusApproved, paid.PaymentStatus)
+	s.Equal(orderPrice, payments[0].Amount)
+	s.NotZero(paymentID)
+}
+
+func (s *paymentSuite) TestMercadoPagoStubRepeatedNotification() {
+	f := s.newStubFlow()
+	created, err := f.svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	s.Require().NoError(err)
+	paymentID, _ := s.stubCall(http.MethodGet, created.CheckoutURL)
+	paid := s.reload()
+
+	_, notification := s.stubCall(http.MethodPost, f.stub.URL+"/payments/"+strconv.FormatInt(paymentID, 10)+"/status?status=approved")
+	again := s.reload()
+	payments, err := f.svc.GetPayments(s.ctx, s.order.ID)
+	s.Require().NoError(err)
+
+	s.Equal("200 OK", notification)
+	s.Equal(paid.History, again.History)
+	s.Len(payments, 1)
+}
+
+func (s *paymentSuite) TestMercadoPagoStubRejectedPayment() {
+	f := s.newStubFlow()
+	created, err := f.svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	s.Require().NoError(err)
+
+	_, notification := s.stubCall(http.MethodGet, created.CheckoutURL+"?status=rejected")
+	o := s.reload()
+
+	s.Equal("200 OK", notification)
+	s.Equal(order.StatusPending, o.Status)
+	s.Equal(payment.StatusRejected, o.PaymentStatus)
+}
+
+func (s *paymentSuite) TestMercadoPagoStubWrongSecret() {
+	f := s.newStubFlow()
+	forger := httptest.NewServer(payment.NewMercadoPagoStub(accessToken, "another-secret"))
+	s.T().Cleanup(forger.Close)
+	provider := payment.NewMercadoPago(payment.MercadoPagoConfig{
+		APIURL:          forger.URL,
+		AccessToken:     accessToken,
+		WebhookSecret:   webhookSecret,
+		NotificationURL: f.webhook.URL,
+	})
+	created, err := payment.NewService(s.repo, s.orders, provider).CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	s.Require().NoError(err)
+
+	_, notification := s.stubCall(http.MethodGet, created.CheckoutURL)
+
+	s.Equal("401 Unauthorized", notification)
+	s.Equal(order.StatusPending, s.reload().Status)
+}
+
+var stubStatusCases = []struct {
+	name              string
+	status            string // As Mercado Pago reports it
+	wantPaymentStatus string
+}{
+	{name: "Refunded", status: "refunded", wantPaymentStatus: payment.StatusRefunded},
+	{name: "Charged back", status: "charged_back", wantPaymentStatus: payment.StatusRefunded},
+	{name: "Cancelled", status: "cancelled", wantPaymentStatus: payment.StatusCancelled},
+	{name: "In process", status: "in_process", wantPaymentStatus: payment.StatusPending},
+}
+
+func (s *paymentSuite) TestMercadoPagoStubStatusChanges() {
+	for _, tc := range stubStatusCases {
+		s.SetupTest()
+		f := s.newStubFlow()
+		created, err := f.svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+		s.Require().NoError(err, tc.name)
+		paymentID, _ := s.stubCall(http.MethodGet, created.CheckoutURL+"?status=pending")
+
+		_, notification := s.stubCall(http.MethodPost, f.stub.URL+"/payments/"+strconv.FormatInt(paymentID, 10)+"/status?status="+tc.status)
+		o := s.reload()
+
+		s.Equal("200 OK", notification, tc.name)
+		s.Equal(tc.wantPaymentStatus, o.PaymentStatus, tc.name)
+		s.Equal(order.StatusPending, o.Status, tc.name)
+	}
+}
+
+func (s *paymentSuite) TestMercadoPagoWrongAccessToken() {
+	stub := httptest.NewServer(payment.NewMercadoPagoStub(accessToken, webhookSecret))
+	s.T().Cleanup(stub.Close)
+	provider := payment.NewMercadoPago(payment.MercadoPagoConfig{APIURL: stub.URL, AccessToken: "another-token", WebhookSecret: webhookSecret})
+
+	_, err := payment.NewService(s.repo, s.orders, provider).CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	payments, listErr := s.repo.GetByOrder(s.ctx, s.order.ID)
+
+	s.ErrorContains(err, "unexpected status 401")
+	s.Require().NoError(listErr)
+	s.Empty(payments)
+}
+
+func (s *paymentSuite) TestCheckoutValidation() {
+	svc := payment.NewService(s.repo, s.orders, payment.NewFake())
+	_, err := s.orders.TransitionOrder(s.ctx, s.order.ID, order.TransitionInput{Status: order.StatusCancelled})
+	s.Require().NoError(err)
+
+	_, missingErr := svc.CreateCheckout(s.ctx, payment.CheckoutInput{})
+	_, unknownErr := svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: "NOPE00"})
+	_, cancelledErr := svc.CreateCheckout(s.ctx, payment.CheckoutInput{Reference: s.order.Reference})
+	_, paymentsErr := svc.GetPayments(s.ctx, 9999)
+
+	s.ErrorIs(missingErr, payment.ErrValidation)
+	s.ErrorIs(unknownErr, order.ErrNotFound)
+	s.ErrorIs(cancelledErr, payment.ErrValidation)
+	s.ErrorIs(paymentsErr, order.ErrNotFound)
+}
@@ -0,0 +1,164 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const paymentColumns = `id, order_id, provider, checkout_id, checkout_url, provider_payment_id, status, status_detail,
+	amount, created_at, updated_at`
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite payment repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// GetByOrder retrieves the payments of an order, newest first
+func (r *SQLiteRepository) GetByOrder(ctx context.Context, orderID int64) ([]*Payment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM payments WHERE order_id = ? ORDER BY id DESC", paymentColumns),
+		orderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return payments, nil
+}
+
+// Create stores a new checkout
+func (r *SQLiteRepository) Create(ctx context.Context, p *Payment) (*Payment, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO payments (order_id, provider, checkout_id, checkout_url, status, amount, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.OrderID, p.Provider, p.CheckoutID, p.CheckoutURL, p.Status, p.Amount, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return getPayment(ctx, r.db, id)
+}
+
+// Record stores the state of a provider payment of an order. The payment is
+// matched by provider payment ID, then to the latest checkout of the order
+// not yet matched, and is otherwise added. It reports whether anything
+// changed, so repeated notifications are no-ops.
+func (r *SQLiteRepository) Record(ctx context.Context, orderID int64, provider string, state *ProviderPayment) (*Payment, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var id int64
+	var status, detail string
+	var amount int
+	err = tx.QueryRowContext(ctx,
+		"SELECT id, status, status_detail, amount FROM payments WHERE provider = ? AND provider_payment_id = ?",
+		provider, state.ID,
+	).Scan(&id, &status, &detail, &amount)
+
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRowContext(ctx, `
+			SELECT id FROM payments
+			WHERE order_id = ? AND provider = ? AND provider_payment_id IS NULL
+			ORDER BY id DESC LIMIT 1
+		`, orderID, provider).Scan(&id)
+		if err == sql.ErrNoRows {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO payments (order_id, provider, checkout_id, checkout_url, provider_payment_id, status,
+					status_detail, amount, created_at, updated_at)
+				VALUES (?, ?, '', '', ?, ?, ?, ?, ?, ?)
+			`, orderID, provider, state.ID, state.Status, state.StatusDetail, state.Amount, now, now)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to create payment: %w", err)
+			}
+			if id, err = result.LastInsertId(); err != nil {
+				return nil, false, fmt.Errorf("failed to get last insert ID: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to find checkout: %w", err)
+		}
+		if err := updatePayment(ctx, tx, id, state, now); err != nil {
+			return nil, false, err
+		}
+	case err != nil:
+		return nil, false, fmt.Errorf("failed to find payment: %w", err)
+	case status == state.Status && detail == state.StatusDetail && amount == state.Amount:
+		p, err := getPayment(ctx, tx, id)
+		return p, false, err
+	default:
+		if err := updatePayment(ctx, tx, id, state, now); err != nil {
+			return nil, false, err
+		}
+	}
+
+	p, err := getPayment(ctx, tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit payment: %w", err)
+	}
+
+	return p, true, nil
+}
+
+// updatePayment stores the provider state of a payment
+func updatePayment(ctx context.Context, tx *sql.Tx, id int64, state *ProviderPayment, now time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE payments SET provider_payment_id = ?, status = ?, status_detail = ?, amount = ?, updated_at = ?
+		WHERE id = ?
+	`, state.ID, state.Status, state.StatusDetail, state.Amount, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	return nil
+}
+
+// getPayment retrieves a payment by ID
+func getPayment(ctx context.Context, db queryRower, id int64) (*Payment, error) {
+	row := db.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM payments WHERE id = ?", paymentColumns), id)
+	p, err := scanPayment(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	return p, nil
+}
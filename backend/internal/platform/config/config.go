@@ -20,6 +20,37 @@ type Config struct {
 	MaxUploadSizeMB int
 	LogLevel        string
 	BaseURL         string
+
+	LowStockThreshold  int
+	TrashRetentionDays int
+	CartTTLDays        int
+	StockHoldMinutes   int // How long pending orders hold their stock
+
+	WhatsAppPhone           string // International format without + or spaces, e.g. 5491123456789
+	WhatsAppMessageTemplate string // text/template for the checkout message; a Spanish default applies when empty
+
+	PaymentProvider          string // mercadopago, fake, or empty to disable online payments
+	PaymentReturnURL         string // Storefront page customers return to after paying
+	MercadoPagoAccessToken   string
+	MercadoPagoWebhookSecret string
+	MercadoPagoAPIURL        string // Override to use the local stub (cmd/mercadopago-stub)
+
+	DesignProductID int64  // Product custom designs are printed on; 0 disables ordering designs
+	DesignSurcharge int    // Charged per unit for printing a custom design, same unit as product prices
+	DesignShareURL  string // Storefront page showing a shared design; the design token is appended
+
+	DesignFontDir       string // TTF and OTF fonts used to render design text for print
+	DesignPrintWidthCM  int    // Default print area of rendered designs
+	DesignPrintHeightCM int
+
+	CustomerJWTExpiryHours int
+	CustomerVerifyURL      string // Storefront page that verifies emails; the token is added as the token query parameter
+
+	SMTPHost     string // Emails are logged instead of sent when empty
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
 }
 
 // Load reads configuration from environment variables
@@ -39,6 +70,37 @@ func Load() (*Config, error) {
 		MaxUploadSizeMB: getEnvAsInt("MAX_UPLOAD_SIZE_MB", 5),
 		LogLevel:        getEnv("LOG_LEVEL", "info"),
 		BaseURL:         getEnv("BASE_URL", "http://localhost:3000"),
+
+		LowStockThreshold:  getEnvAsInt("LOW_STOCK_THRESHOLD", 3),
+		TrashRetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+		CartTTLDays:        getEnvAsInt("CART_TTL_DAYS", 30),
+		StockHoldMinutes:   getEnvAsInt("STOCK_HOLD_MINUTES", 60),
+
+		WhatsAppPhone:           getEnv("WHATSAPP_PHONE", ""),
+		WhatsAppMessageTemplate: getEnv("WHATSAPP_MESSAGE_TEMPLATE", ""),
+
+		PaymentProvider:          getEnv("PAYMENT_PROVIDER", ""),
+		PaymentReturnURL:         getEnv("PAYMENT_RETURN_URL", ""),
+		MercadoPagoAccessToken:   getEnv("MERCADOPAGO_ACCESS_TOKEN", ""),
+		MercadoPagoWebhookSecret: getEnv("MERCADOPAGO_WEBHOOK_SECRET", ""),
+		MercadoPagoAPIURL:        getEnv("MERCADOPAGO_API_URL", "https://api.mercadopago.com"),
+
+		DesignProductID: int64(getEnvAsInt("DESIGN_PRODUCT_ID", 0)),
+		DesignSurcharge: getEnvAsInt("DESIGN_SURCHARGE", 0),
+		DesignShareURL:  getEnv("DESIGN_SHARE_URL", "http://localhost:5173/designer"),
+
+		DesignFontDir:       getEnv("DESIGN_FONT_DIR", "./fonts"),
+		DesignPrintWidthCM:  getEnvAsInt("DESIGN_PRINT_WIDTH_CM", 30),
+		DesignPrintHeightCM: getEnvAsInt("DESIGN_PRINT_HEIGHT_CM", 40),
+
+		CustomerJWTExpiryHours: getEnvAsInt("CUSTOMER_JWT_EXPIRY_HOURS", 720),
+		CustomerVerifyURL:      getEnv("CUSTOMER_VERIFY_URL", "http://localhost:5173/cuenta/verificar"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", ""),
 	}
 
 	// Validate required fields
@@ -50,6 +112,14 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("JWT_SECRET must be at least 32 characters long")
 	}
 
+	if cfg.PaymentProvider == "mercadopago" && (cfg.MercadoPagoAccessToken == "" || cfg.MercadoPagoWebhookSecret == "") {
+		return nil, fmt.Errorf("MERCADOPAGO_ACCESS_TOKEN and MERCADOPAGO_WEBHOOK_SECRET are required when PAYMENT_PROVIDER is mercadopago")
+	}
+
+	if cfg.SMTPHost != "" && cfg.MailFrom == "" {
+		return nil, fmt.Errorf("MAIL_FROM is required when SMTP_HOST is set")
+	}
+
 	return cfg, nil
 }
 
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// backfillCategories creates one category per distinct free-text product
+// category, merging case and accent variants such as "Santos" and "santos",
+// and links every product to its category with the normalized name.
+func backfillCategories(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, trim(category)
+		FROM products
+		WHERE trim(COALESCE(category, '')) != ''
+		ORDER BY trim(category), id
+	`)
+	if err != nil {
+		return err
+	}
+
+	type productCategory struct {
+		id   int64
+		slug string
+	}
+
+	// Keep the first spelling seen for each slug as the display name
+	var products []productCategory
+	var slugs []string
+	names := make(map[string]string)
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return err
+		}
+		s := slug.Make(name)
+		if s == "" {
+			continue
+		}
+		if _, ok := names[s]; !ok {
+			names[s] = name
+			slugs = append(slugs, s)
+		}
+		products = append(products, productCategory{id: id, slug: s})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	categoryIDs := make(map[string]int64, len(slugs))
+	for i, s := range slugs {
+		result, err := tx.Exec(
+			"INSERT INTO categories (slug, name, sort_order) VALUES (?, ?, ?)",
+			s, names[s], i,
+		)
+		if err != nil {
+			return err
+		}
+		if categoryIDs[s], err = result.LastInsertId(); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range products {
+		_, err := tx.Exec(
+			"UPDATE products SET category_id = ?, category = ? WHERE id = ?",
+			categoryIDs[p.slug], names[p.slug], p.id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillProductSlugs derives a unique slug from the name of every product,
+// suffixing repeated names as "remera-santos-2", "remera-santos-3" in ID order
+func backfillProductSlugs(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, name FROM products WHERE slug IS NULL ORDER BY id")
+	if err != nil {
+		return err
+	}
+
+	type productName struct {
+		id   int64
+		name string
+	}
+
+	var products []productName
+	for rows.Next() {
+		var p productName
+		if err := rows.Scan(&p.id, &p.name); err != nil {
+			rows.Close()
+			return err
+		}
+		products = append(products, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool, len(products))
+	for _, p := range products {
+		base := slug.Make(p.name)
+		if base == "" {
+			base = "producto"
+		}
+
+		candidate := base
+		for n := 2; used[candidate]; n++ {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[candidate] = true
+
+		if _, err := tx.Exec("UPDATE products SET slug = ? WHERE id = ?", candidate, p.id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
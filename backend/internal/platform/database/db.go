@@ -23,8 +23,11 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	// Open SQLite database
-	sqlDB, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on")
+	// Open SQLite database with foreign keys enforced on every connection.
+	// Transactions take the write lock when they begin and wait up to 5s for
+	// it, so concurrent read-then-write transactions, such as stock
+	// reservations, run one after another instead of failing with SQLITE_BUSY.
+	sqlDB, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_txlock=immediate&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -0,0 +1,51 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const connections = 3
+
+type dbSuite struct {
+	suite.Suite
+	db *database.DB
+}
+
+func TestDBSuite(t *testing.T) {
+	suite.Run(t, new(dbSuite))
+}
+
+func (s *dbSuite) SetupTest() {
+	var err error
+	s.db, err = database.New(filepath.Join(s.T().TempDir(), "test.db"))
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { s.db.Close() })
+}
+
+func (s *dbSuite) TestForeignKeysOnEveryConnection() {
+	ctx := context.Background()
+	// Holding the connections open makes the pool open new ones
+	for i := 0; i < connections; i++ {
+		conn, err := s.db.Conn(ctx)
+		s.Require().NoError(err)
+		defer conn.Close()
+
+		var enabled int
+		s.Require().NoError(conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled))
+		s.Equal(1, enabled)
+	}
+}
+
+func (s *dbSuite) TestForeignKeyViolationFails() {
+	s.Require().NoError(database.Migrate(s.db.DB))
+
+	_, err := s.db.Exec("INSERT INTO product_variants (product_id, sku) VALUES (9999, 'NOPE')")
+
+	s.ErrorContains(err, "FOREIGN KEY constraint failed")
+}
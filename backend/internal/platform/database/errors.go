@@ -0,0 +1,8 @@
+package database
+
+import "strings"
+
+// IsUniqueViolation reports whether err is a SQLite UNIQUE constraint failure
+func IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -10,6 +10,9 @@ type Migration struct {
 	Version     int
 	Description string
 	SQL         string
+
+	// Backfill optionally migrates existing data after SQL, in the same transaction
+	Backfill func(tx *sql.Tx) error
 }
 
 // migrations contains all database migrations in order
@@ -71,6 +74,562 @@ var migrations = []Migration{
 			CREATE INDEX IF NOT EXISTS idx_products_featured ON products(featured);
 		`,
 	},
+	{
+		Version:     5,
+		Description: "Create product_variants table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS product_variants (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				sku TEXT UNIQUE NOT NULL,
+				size TEXT NOT NULL DEFAULT '',
+				color TEXT NOT NULL DEFAULT '',
+				stock INTEGER NOT NULL DEFAULT 0,
+				price INTEGER NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (product_id, size, color)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_product_variants_product ON product_variants(product_id);
+		`,
+	},
+	{
+		Version:     6,
+		Description: "Create inventory_movements table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS inventory_movements (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				variant_id INTEGER NULL REFERENCES product_variants(id),
+				type TEXT NOT NULL,
+				quantity INTEGER NOT NULL,
+				reason TEXT,
+				admin_id INTEGER NULL REFERENCES admins(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_inventory_movements_product ON inventory_movements(product_id);
+			CREATE INDEX IF NOT EXISTS idx_inventory_movements_created ON inventory_movements(created_at);
+		`,
+	},
+	{
+		Version:     7,
+		Description: "Create products_fts full-text index",
+		SQL: `
+			CREATE VIRTUAL TABLE IF NOT EXISTS products_fts USING fts5(
+				name,
+				description,
+				tags,
+				category,
+				content='products',
+				content_rowid='id',
+				tokenize="unicode61 remove_diacritics 2"
+			);
+
+			CREATE TRIGGER IF NOT EXISTS products_fts_insert AFTER INSERT ON products BEGIN
+				INSERT INTO products_fts (rowid, name, description, tags, category)
+				VALUES (new.id, new.name, new.description, new.tags, new.category);
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS products_fts_delete AFTER DELETE ON products BEGIN
+				INSERT INTO products_fts (products_fts, rowid, name, description, tags, category)
+				VALUES ('delete', old.id, old.name, old.description, old.tags, old.category);
+			END;
+
+			CREATE TRIGGER IF NOT EXISTS products_fts_update AFTER UPDATE OF name, description, tags, category ON products BEGIN
+				INSERT INTO products_fts (products_fts, rowid, name, description, tags, category)
+				VALUES ('delete', old.id, old.name, old.description, old.tags, old.category);
+				INSERT INTO products_fts (rowid, name, description, tags, category)
+				VALUES (new.id, new.name, new.description, new.tags, new.category);
+			END;
+
+			INSERT INTO products_fts (products_fts) VALUES ('rebuild');
+		`,
+	},
+	{
+		Version:     8,
+		Description: "Create categories table and backfill from products",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS categories (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				slug TEXT UNIQUE NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				parent_id INTEGER NULL REFERENCES categories(id),
+				sort_order INTEGER NOT NULL DEFAULT 0,
+				cover_image TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+
+			ALTER TABLE products ADD COLUMN category_id INTEGER NULL REFERENCES categories(id);
+			CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+		`,
+		Backfill: backfillCategories,
+	},
+	{
+		Version:     9,
+		Description: "Add slug to products and create product_slug_history table",
+		SQL: `
+			ALTER TABLE products ADD COLUMN slug TEXT NULL;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products(slug);
+
+			CREATE TABLE IF NOT EXISTS product_slug_history (
+				slug TEXT PRIMARY KEY,
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_product_slug_history_product ON product_slug_history(product_id);
+		`,
+		Backfill: backfillProductSlugs,
+	},
+	{
+		Version:     10,
+		Description: "Create product_revisions table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS product_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				revision INTEGER NOT NULL,
+				action TEXT NOT NULL,
+				source_revision INTEGER NULL,
+				snapshot TEXT NOT NULL,
+				admin_id INTEGER NULL REFERENCES admins(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (product_id, revision)
+			);
+
+			-- Baseline revision with the current state of existing products
+			INSERT INTO product_revisions (product_id, revision, action, snapshot, created_at)
+			SELECT id, 1, 'create', json_object(
+				'name', name,
+				'slug', COALESCE(slug, ''),
+				'description', COALESCE(description, ''),
+				'price', price,
+				'category', COALESCE(category, ''),
+				'category_id', category_id,
+				'images', CASE WHEN json_valid(images) AND json_type(images) = 'array' THEN json(images) ELSE json_array() END,
+				'tags', CASE WHEN json_valid(tags) AND json_type(tags) = 'array' THEN json(tags) ELSE json_array() END,
+				'sizes', CASE WHEN json_valid(sizes) AND json_type(sizes) = 'array' THEN json(sizes) ELSE json_array() END,
+				'colors', CASE WHEN json_valid(colors) AND json_type(colors) = 'array' THEN json(colors) ELSE json_array() END,
+				'gender', COALESCE(gender, ''),
+				'oversize', json(CASE WHEN oversize = 1 THEN 'true' ELSE 'false' END),
+				'featured', json(CASE WHEN featured = 1 THEN 'true' ELSE 'false' END),
+				'deleted', json(CASE WHEN deleted_at IS NULL THEN 'false' ELSE 'true' END)
+			), COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+			FROM products;
+		`,
+	},
+	{
+		Version:     11,
+		Description: "Create price adjustment tables",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS price_adjustments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				type TEXT NOT NULL,
+				value REAL NOT NULL,
+				category TEXT NOT NULL DEFAULT '',
+				tag TEXT NOT NULL DEFAULT '',
+				round_to INTEGER NOT NULL DEFAULT 1,
+				round_mode TEXT NOT NULL DEFAULT 'nearest',
+				note TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				scheduled_at TIMESTAMP NULL,
+				applied_at TIMESTAMP NULL,
+				reverted_at TIMESTAMP NULL,
+				admin_id INTEGER NULL REFERENCES admins(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_price_adjustments_due ON price_adjustments(status, scheduled_at);
+
+			CREATE TABLE IF NOT EXISTS price_adjustment_items (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				adjustment_id INTEGER NOT NULL REFERENCES price_adjustments(id),
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				variant_id INTEGER NULL REFERENCES product_variants(id),
+				old_price INTEGER NOT NULL,
+				new_price INTEGER NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_price_adjustment_items_adjustment ON price_adjustment_items(adjustment_id);
+		`,
+	},
+	{
+		Version:     12,
+		Description: "Add sale pricing to products and create product_prices table",
+		SQL: `
+			ALTER TABLE products ADD COLUMN compare_at_price INTEGER NULL;
+			ALTER TABLE products ADD COLUMN sale_price INTEGER NULL;
+			ALTER TABLE products ADD COLUMN sale_starts_at TIMESTAMP NULL;
+			ALTER TABLE products ADD COLUMN sale_ends_at TIMESTAMP NULL;
+
+			CREATE TABLE IF NOT EXISTS product_prices (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				price INTEGER NOT NULL,
+				compare_at_price INTEGER NULL,
+				sale_price INTEGER NULL,
+				sale_starts_at TIMESTAMP NULL,
+				sale_ends_at TIMESTAMP NULL,
+				changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices(product_id);
+
+			-- Current prices start the history of existing products
+			INSERT INTO product_prices (product_id, price, changed_at)
+			SELECT id, price, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP) FROM products;
+		`,
+	},
+	{
+		Version:     13,
+		Description: "Create promotions table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS promotions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				code TEXT NOT NULL DEFAULT '',
+				type TEXT NOT NULL,
+				value INTEGER NOT NULL DEFAULT 0,
+				buy_quantity INTEGER NOT NULL DEFAULT 0,
+				pay_quantity INTEGER NOT NULL DEFAULT 0,
+				scope TEXT NOT NULL DEFAULT 'all',
+				category TEXT NOT NULL DEFAULT '',
+				tag TEXT NOT NULL DEFAULT '',
+				product_ids TEXT NOT NULL DEFAULT '[]',
+				starts_at TIMESTAMP NULL,
+				ends_at TIMESTAMP NULL,
+				usage_limit INTEGER NULL,
+				usage_count INTEGER NOT NULL DEFAULT 0,
+				active INTEGER NOT NULL DEFAULT 1,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			-- Coupon codes are unique; automatic promotions have no code
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions(code) WHERE code != '';
+		`,
+	},
+	{
+		Version:     14,
+		Description: "Create carts and cart_items tables",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS carts (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				token TEXT UNIQUE NOT NULL,
+				coupon TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_carts_updated ON carts(updated_at);
+
+			CREATE TABLE IF NOT EXISTS cart_items (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				cart_id INTEGER NOT NULL REFERENCES carts(id),
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				name TEXT NOT NULL,
+				size TEXT NOT NULL DEFAULT '',
+				color TEXT NOT NULL DEFAULT '',
+				quantity INTEGER NOT NULL,
+				unit_price INTEGER NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (cart_id, product_id, size, color)
+			);
+		`,
+	},
+	{
+		Version:     15,
+		Description: "Create orders, order_items and order_status_history tables",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS orders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				status TEXT NOT NULL,
+				customer_name TEXT NOT NULL,
+				customer_phone TEXT NOT NULL DEFAULT '',
+				customer_email TEXT NOT NULL DEFAULT '',
+				address TEXT NOT NULL DEFAULT '',
+				city TEXT NOT NULL DEFAULT '',
+				province TEXT NOT NULL DEFAULT '',
+				postal_code TEXT NOT NULL DEFAULT '',
+				notes TEXT NOT NULL DEFAULT '',
+				coupon TEXT NOT NULL DEFAULT '',
+				item_count INTEGER NOT NULL,
+				subtotal INTEGER NOT NULL,
+				discount INTEGER NOT NULL DEFAULT 0,
+				total INTEGER NOT NULL,
+				admin_id INTEGER NULL REFERENCES admins(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				confirmed_at TIMESTAMP NULL,
+				paid_at TIMESTAMP NULL,
+				shipped_at TIMESTAMP NULL,
+				delivered_at TIMESTAMP NULL,
+				cancelled_at TIMESTAMP NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status, created_at);
+			CREATE INDEX IF NOT EXISTS idx_orders_created ON orders(created_at);
+
+			CREATE TABLE IF NOT EXISTS order_items (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				order_id INTEGER NOT NULL REFERENCES orders(id),
+				product_id INTEGER NOT NULL,
+				variant_id INTEGER NULL,
+				name TEXT NOT NULL,
+				sku TEXT NOT NULL DEFAULT '',
+				size TEXT NOT NULL DEFAULT '',
+				color TEXT NOT NULL DEFAULT '',
+				quantity INTEGER NOT NULL,
+				unit_price INTEGER NOT NULL,
+				subtotal INTEGER NOT NULL,
+				discount INTEGER NOT NULL DEFAULT 0,
+				total INTEGER NOT NULL,
+				promotion_id INTEGER NULL,
+				promotion_name TEXT NOT NULL DEFAULT ''
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_order_items_order ON order_items(order_id);
+
+			CREATE TABLE IF NOT EXISTS order_status_history (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				order_id INTEGER NOT NULL REFERENCES orders(id),
+				from_status TEXT NOT NULL DEFAULT '',
+				to_status TEXT NOT NULL,
+				note TEXT NOT NULL DEFAULT '',
+				admin_id INTEGER NULL REFERENCES admins(id),
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_order_status_history_order ON order_status_history(order_id);
+		`,
+	},
+	{
+		Version:     16,
+		Description: "Add reference and source to orders",
+		SQL: `
+			ALTER TABLE orders ADD COLUMN reference TEXT NOT NULL DEFAULT '';
+			ALTER TABLE orders ADD COLUMN source TEXT NOT NULL DEFAULT 'admin';
+
+			UPDATE orders SET reference = printf('P%05d', id) WHERE reference = '';
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_reference ON orders(reference);
+		`,
+	},
+	{
+		Version:     17,
+		Description: "Add payment_status to orders and create payments table",
+		SQL: `
+			ALTER TABLE orders ADD COLUMN payment_status TEXT NOT NULL DEFAULT '';
+
+			CREATE TABLE IF NOT EXISTS payments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				order_id INTEGER NOT NULL REFERENCES orders(id),
+				provider TEXT NOT NULL,
+				checkout_id TEXT NOT NULL,
+				checkout_url TEXT NOT NULL,
+				provider_payment_id TEXT NULL,
+				status TEXT NOT NULL,
+				status_detail TEXT NOT NULL DEFAULT '',
+				amount INTEGER NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_payments_order ON payments(order_id);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_payment ON payments(provider, provider_payment_id);
+		`,
+	},
+	{
+		Version:     18,
+		Description: "Create shipping_settings table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS shipping_settings (
+				id INTEGER PRIMARY KEY CHECK (id = 1),
+				settings TEXT NOT NULL,
+				admin_id INTEGER REFERENCES admins(id),
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+		`,
+	},
+	{
+		Version:     19,
+		Description: "Create stock_reservations table",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS stock_reservations (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				order_id INTEGER NOT NULL REFERENCES orders(id),
+				order_item_id INTEGER NOT NULL REFERENCES order_items(id),
+				product_id INTEGER NOT NULL REFERENCES products(id),
+				variant_id INTEGER NULL REFERENCES product_variants(id),
+				quantity INTEGER NOT NULL,
+				status TEXT NOT NULL,
+				movement_id INTEGER NULL REFERENCES inventory_movements(id),
+				expires_at TIMESTAMP NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_stock_reservations_order ON stock_reservations(order_id);
+			CREATE INDEX IF NOT EXISTS idx_stock_reservations_held ON stock_reservations(status, expires_at);
+		`,
+	},
+	{
+		Version:     20,
+		Description: "Create designs table and add designs to order_items",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS designs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				token TEXT NOT NULL UNIQUE,
+				template_id TEXT NOT NULL DEFAULT '',
+				template_name TEXT NOT NULL DEFAULT '',
+				shirt_color TEXT NOT NULL,
+				shirt_color_hex TEXT NOT NULL DEFAULT '',
+				text_layers TEXT NOT NULL,
+				fonts TEXT NOT NULL,
+				canvas TEXT NULL,
+				width INTEGER NOT NULL,
+				height INTEGER NOT NULL,
+				image_url TEXT NOT NULL,
+				image_filename TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			ALTER TABLE order_items ADD COLUMN design_id INTEGER NULL REFERENCES designs(id);
+			ALTER TABLE order_items ADD COLUMN design_token TEXT NOT NULL DEFAULT '';
+			ALTER TABLE order_items ADD COLUMN design_image_url TEXT NOT NULL DEFAULT '';
+			ALTER TABLE order_items ADD COLUMN design_surcharge INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		Version:     21,
+		Description: "Create design_templates and shirt_colors tables",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS design_templates (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				slug TEXT NOT NULL UNIQUE,
+				name TEXT NOT NULL,
+				category TEXT NOT NULL DEFAULT '',
+				description TEXT NOT NULL DEFAULT '',
+				tags TEXT NOT NULL DEFAULT '[]',
+				preview_url TEXT NOT NULL DEFAULT '',
+				preview_filename TEXT NOT NULL DEFAULT '',
+				print_url TEXT NOT NULL DEFAULT '',
+				print_filename TEXT NOT NULL DEFAULT '',
+				active INTEGER NOT NULL DEFAULT 1,
+				sort_order INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS shirt_colors (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+				hex TEXT NOT NULL,
+				mockup_url TEXT NOT NULL DEFAULT '',
+				mockup_filename TEXT NOT NULL DEFAULT '',
+				active INTEGER NOT NULL DEFAULT 1,
+				sort_order INTEGER NOT NULL DEFAULT 0,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+
+			-- The colors the designer offered before they were managed here
+			INSERT INTO shirt_colors (name, hex, sort_order) VALUES
+				('Blanco', '#FFFFFF', 1),
+				('Negro', '#000000', 2),
+				('Gris', '#808080', 3),
+				('Azul Navy', '#001F3F', 4);
+
+			ALTER TABLE designs ADD COLUMN shirt_color_id INTEGER NULL REFERENCES shirt_colors(id);
+		`,
+	},
+	{
+		Version:     22,
+		Description: "Add print and mockup renders to designs",
+		SQL: `
+			ALTER TABLE designs ADD COLUMN print_url TEXT NOT NULL DEFAULT '';
+			ALTER TABLE designs ADD COLUMN print_filename TEXT NOT NULL DEFAULT '';
+			ALTER TABLE designs ADD COLUMN print_width_cm REAL NOT NULL DEFAULT 0;
+			ALTER TABLE designs ADD COLUMN print_height_cm REAL NOT NULL DEFAULT 0;
+			ALTER TABLE designs ADD COLUMN mockup_url TEXT NOT NULL DEFAULT '';
+			ALTER TABLE designs ADD COLUMN mockup_filename TEXT NOT NULL DEFAULT '';
+			ALTER TABLE designs ADD COLUMN rendered_at DATETIME NULL;
+		`,
+	},
+	{
+		Version:     23,
+		Description: "Create customers and customer_addresses tables and link orders and designs to customers",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS customers (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				email TEXT NOT NULL UNIQUE COLLATE NOCASE,
+				password_hash TEXT NOT NULL,
+				name TEXT NOT NULL,
+				phone TEXT NOT NULL DEFAULT '',
+				email_verified_at DATETIME NULL,
+				verification_token_hash TEXT NOT NULL DEFAULT '',
+				verification_expires_at DATETIME NULL,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS customer_addresses (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+				label TEXT NOT NULL DEFAULT '',
+				recipient TEXT NOT NULL DEFAULT '',
+				phone TEXT NOT NULL DEFAULT '',
+				address TEXT NOT NULL,
+				city TEXT NOT NULL DEFAULT '',
+				province TEXT NOT NULL DEFAULT '',
+				postal_code TEXT NOT NULL DEFAULT '',
+				is_default BOOLEAN NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_customer_addresses_customer ON customer_addresses(customer_id);
+
+			ALTER TABLE orders ADD COLUMN customer_id INTEGER NULL REFERENCES customers(id);
+			CREATE INDEX IF NOT EXISTS idx_orders_customer ON orders(customer_id);
+
+			ALTER TABLE designs ADD COLUMN customer_id INTEGER NULL REFERENCES customers(id);
+			CREATE INDEX IF NOT EXISTS idx_designs_customer ON designs(customer_id);
+		`,
+	},
+	{
+		Version:     24,
+		Description: "Record variant stock in the inventory ledger",
+		SQL: `
+			CREATE INDEX IF NOT EXISTS idx_inventory_movements_variant ON inventory_movements(variant_id);
+
+			-- Variant stock set before it was recorded in the ledger
+			INSERT INTO inventory_movements (product_id, variant_id, type, quantity, reason, created_at)
+			SELECT v.product_id, v.id, 'adjustment', v.stock - COALESCE(SUM(m.quantity), 0), 'Opening variant stock', CURRENT_TIMESTAMP
+			FROM product_variants v
+			LEFT JOIN inventory_movements m ON m.variant_id = v.id
+			GROUP BY v.id
+			HAVING v.stock - COALESCE(SUM(m.quantity), 0) != 0;
+		`,
+	},
+	{
+		Version:     25,
+		Description: "Record sale and compare-at prices of price adjustment items",
+		SQL: `
+			ALTER TABLE price_adjustment_items ADD COLUMN old_compare_at_price INTEGER NULL;
+			ALTER TABLE price_adjustment_items ADD COLUMN new_compare_at_price INTEGER NULL;
+			ALTER TABLE price_adjustment_items ADD COLUMN old_sale_price INTEGER NULL;
+			ALTER TABLE price_adjustment_items ADD COLUMN new_sale_price INTEGER NULL;
+		`,
+	},
 }
 
 // Migrate runs all pending migrations
@@ -111,6 +670,14 @@ func Migrate(db *sql.DB) error {
 			return fmt.Errorf("failed to execute migration %d (%s): %w", migration.Version, migration.Description, err)
 		}
 
+		// Migrate existing data
+		if migration.Backfill != nil {
+			if err := migration.Backfill(tx); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to backfill migration %d (%s): %w", migration.Version, migration.Description, err)
+			}
+		}
+
 		// Record migration
 		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", migration.Version); err != nil {
 			tx.Rollback()
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sender sends plain text emails
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// SMTPSender sends emails through an SMTP server, authenticating when a
+// username is set
+type SMTPSender struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender creates a sender using the SMTP server at host:port
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Send sends an email
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	// The From header may have a name; the envelope takes the bare address
+	sender, err := netmail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender %q: %w", s.from, err)
+	}
+
+	headers := []string{
+		"From: " + s.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	msg := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+
+	if err := smtp.SendMail(s.addr, auth, sender.Address, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// LogSender logs emails instead of sending them, for development and
+// deployments without an SMTP server
+type LogSender struct{}
+
+// Send logs the recipient and subject of an email. The body is left out,
+// since it may hold a link that signs in or verifies the recipient.
+func (LogSender) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("Email to %s: %s (%d bytes not logged)", to, subject, len(body))
+	return nil
+}
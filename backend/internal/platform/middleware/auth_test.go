@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/auth"
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/platform/middleware"
+)
+
+// Both kinds of token come from the same secret, as they do in production
+const jwtSecret = "un-secreto-de-al-menos-32-caracteres"
+
+type authSuite struct {
+	suite.Suite
+	admin         http.Handler // Behind the admin middleware, as /api/admin/*
+	customer      http.Handler // Behind the customer middleware, as /api/customers/me
+	adminToken    string
+	customerToken string
+}
+
+func TestAuthSuite(t *testing.T) {
+	suite.Run(t, new(authSuite))
+}
+
+func (s *authSuite) SetupTest() {
+	adminJWT := auth.NewJWTManager(jwtSecret, 1)
+	customerJWT := customer.NewJWTManager(jwtSecret, 1)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	s.admin = middleware.AuthMiddleware(auth.NewService(nil, adminJWT))(ok)
+	s.customer = middleware.CustomerAuthMiddleware(customer.NewService(nil, customerJWT, nil, nil, nil, ""))(ok)
+
+	var err error
+	s.adminToken, _, err = adminJWT.Generate(&auth.Admin{ID: 1, Username: "admin", Role: "admin"})
+	s.Require().NoError(err)
+	s.customerToken, _, err = customerJWT.Generate(&customer.Customer{ID: 1, Email: "ana@example.com"})
+	s.Require().NoError(err)
+}
+
+// status returns the status of a request to a handler with a bearer token
+func (s *authSuite) status(h http.Handler, token string) int {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+// Routes and tokens of the cases
+const (
+	adminRoute    = "admin"
+	customerRoute = "customer"
+	noToken       = ""
+)
+
+var tokenCases = []struct {
+	name  string
+	route string
+	token string
+	want  int
+}{
+	{name: "Admin token on admin routes", route: adminRoute, token: adminRoute, want: http.StatusOK},
+	{name: "Customer token on admin routes", route: adminRoute, token: customerRoute, want: http.StatusUnauthorized},
+	{name: "No token on admin routes", route: adminRoute, token: noToken, want: http.StatusUnauthorized},
+	{name: "Customer token on customer routes", route: customerRoute, token: customerRoute, want: http.StatusOK},
+	{name: "Admin token on customer routes", route: customerRoute, token: adminRoute, want: http.StatusUnauthorized},
+	{name: "No token on customer routes", route: customerRoute, token: noToken, want: http.StatusUnauthorized},
+}
+
+func (s *authSuite) TestTokensOnlyOpenTheirOwnRoutes() {
+	handlers := map[string]http.Handler{adminRoute: s.admin, customerRoute: s.customer}
+	tokens := map[string]string{adminRoute: s.adminToken, customerRoute: s.customerToken}
+
+	for _, tc := range tokenCases {
+		s.Equal(tc.want, s.status(handlers[tc.route], tokens[tc.token]), tc.name)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/tomas/tienda-backend/internal/customer"
+	"github.com/tomas/tienda-backend/internal/platform/web"
+)
+
+const (
+	// CustomerClaimsContextKey is the context key for customer JWT claims
+	CustomerClaimsContextKey contextKey = "customer_claims"
+)
+
+// CustomerAuthMiddleware creates a middleware that requires a signed in customer
+func CustomerAuthMiddleware(customerService *customer.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Skip authentication for OPTIONS requests (CORS preflight)
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				web.RespondUnauthorized(w, "missing authorization header")
+				return
+			}
+
+			tokenString, ok := bearerToken(authHeader)
+			if !ok {
+				web.RespondUnauthorized(w, "invalid authorization header format")
+				return
+			}
+
+			claims, err := customerService.ValidateToken(tokenString)
+			if err != nil {
+				web.RespondUnauthorized(w, "invalid or expired token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), CustomerClaimsContextKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OptionalCustomerMiddleware creates a middleware for endpoints open to
+// guests that link what they create to the customer when one is signed in.
+// Missing or invalid tokens are ignored and the request goes on as a guest.
+func OptionalCustomerMiddleware(customerService *customer.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tokenString, ok := bearerToken(r.Header.Get("Authorization")); ok {
+				if claims, err := customerService.ValidateToken(tokenString); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), CustomerClaimsContextKey, claims))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetCustomerClaims retrieves customer JWT claims from request context
+func GetCustomerClaims(r *http.Request) (*customer.Claims, bool) {
+	claims, ok := r.Context().Value(CustomerClaimsContextKey).(*customer.Claims)
+	return claims, ok
+}
+
+// bearerToken extracts the token of a Bearer authorization header
+func bearerToken(authHeader string) (string, bool) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false
+	}
+	return parts[1], true
+}
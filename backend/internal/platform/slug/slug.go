@@ -0,0 +1,40 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// accentReplacer maps Spanish accented letters to their ASCII base letter
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ú", "U", "Ü", "U", "Ñ", "N",
+)
+
+// FoldAccents replaces Spanish accented characters with their ASCII base letter
+func FoldAccents(s string) string {
+	return accentReplacer.Replace(s)
+}
+
+// Make builds a URL-friendly slug such as "virgen-de-lujan" from text.
+// Accents are folded, letters lower-cased and any other run of characters
+// collapsed into a single hyphen.
+func Make(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, r := range FoldAccents(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package slug_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+type slugSuite struct {
+	suite.Suite
+}
+
+func TestSlugSuite(t *testing.T) {
+	suite.Run(t, new(slugSuite))
+}
+
+var makeCases = []struct {
+	name string
+	text string
+	want string
+}{
+	{name: "Accents and capitals", text: "Virgen María", want: "virgen-maria"},
+	{name: "Eñe and diaeresis", text: "Niño Pingüino", want: "nino-pinguino"},
+	{name: "Runs of symbols", text: "Remera -- 100% algodón!", want: "remera-100-algodon"},
+	{name: "Leading and trailing symbols", text: "  ¡San Expedito!  ", want: "san-expedito"},
+	{name: "Already a slug", text: "gauchito-gil-2", want: "gauchito-gil-2"},
+	{name: "Other scripts are dropped", text: "Remera 日本", want: "remera"},
+	{name: "Nothing usable", text: "¡¿?!", want: ""},
+}
+
+func (s *slugSuite) TestMake() {
+	for _, tc := range makeCases {
+		s.Equal(tc.want, slug.Make(tc.text), tc.name)
+	}
+}
+
+func (s *slugSuite) TestFoldAccents() {
+	s.Equal("Accion Unica Nandu pinguino", slug.FoldAccents("Acción Única Ñandú pingüino"))
+}
@@ -0,0 +1,27 @@
+// Package testdouble holds the test doubles shared by package tests: a
+// throwaway database and mocks of external dependencies.
+package testdouble
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+// NewDB opens a migrated SQLite database in a temporary directory that is
+// removed when the test ends
+func NewDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.Migrate(db.DB))
+
+	return db.DB
+}
@@ -0,0 +1,34 @@
+package testdouble
+
+import (
+	"context"
+	"sync"
+)
+
+// Email is an email handed to a Mailer
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is a mail.Sender that keeps the emails instead of sending them
+type Mailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+// Send records an email
+func (m *Mailer) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Email{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+// Sent returns the emails sent so far, oldest first
+func (m *Mailer) Sent() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.sent...)
+}
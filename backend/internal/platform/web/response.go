@@ -77,6 +77,11 @@ func RespondNotFound(w http.ResponseWriter, message string) {
 	RespondError(w, http.StatusNotFound, "not_found", message)
 }
 
+// RespondConflict sends a 409 Conflict error
+func RespondConflict(w http.ResponseWriter, message string) {
+	RespondError(w, http.StatusConflict, "conflict", message)
+}
+
 // RespondInternalError sends a 500 Internal Server Error
 func RespondInternalError(w http.ResponseWriter, message string) {
 	RespondError(w, http.StatusInternalServerError, "internal_error", message)
@@ -0,0 +1,133 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/xlsx"
+)
+
+// Parts of a workbook saved by a spreadsheet application, which keeps text
+// in a shared strings table instead of inline
+const (
+	sharedWorkbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Hoja1" sheetId="1" r:id="rId3"/></sheets>
+</workbook>`
+	sharedRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/hoja.xml"/>
+</Relationships>`
+	sharedStringsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si>
+<si><t>price</t></si>
+<si><r><t>Virgen </t></r><r><t>María</t></r></si>
+</sst>`
+	sharedSheetXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>550000</v></c></row>
+</sheetData></worksheet>`
+)
+
+type xlsxSuite struct {
+	suite.Suite
+}
+
+func TestXLSXSuite(t *testing.T) {
+	suite.Run(t, new(xlsxSuite))
+}
+
+// read reads back the rows of a workbook
+func (s *xlsxSuite) read(data []byte) [][]string {
+	rows, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+	s.Require().NoError(err)
+	return rows
+}
+
+// zipped builds an archive holding the given parts
+func (s *xlsxSuite) zipped(parts map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range parts {
+		f, err := zw.Create(name)
+		s.Require().NoError(err)
+		_, err = f.Write([]byte(body))
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(zw.Close())
+	return buf.Bytes()
+}
+
+func (s *xlsxSuite) TestWrittenRowsReadBack() {
+	wide := make([]interface{}, 30)
+	wide[0], wide[27], wide[29] = "A", "AB", int64(29)
+	var buf bytes.Buffer
+	w, err := xlsx.NewWriter(&buf, "Productos & Más")
+	s.Require().NoError(err)
+
+	for _, row := range [][]interface{}{
+		{"name", "price", "oversize"},
+		{"Remera <Lisa> & \"Negra\"", 550000, true},
+		{"  María  ", nil, ""},
+		wide,
+	} {
+		s.Require().NoError(w.WriteRow(row))
+	}
+	s.Require().NoError(w.Close())
+	rows := s.read(buf.Bytes())
+
+	s.Require().Len(rows, 4)
+	s.Equal([]string{"name", "price", "oversize"}, rows[0])
+	s.Equal([]string{"Remera <Lisa> & \"Negra\"", "550000", "true"}, rows[1])
+	// Empty trailing cells are not stored, so the row ends at the last value
+	s.Equal([]string{"  María  "}, rows[2])
+	s.Require().Len(rows[3], 30)
+	s.Equal("A", rows[3][0])
+	s.Equal("AB", rows[3][27])
+	s.Equal("29", rows[3][29])
+}
+
+func (s *xlsxSuite) TestReadsSharedStrings() {
+	data := s.zipped(map[string]string{
+		"xl/workbook.xml":            sharedWorkbookXML,
+		"xl/_rels/workbook.xml.rels": sharedRelsXML,
+		"xl/sharedStrings.xml":       sharedStringsXML,
+		"xl/worksheets/hoja.xml":     sharedSheetXML,
+	})
+
+	rows := s.read(data)
+
+	s.Equal([][]string{{"name", "price"}, {"Virgen María", "", "550000"}}, rows)
+}
+
+var invalidFileCases = []struct {
+	name  string
+	parts map[string]string // nil for data that is not an archive
+}{
+	{name: "Not an archive"},
+	{name: "Missing workbook", parts: map[string]string{"xl/worksheets/hoja.xml": sharedSheetXML}},
+	{name: "Missing sheet", parts: map[string]string{"xl/workbook.xml": sharedWorkbookXML, "xl/_rels/workbook.xml.rels": sharedRelsXML}},
+	{name: "Shared string out of range", parts: map[string]string{
+		"xl/workbook.xml":            sharedWorkbookXML,
+		"xl/_rels/workbook.xml.rels": sharedRelsXML,
+		"xl/worksheets/hoja.xml":     sharedSheetXML,
+	}},
+}
+
+func (s *xlsxSuite) TestRejectsInvalidFiles() {
+	for _, tc := range invalidFileCases {
+		data := []byte("name,price\nRemera,550000\n")
+		if tc.parts != nil {
+			data = s.zipped(tc.parts)
+		}
+
+		_, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+
+		s.ErrorIs(err, xlsx.ErrInvalidFile, tc.name)
+	}
+}
@@ -0,0 +1,226 @@
+package product
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+)
+
+// Price adjustment types
+const (
+	AdjustmentPercentage = "percentage"
+	AdjustmentFixed      = "fixed"
+)
+
+// Price rounding modes
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// Price adjustment statuses
+const (
+	AdjustmentScheduled = "scheduled"
+	AdjustmentApplied   = "applied"
+	AdjustmentReverted  = "reverted"
+	AdjustmentCancelled = "cancelled"
+	AdjustmentFailed    = "failed"
+)
+
+// PriceAdjustment is a mass price change over the catalog or a subset of it
+type PriceAdjustment struct {
+	ID          int64                 `json:"id"`
+	Type        string                `json:"type"`
+	Value       float64               `json:"value"` // Percent, or amount for fixed adjustments
+	Category    string                `json:"category,omitempty"`
+	Tag         string                `json:"tag,omitempty"`
+	RoundTo     int                   `json:"round_to"`
+	RoundMode   string                `json:"round_mode"`
+	Note        string                `json:"note,omitempty"`
+	Status      string                `json:"status"`
+	Error       string                `json:"error,omitempty"` // Why a scheduled adjustment failed
+	ScheduledAt *time.Time            `json:"scheduled_at,omitempty"`
+	AppliedAt   *time.Time            `json:"applied_at,omitempty"`
+	RevertedAt  *time.Time            `json:"reverted_at,omitempty"`
+	AdminID     *int64                `json:"admin_id,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	Items       []PriceAdjustmentItem `json:"items,omitempty"`
+}
+
+// PriceAdjustmentPreview lists the prices an adjustment would change
+type PriceAdjustmentPreview struct {
+	Products int                   `json:"products"` // Products with at least one price change
+	Items    []PriceAdjustmentItem `json:"items"`
+}
+
+// PriceAdjustmentItem is the price change of a single product, or of a
+// variant overriding the product price. The compare-at and sale prices of a
+// product are adjusted along with its price.
+type PriceAdjustmentItem struct {
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id,omitempty"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku,omitempty"`
+	OldPrice  int    `json:"old_price"`
+	NewPrice  int    `json:"new_price"`
+
+	OldCompareAtPrice *int `json:"old_compare_at_price,omitempty"`
+	NewCompareAtPrice *int `json:"new_compare_at_price,omitempty"`
+	OldSalePrice      *int `json:"old_sale_price,omitempty"`
+	NewSalePrice      *int `json:"new_sale_price,omitempty"`
+}
+
+// PriceAdjustmentInput represents input for previewing or creating a price adjustment
+type PriceAdjustmentInput struct {
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	Category    string     `json:"category,omitempty"` // Limits the adjustment to a category and its subcategories
+	Tag         string     `json:"tag,omitempty"`      // Limits the adjustment to products with the tag
+	RoundTo     int        `json:"round_to,omitempty"` // Round new prices to a multiple, e.g. 500
+	RoundMode   string     `json:"round_mode,omitempty"`
+	Note        string     `json:"note,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // Applied right away when empty or past
+
+	AdminID int64 `json:"-"` // Taken from the authenticated admin
+}
+
+// RevertResult reports the outcome of reverting a price adjustment
+type RevertResult struct {
+	Adjustment *PriceAdjustment      `json:"adjustment"`
+	Reverted   int                   `json:"reverted"`
+	Skipped    []PriceAdjustmentItem `json:"skipped"` // Products whose price changed again since
+}
+
+// Validate validates and normalizes price adjustment input
+func (input *PriceAdjustmentInput) Validate() error {
+	switch input.Type {
+	case AdjustmentPercentage:
+		if input.Value <= -100 {
+			return ErrInvalidInput("percentage must be greater than -100")
+		}
+	case AdjustmentFixed:
+		if input.Value != math.Trunc(input.Value) {
+			return ErrInvalidInput("fixed amount must be a whole number")
+		}
+	default:
+		return ErrInvalidInput("type must be percentage or fixed")
+	}
+	if input.Value == 0 {
+		return ErrInvalidInput("value cannot be 0")
+	}
+
+	if input.RoundTo < 0 {
+		return ErrInvalidInput("round_to cannot be negative")
+	}
+	if input.RoundTo == 0 {
+		input.RoundTo = 1
+	}
+
+	switch input.RoundMode {
+	case "":
+		input.RoundMode = RoundNearest
+	case RoundNearest, RoundUp, RoundDown:
+	default:
+		return ErrInvalidInput("round_mode must be nearest, up or down")
+	}
+
+	input.Category = strings.TrimSpace(input.Category)
+	input.Tag = strings.TrimSpace(input.Tag)
+
+	return nil
+}
+
+// filters returns the listing filters selecting the products to adjust
+func (input *PriceAdjustmentInput) filters() GetAllFilters {
+	filters := GetAllFilters{Category: input.Category}
+	if input.Tag != "" {
+		filters.Tags = []string{input.Tag}
+	}
+	return filters
+}
+
+// input returns the settings of a stored adjustment
+func (a *PriceAdjustment) input() PriceAdjustmentInput {
+	return PriceAdjustmentInput{
+		Type:      a.Type,
+		Value:     a.Value,
+		Category:  a.Category,
+		Tag:       a.Tag,
+		RoundTo:   a.RoundTo,
+		RoundMode: a.RoundMode,
+	}
+}
+
+// adjustPrice computes the new price of a product
+func (input *PriceAdjustmentInput) adjustPrice(price int) int {
+	adjusted := float64(price) + input.Value
+	if input.Type == AdjustmentPercentage {
+		adjusted = float64(price) * (1 + input.Value/100)
+	}
+
+	step := float64(input.RoundTo)
+	switch input.RoundMode {
+	case RoundUp:
+		adjusted = math.Ceil(adjusted/step) * step
+	case RoundDown:
+		adjusted = math.Floor(adjusted/step) * step
+	default:
+		adjusted = math.Round(adjusted/step) * step
+	}
+
+	return int(adjusted)
+}
+
+// adjustOptionalPrice computes the new value of a price that may be unset
+func (input *PriceAdjustmentInput) adjustOptionalPrice(price *int) *int {
+	if price == nil {
+		return nil
+	}
+	adjusted := input.adjustPrice(*price)
+	return &adjusted
+}
+
+// scanPriceAdjustment scans a database row into a PriceAdjustment
+func scanPriceAdjustment(row interface{ Scan(...interface{}) error }) (*PriceAdjustment, error) {
+	var a PriceAdjustment
+	var scheduledAt, appliedAt, revertedAt sql.NullTime
+	var adminID sql.NullInt64
+
+	err := row.Scan(
+		&a.ID,
+		&a.Type,
+		&a.Value,
+		&a.Category,
+		&a.Tag,
+		&a.RoundTo,
+		&a.RoundMode,
+		&a.Note,
+		&a.Status,
+		&a.Error,
+		&scheduledAt,
+		&appliedAt,
+		&revertedAt,
+		&adminID,
+		&a.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduledAt.Valid {
+		a.ScheduledAt = &scheduledAt.Time
+	}
+	if appliedAt.Valid {
+		a.AppliedAt = &appliedAt.Time
+	}
+	if revertedAt.Valid {
+		a.RevertedAt = &revertedAt.Time
+	}
+	if adminID.Valid {
+		a.AdminID = &adminID.Int64
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,223 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	adjustedPrice        = 550000
+	adjustedVariantPrice = 600000
+	untouchedPrice       = 300000
+	adjustmentTag        = "verano"
+	adjustmentRoundTo    = 1000
+	adjustmentAdminID    = int64(0)
+)
+
+type adjustmentSuite struct {
+	suite.Suite
+	ctx       context.Context
+	svc       *product.Service
+	adjusted  *product.Product
+	untouched *product.Product
+}
+
+func TestAdjustmentSuite(t *testing.T) {
+	suite.Run(t, new(adjustmentSuite))
+}
+
+func (s *adjustmentSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	variantPrice := adjustedVariantPrice
+	var err error
+	s.adjusted, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     "Remera Estampada",
+		Price:    adjustedPrice,
+		Tags:     []string{adjustmentTag},
+		Sizes:    []string{"M", "L"},
+		Variants: []product.CreateVariantInput{{Size: "M"}, {Size: "L", Price: &variantPrice}},
+	})
+	s.Require().NoError(err)
+	s.untouched, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: "Gorra", Price: untouchedPrice})
+	s.Require().NoError(err)
+}
+
+// prices returns the price of the adjusted product, of its variant with a
+// price override and of the product outside the adjustment
+func (s *adjustmentSuite) prices() (int, int, int) {
+	adjusted, err := s.svc.GetProduct(s.ctx, s.adjusted.ID)
+	s.Require().NoError(err)
+	untouched, err := s.svc.GetProduct(s.ctx, s.untouched.ID)
+	s.Require().NoError(err)
+	return adjusted.Price, *adjusted.Variants[1].Price, untouched.Price
+}
+
+var adjustmentValidationCases = []struct {
+	name  string
+	input product.PriceAdjustmentInput
+}{
+	{name: "Unknown type", input: product.PriceAdjustmentInput{Type: "ratio", Value: 10}},
+	{name: "Zero value", input: product.PriceAdjustmentInput{Type: product.AdjustmentPercentage}},
+	{name: "Percentage of -100", input: product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: -100}},
+	{name: "Fractional fixed amount", input: product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 10.5}},
+	{name: "Negative rounding", input: product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 100, RoundTo: -1}},
+	{name: "Unknown rounding mode", input: product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 100, RoundMode: "half"}},
+	{name: "Price below zero", input: product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: -adjustedVariantPrice}},
+}
+
+func (s *adjustmentSuite) TestAdjustmentValidation() {
+	for _, tc := range adjustmentValidationCases {
+		_, err := s.svc.CreatePriceAdjustment(s.ctx, tc.input)
+		adjustments, listErr := s.svc.ListPriceAdjustments(s.ctx)
+
+		s.ErrorIs(err, product.ErrValidation, tc.name)
+		s.Require().NoError(listErr)
+		s.Empty(adjustments, tc.name)
+	}
+}
+
+var adjustmentApplyCases = []struct {
+	name             string
+	input            product.PriceAdjustmentInput
+	wantPrice        int
+	wantVariantPrice int
+	wantUntouched    int
+}{
+	{
+		name:             "Percentage rounded to nearest",
+		input:            product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: 7, RoundTo: adjustmentRoundTo},
+		wantPrice:        589000,
+		wantVariantPrice: 642000,
+		wantUntouched:    321000,
+	},
+	{
+		name:             "Percentage rounded up",
+		input:            product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: 7, RoundTo: adjustmentRoundTo, RoundMode: product.RoundUp},
+		wantPrice:        589000,
+		wantVariantPrice: 642000,
+		wantUntouched:    321000,
+	},
+	{
+		name:             "Discount rounded down",
+		input:            product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: -15, RoundTo: adjustmentRoundTo, RoundMode: product.RoundDown},
+		wantPrice:        467000,
+		wantVariantPrice: 510000,
+		wantUntouched:    255000,
+	},
+	{
+		name:             "Fixed amount by tag",
+		input:            product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 25000, Tag: adjustmentTag},
+		wantPrice:        575000,
+		wantVariantPrice: 625000,
+		wantUntouched:    untouchedPrice,
+	},
+}
+
+func (s *adjustmentSuite) TestApplyAndRevert() {
+	for _, tc := range adjustmentApplyCases {
+		a, err := s.svc.CreatePriceAdjustment(s.ctx, tc.input)
+		s.Require().NoError(err, tc.name)
+		price, variantPrice, untouched := s.prices()
+
+		result, revertErr := s.svc.RevertPriceAdjustment(s.ctx, a.ID, adjustmentAdminID)
+		revertedPrice, revertedVariantPrice, revertedUntouched := s.prices()
+
+		s.Equal(product.AdjustmentApplied, a.Status, tc.name)
+		s.Equal(tc.wantPrice, price, tc.name)
+		s.Equal(tc.wantVariantPrice, variantPrice, tc.name)
+		s.Equal(tc.wantUntouched, untouched, tc.name)
+		s.Require().NoError(revertErr, tc.name)
+		s.Equal(product.AdjustmentReverted, result.Adjustment.Status, tc.name)
+		s.Len(a.Items, result.Reverted, tc.name)
+		s.Empty(result.Skipped, tc.name)
+		s.Equal(adjustedPrice, revertedPrice, tc.name)
+		s.Equal(adjustedVariantPrice, revertedVariantPrice, tc.name)
+		s.Equal(untouchedPrice, revertedUntouched, tc.name)
+	}
+}
+
+func (s *adjustmentSuite) TestAdjustedVariantCannotBeDeleted() {
+	_, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000, Tag: adjustmentTag})
+	s.Require().NoError(err)
+
+	err = s.svc.DeleteVariant(s.ctx, s.adjusted.ID, s.adjusted.Variants[1].ID)
+	variants, getErr := s.svc.GetVariants(s.ctx, s.adjusted.ID)
+
+	s.ErrorIs(err, product.ErrVariantInUse)
+	s.Require().NoError(getErr)
+	s.Len(variants, 2)
+}
+
+func (s *adjustmentSuite) TestPreviewChangesNothing() {
+	preview, err := s.svc.PreviewPriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000, Tag: adjustmentTag})
+	price, variantPrice, _ := s.prices()
+
+	s.Require().NoError(err)
+	s.Equal(1, preview.Products)
+	s.Len(preview.Items, 2)
+	s.Equal(adjustedPrice+1000, preview.Items[0].NewPrice)
+	s.Equal(adjustedPrice, price)
+	s.Equal(adjustedVariantPrice, variantPrice)
+}
+
+func (s *adjustmentSuite) TestRevertSkipsPricesEditedSince() {
+	a, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000, Tag: adjustmentTag})
+	s.Require().NoError(err)
+	edited := adjustedPrice + 5000
+	_, err = s.svc.UpdateProduct(s.ctx, s.adjusted.ID, product.UpdateProductInput{Price: &edited})
+	s.Require().NoError(err)
+
+	result, err := s.svc.RevertPriceAdjustment(s.ctx, a.ID, adjustmentAdminID)
+	price, variantPrice, _ := s.prices()
+	_, againErr := s.svc.RevertPriceAdjustment(s.ctx, a.ID, adjustmentAdminID)
+
+	s.Require().NoError(err)
+	s.Equal(1, result.Reverted)
+	s.Len(result.Skipped, 1)
+	s.Equal(edited, price)
+	s.Equal(adjustedVariantPrice, variantPrice)
+	s.ErrorIs(againErr, product.ErrAdjustmentStatus)
+}
+
+func (s *adjustmentSuite) TestScheduledAdjustment() {
+	scheduledAt := time.Now().Add(time.Hour)
+
+	a, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000, ScheduledAt: &scheduledAt})
+	s.Require().NoError(err)
+	due, dueErr := s.svc.ApplyDuePriceAdjustments(s.ctx)
+	price, _, _ := s.prices()
+	_, revertErr := s.svc.RevertPriceAdjustment(s.ctx, a.ID, adjustmentAdminID)
+	cancelled, cancelErr := s.svc.CancelPriceAdjustment(s.ctx, a.ID)
+	_, cancelAgainErr := s.svc.CancelPriceAdjustment(s.ctx, a.ID)
+
+	s.Equal(product.AdjustmentScheduled, a.Status)
+	s.Require().NoError(dueErr)
+	s.Empty(due)
+	s.Equal(adjustedPrice, price)
+	s.ErrorIs(revertErr, product.ErrAdjustmentStatus)
+	s.Require().NoError(cancelErr)
+	s.Equal(product.AdjustmentCancelled, cancelled.Status)
+	s.ErrorIs(cancelAgainErr, product.ErrAdjustmentStatus)
+}
+
+func (s *adjustmentSuite) TestAdjustmentRecordsRevisions() {
+	_, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000, Tag: adjustmentTag})
+	s.Require().NoError(err)
+
+	adjusted, adjustedErr := s.svc.GetRevisions(s.ctx, s.adjusted.ID)
+	untouched, untouchedErr := s.svc.GetRevisions(s.ctx, s.untouched.ID)
+
+	s.Require().NoError(adjustedErr)
+	s.Require().NoError(untouchedErr)
+	s.Len(adjusted, 2)
+	s.Equal(adjustedPrice+1000, adjusted[0].Snapshot.Price)
+	s.Len(untouched, 1)
+}
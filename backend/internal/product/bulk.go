@@ -0,0 +1,138 @@
+package product
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bulk operations
+const (
+	BulkUpdate    = "update"
+	BulkDelete    = "delete"
+	BulkRestore   = "restore"
+	BulkAddTag    = "add_tag"
+	BulkRemoveTag = "remove_tag"
+)
+
+// MaxBulkItems limits the number of products a bulk operation may touch
+const MaxBulkItems = 1000
+
+// BulkInput represents a bulk operation on a list of products or on every
+// product matching a filter
+type BulkInput struct {
+	IDs       []int64             `json:"ids,omitempty"`
+	Filter    *BulkFilter         `json:"filter,omitempty"`
+	Operation string              `json:"operation"`
+	Update    *UpdateProductInput `json:"update,omitempty"` // For update
+	Tag       string              `json:"tag,omitempty"`    // For add_tag and remove_tag
+
+	AdminID int64 `json:"-"` // Taken from the authenticated admin
+}
+
+// BulkFilter selects products like the GET /api/products query parameters
+type BulkFilter struct {
+	Search      string   `json:"search,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Gender      string   `json:"gender,omitempty"`
+	Oversize    *bool    `json:"oversize,omitempty"`
+	Featured    *bool    `json:"featured,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Sizes       []string `json:"sizes,omitempty"`
+	Colors      []string `json:"colors,omitempty"`
+	MinPrice    int      `json:"min_price,omitempty"`
+	MaxPrice    int      `json:"max_price,omitempty"`
+	InStockOnly bool     `json:"in_stock,omitempty"`
+}
+
+// BulkResult reports the outcome of a bulk operation
+type BulkResult struct {
+	Operation string           `json:"operation"`
+	Applied   bool             `json:"applied"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// BulkItemResult is the outcome for a single product
+type BulkItemResult struct {
+	ID      int64    `json:"id"`
+	Status  string   `json:"status"` // ok or error
+	Error   string   `json:"error,omitempty"`
+	Product *Product `json:"product,omitempty"`
+}
+
+// Validate validates bulk operation input
+func (input *BulkInput) Validate() error {
+	if (len(input.IDs) > 0) == (input.Filter != nil) {
+		return ErrInvalidInput("provide either ids or filter")
+	}
+	if len(input.IDs) > MaxBulkItems {
+		return ErrInvalidInput(fmt.Sprintf("at most %d products per bulk operation", MaxBulkItems))
+	}
+
+	switch input.Operation {
+	case BulkUpdate:
+		if input.Update == nil {
+			return ErrInvalidInput("update requires the fields to change")
+		}
+		if input.Update.Name != nil || input.Update.Slug != nil {
+			return ErrInvalidInput("name and slug cannot be changed in bulk")
+		}
+		if input.Update.Price != nil && *input.Update.Price <= 0 {
+			return ErrInvalidInput("price must be greater than 0")
+		}
+		if err := validatePricing(input.Update.CompareAtPrice, input.Update.SalePrice, input.Update.SaleStartsAt, input.Update.SaleEndsAt); err != nil {
+			return err
+		}
+	case BulkDelete:
+	case BulkRestore:
+		if input.Filter != nil {
+			return ErrInvalidInput("restore requires ids")
+		}
+	case BulkAddTag, BulkRemoveTag:
+		input.Tag = strings.TrimSpace(input.Tag)
+		if input.Tag == "" {
+			return ErrInvalidInput("tag is required")
+		}
+	default:
+		return ErrInvalidInput("operation must be update, delete, restore, add_tag or remove_tag")
+	}
+
+	return nil
+}
+
+// filters converts the bulk filter into listing filters
+func (f *BulkFilter) filters() GetAllFilters {
+	return GetAllFilters{
+		Search:      f.Search,
+		Category:    f.Category,
+		Gender:      f.Gender,
+		Oversize:    f.Oversize,
+		Featured:    f.Featured,
+		Tags:        f.Tags,
+		Sizes:       f.Sizes,
+		Colors:      f.Colors,
+		MinPrice:    f.MinPrice,
+		MaxPrice:    f.MaxPrice,
+		InStockOnly: f.InStockOnly,
+	}
+}
+
+// withTag returns tags with tag added or removed, keeping the order
+func withTag(tags []string, tag string, add bool) []string {
+	result := []string{}
+	found := false
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			found = true
+			if !add {
+				continue
+			}
+		}
+		result = append(result, t)
+	}
+	if add && !found {
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	bulkPrice     = 550000
+	bulkNewPrice  = 480000
+	bulkTag       = "verano"
+	bulkMissingID = int64(9999)
+)
+
+type bulkSuite struct {
+	suite.Suite
+	ctx   context.Context
+	svc   *product.Service
+	first *product.Product
+	other *product.Product
+}
+
+func TestBulkSuite(t *testing.T) {
+	suite.Run(t, new(bulkSuite))
+}
+
+func (s *bulkSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	var err error
+	s.first, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera Lisa", Price: bulkPrice, Tags: []string{bulkTag}})
+	s.Require().NoError(err)
+	s.other, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: "Buzo Canguro", Price: bulkPrice})
+	s.Require().NoError(err)
+}
+
+// price returns the stored price of a product
+func (s *bulkSuite) price(id int64) int {
+	p, err := s.svc.GetProduct(s.ctx, id)
+	s.Require().NoError(err)
+	return p.Price
+}
+
+var bulkValidationCases = []struct {
+	name  string
+	input product.BulkInput
+}{
+	{name: "Neither ids nor filter", input: product.BulkInput{Operation: product.BulkDelete}},
+	{name: "Both ids and filter", input: product.BulkInput{IDs: []int64{1}, Filter: &product.BulkFilter{}, Operation: product.BulkDelete}},
+	{name: "Unknown operation", input: product.BulkInput{IDs: []int64{1}, Operation: "archive"}},
+	{name: "Update without fields", input: product.BulkInput{IDs: []int64{1}, Operation: product.BulkUpdate}},
+	{name: "Restore by filter", input: product.BulkInput{Filter: &product.BulkFilter{}, Operation: product.BulkRestore}},
+	{name: "Blank tag", input: product.BulkInput{IDs: []int64{1}, Operation: product.BulkAddTag, Tag: " "}},
+}
+
+func (s *bulkSuite) TestBulkValidation() {
+	for _, tc := range bulkValidationCases {
+		_, err := s.svc.BulkUpdate(s.ctx, tc.input)
+
+		s.ErrorIs(err, product.ErrValidation, tc.name)
+	}
+}
+
+func (s *bulkSuite) TestBulkUpdateApplied() {
+	price := bulkNewPrice
+
+	result, err := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID, s.other.ID, s.first.ID},
+		Operation: product.BulkUpdate,
+		Update:    &product.UpdateProductInput{Price: &price},
+	})
+
+	s.Require().NoError(err)
+	s.True(result.Applied)
+	s.Equal(2, result.Succeeded)
+	s.Equal(bulkNewPrice, result.Results[0].Product.Price)
+	s.Equal(bulkNewPrice, s.price(s.first.ID))
+	s.Equal(bulkNewPrice, s.price(s.other.ID))
+}
+
+func (s *bulkSuite) TestBulkIsAllOrNothing() {
+	price := bulkNewPrice
+
+	result, err := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID, bulkMissingID, s.other.ID},
+		Operation: product.BulkUpdate,
+		Update:    &product.UpdateProductInput{Price: &price},
+	})
+	revisions, revisionsErr := s.svc.GetRevisions(s.ctx, s.first.ID)
+
+	s.Require().NoError(err)
+	s.Require().NoError(revisionsErr)
+	s.False(result.Applied)
+	s.Equal(2, result.Succeeded)
+	s.Equal(1, result.Failed)
+	s.Equal("error", result.Results[1].Status)
+	s.Nil(result.Results[0].Product)
+	s.Equal(bulkPrice, s.price(s.first.ID))
+	s.Equal(bulkPrice, s.price(s.other.ID))
+	s.Len(revisions, 1)
+}
+
+func (s *bulkSuite) TestBulkDeleteByFilterAndRestore() {
+	deleted, deleteErr := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		Filter:    &product.BulkFilter{Tags: []string{bulkTag}},
+		Operation: product.BulkDelete,
+	})
+	_, getErr := s.svc.GetProduct(s.ctx, s.first.ID)
+	restored, restoreErr := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID},
+		Operation: product.BulkRestore,
+	})
+
+	s.Require().NoError(deleteErr)
+	s.Require().NoError(restoreErr)
+	s.True(deleted.Applied)
+	s.Equal(1, deleted.Succeeded)
+	s.ErrorIs(getErr, product.ErrNotFound)
+	s.True(restored.Applied)
+	s.Equal(s.first.ID, restored.Results[0].Product.ID)
+	s.Equal(bulkPrice, s.price(s.other.ID))
+}
+
+func (s *bulkSuite) TestBulkRestoreOfProductNotInTrash() {
+	result, err := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID},
+		Operation: product.BulkRestore,
+	})
+
+	s.Require().NoError(err)
+	s.False(result.Applied)
+	s.Equal(1, result.Failed)
+}
+
+func (s *bulkSuite) TestBulkTags() {
+	added, addErr := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID, s.other.ID},
+		Operation: product.BulkAddTag,
+		Tag:       bulkTag,
+	})
+	removed, removeErr := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.first.ID},
+		Operation: product.BulkRemoveTag,
+		Tag:       bulkTag,
+	})
+
+	s.Require().NoError(addErr)
+	s.Require().NoError(removeErr)
+	s.Equal([]string{bulkTag}, added.Results[0].Product.Tags)
+	s.Equal([]string{bulkTag}, added.Results[1].Product.Tags)
+	s.Empty(removed.Results[0].Product.Tags)
+}
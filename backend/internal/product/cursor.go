@@ -0,0 +1,67 @@
+package product
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// CursorPage is a page of products in keyset pagination mode
+type CursorPage struct {
+	Products   []*Product
+	NextCursor string
+	PrevCursor string
+}
+
+// cursor marks a position in a sorted product listing. It is handed to
+// clients as an opaque base64 token.
+type cursor struct {
+	Sort     string      `json:"s"`
+	Order    string      `json:"o"`
+	Value    interface{} `json:"v"` // sort column value of the boundary row
+	ID       int64       `json:"id"`
+	Backward bool        `json:"b,omitempty"`
+}
+
+// encode serializes the cursor into an opaque token
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a token and checks it was issued for the same sort
+func decodeCursor(token, sort, order string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort || c.Order != order || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// productOrder resolves the sort key, SQL expression and direction of a listing.
+// Searches are ranked by relevance unless another sort is requested.
+func productOrder(filters GetAllFilters, searching bool) (sort, expr, order string) {
+	order = "DESC"
+	if filters.Order == "asc" {
+		order = "ASC"
+	}
+
+	switch {
+	case searching && (filters.Sort == "" || filters.Sort == "relevance"):
+		return "relevance", "fts.score", "ASC"
+	case filters.Sort == "name":
+		return "name", "products.name", order
+	case filters.Sort == "price":
+		return "price", "(" + effectivePriceExpr + ")", order
+	default:
+		return "created_at", "CAST(products.created_at AS TEXT)", order
+	}
+}
@@ -0,0 +1,149 @@
+package product_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	cursorProducts = 7
+	cursorPageSize = 3
+	cursorPrice    = 550000 // Shared by every product so ties fall back to the ID
+)
+
+type cursorSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+}
+
+func TestCursorSuite(t *testing.T) {
+	suite.Run(t, new(cursorSuite))
+}
+
+func (s *cursorSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	for i := 1; i <= cursorProducts; i++ {
+		s.create(fmt.Sprintf("Remera %d", i), cursorPrice)
+	}
+}
+
+// create creates a product with a price
+func (s *cursorSuite) create(name string, price int) *product.Product {
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: name, Price: price})
+	s.Require().NoError(err)
+	return p
+}
+
+// page fetches a page and returns it with the names of its products
+func (s *cursorSuite) page(filters product.GetAllFilters, token string) (*product.CursorPage, []string) {
+	page, err := s.svc.GetProductsByCursor(s.ctx, filters, token)
+	s.Require().NoError(err)
+
+	names := []string{}
+	for _, p := range page.Products {
+		names = append(names, p.Name)
+	}
+	return page, names
+}
+
+// scroll walks every page, calling insert once after the first page, and
+// returns the names seen in order
+func (s *cursorSuite) scroll(filters product.GetAllFilters, insert func()) []string {
+	seen := []string{}
+	token := ""
+	for pages := 0; pages <= cursorProducts+1; pages++ {
+		page, names := s.page(filters, token)
+		seen = append(seen, names...)
+		if pages == 0 {
+			insert()
+		}
+		if page.NextCursor == "" {
+			return seen
+		}
+		token = page.NextCursor
+	}
+	s.FailNow("scroll did not end")
+	return nil
+}
+
+var insertCases = []struct {
+	name    string
+	filters product.GetAllFilters
+	insert  string // Product inserted once the first page is read
+	price   int
+	want    []string
+}{
+	{
+		name:    "Newest first, insert lands on an already read page",
+		filters: product.GetAllFilters{Limit: cursorPageSize},
+		insert:  "Remera Nueva",
+		price:   cursorPrice,
+		want:    []string{"Remera 7", "Remera 6", "Remera 5", "Remera 4", "Remera 3", "Remera 2", "Remera 1"},
+	},
+	{
+		name:    "Oldest first, insert lands on a page yet to read",
+		filters: product.GetAllFilters{Sort: "created_at", Order: "asc", Limit: cursorPageSize},
+		insert:  "Remera Nueva",
+		price:   cursorPrice,
+		want:    []string{"Remera 1", "Remera 2", "Remera 3", "Remera 4", "Remera 5", "Remera 6", "Remera 7", "Remera Nueva"},
+	},
+	{
+		name:    "Price ascending, cheaper insert lands on an already read page",
+		filters: product.GetAllFilters{Sort: "price", Order: "asc", Limit: cursorPageSize},
+		insert:  "Remera Barata",
+		price:   100000,
+		want:    []string{"Remera 1", "Remera 2", "Remera 3", "Remera 4", "Remera 5", "Remera 6", "Remera 7"},
+	},
+	{
+		name:    "Price ascending, tied insert sorts after by ID",
+		filters: product.GetAllFilters{Sort: "price", Order: "asc", Limit: cursorPageSize},
+		insert:  "Remera Empatada",
+		price:   cursorPrice,
+		want:    []string{"Remera 1", "Remera 2", "Remera 3", "Remera 4", "Remera 5", "Remera 6", "Remera 7", "Remera Empatada"},
+	},
+}
+
+func (s *cursorSuite) TestInsertMidScrollNeitherSkipsNorRepeats() {
+	for _, tc := range insertCases {
+		s.SetupTest()
+
+		seen := s.scroll(tc.filters, func() { s.create(tc.insert, tc.price) })
+
+		s.Equal(tc.want, seen, tc.name)
+	}
+}
+
+func (s *cursorSuite) TestPrevCursorReturnsThePreviousPage() {
+	filters := product.GetAllFilters{Sort: "price", Order: "asc", Limit: cursorPageSize}
+
+	first, firstNames := s.page(filters, "")
+	second, secondNames := s.page(filters, first.NextCursor)
+	s.create("Remera Barata", 100000)
+	back, backNames := s.page(filters, second.PrevCursor)
+
+	s.Empty(first.PrevCursor)
+	s.Equal([]string{"Remera 4", "Remera 5", "Remera 6"}, secondNames)
+	s.Equal(firstNames, backNames)
+	// The cheaper product now precedes the first page, so there is more to go back to
+	s.NotEmpty(back.PrevCursor)
+	s.NotEmpty(back.NextCursor)
+}
+
+func (s *cursorSuite) TestCursorBelongsToItsSort() {
+	first, _ := s.page(product.GetAllFilters{Sort: "price", Order: "asc", Limit: cursorPageSize}, "")
+
+	_, otherSortErr := s.svc.GetProductsByCursor(s.ctx, product.GetAllFilters{Sort: "name", Order: "asc"}, first.NextCursor)
+	_, garbageErr := s.svc.GetProductsByCursor(s.ctx, product.GetAllFilters{}, "not-a-cursor")
+
+	s.ErrorIs(otherSortErr, product.ErrInvalidCursor)
+	s.ErrorIs(garbageErr, product.ErrInvalidCursor)
+}
@@ -8,9 +8,43 @@ import (
 var (
 	// ErrNotFound indicates a product was not found
 	ErrNotFound = errors.New("product not found")
+
+	// ErrInvalidCursor indicates a pagination cursor is malformed or belongs to another sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrVariantNotFound indicates a product variant was not found
+	ErrVariantNotFound = errors.New("variant not found")
+
+	// ErrDuplicateVariant indicates the SKU or size/color combination already exists
+	ErrDuplicateVariant = errors.New("variant with the same sku or size/color already exists")
+
+	// ErrVariantInUse indicates a variant cannot be deleted because stock reservations or price adjustments refer to it
+	ErrVariantInUse = errors.New("variant is referenced by orders or price adjustments")
+
+	// ErrRevisionNotFound indicates a product revision was not found
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	// ErrAdjustmentNotFound indicates a price adjustment was not found
+	ErrAdjustmentNotFound = errors.New("price adjustment not found")
+
+	// ErrAdjustmentStatus indicates a price adjustment cannot be applied, reverted or cancelled in its current status
+	ErrAdjustmentStatus = errors.New("price adjustment status does not allow this operation")
 	
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
 	// ErrInvalidInput indicates invalid input data
 	ErrInvalidInput = func(msg string) error {
-		return fmt.Errorf("invalid input: %s", msg)
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
 	}
 )
+
+// SlugMovedError indicates a product was requested by a previous slug
+type SlugMovedError struct {
+	Slug string // current slug of the product
+}
+
+// Error implements the error interface
+func (e *SlugMovedError) Error() string {
+	return "product moved to slug " + e.Slug
+}
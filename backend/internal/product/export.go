@@ -0,0 +1,153 @@
+package product
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/xlsx"
+)
+
+// FormatJSONL is the JSON Lines export format, one object per row
+const FormatJSONL = "jsonl"
+
+// DeletedColumn is added to exports that include soft-deleted products.
+// The import skips rows where it is set.
+const DeletedColumn = "deleted_at"
+
+// rowWriter writes export rows in one file format
+type rowWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// newRowWriter creates the writer for format and writes the header row
+func newRowWriter(w io.Writer, format string, columns []string) (rowWriter, error) {
+	var rw rowWriter
+	switch format {
+	case FormatCSV:
+		rw = &csvRowWriter{w: csv.NewWriter(w)}
+	case FormatJSONL:
+		return &jsonlRowWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	case FormatXLSX:
+		xw, err := xlsx.NewWriter(w, "Productos")
+		if err != nil {
+			return nil, err
+		}
+		rw = xw
+	default:
+		return nil, ErrInvalidInput("unsupported format, use csv, jsonl or xlsx")
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := rw.WriteRow(header); err != nil {
+		return nil, err
+	}
+
+	return rw, nil
+}
+
+// exportRows lays out a product as import rows: one per variant, with the
+// product columns on the first row only and the slug on every row
+func exportRows(p *Product, variants []*Variant, includeDeleted bool) [][]interface{} {
+	deletedAt := optionalTime(p.DeletedAt)
+
+	product := []interface{}{
+		p.Slug, p.Name, p.Description, p.Price, p.Category,
+		strings.Join(p.Images, ListSeparator),
+		strings.Join(p.Tags, ListSeparator),
+		strings.Join(p.Sizes, ListSeparator),
+		strings.Join(p.Colors, ListSeparator),
+		p.Gender, p.Oversize, p.Featured,
+		optionalPrice(p.CompareAtPrice), optionalPrice(p.SalePrice),
+		optionalTime(p.SaleStartsAt), optionalTime(p.SaleEndsAt),
+	}
+	continuation := make([]interface{}, len(product))
+	continuation[0] = p.Slug
+
+	if len(variants) == 0 {
+		row := append(product, nil, nil, nil, nil, nil)
+		if includeDeleted {
+			row = append(row, deletedAt)
+		}
+		return [][]interface{}{row}
+	}
+
+	rows := make([][]interface{}, 0, len(variants))
+	for i, v := range variants {
+		row := product
+		if i > 0 {
+			row = continuation
+		}
+
+		row = append(append([]interface{}{}, row...), v.SKU, v.Size, v.Color, v.Stock, optionalPrice(v.Price))
+		if includeDeleted {
+			row = append(row, deletedAt)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// optionalPrice returns a price cell, empty when the price is not set
+func optionalPrice(price *int) interface{} {
+	if price == nil {
+		return nil
+	}
+	return *price
+}
+
+// optionalTime returns a time cell, empty when the time is not set
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// csvRowWriter writes rows as CSV
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlRowWriter writes each row as a JSON object keyed by column name
+type jsonlRowWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (j *jsonlRowWriter) WriteRow(values []interface{}) error {
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		if value != nil && value != "" {
+			object[j.columns[i]] = value
+		}
+	}
+	return j.enc.Encode(object)
+}
+
+func (j *jsonlRowWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,175 @@
+package product_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+type exportSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+	p   *product.Product // "Gauchito Gil", on sale with two variants
+}
+
+func TestExportSuite(t *testing.T) {
+	suite.Run(t, new(exportSuite))
+}
+
+func (s *exportSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	startsAt := time.Date(2024, 11, 1, 3, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2024, 11, 30, 3, 0, 0, 0, time.UTC)
+	variantPrice := 600000
+	s.p = s.create(product.CreateProductInput{
+		Name:           "Gauchito Gil",
+		Description:    "Remera de algodón,\ncon estampa \"vintage\"",
+		Price:          550000,
+		Category:       "Remeras",
+		Images:         []string{"gauchito.jpg", "gauchito-espalda.jpg"},
+		Tags:           []string{"santos populares", "rojo"},
+		Sizes:          []string{"M", "XL"},
+		Colors:         []string{"Rojo"},
+		Gender:         "unisex",
+		Oversize:       true,
+		CompareAtPrice: intPtr(700000),
+		SalePrice:      intPtr(450000),
+		SaleStartsAt:   &startsAt,
+		SaleEndsAt:     &endsAt,
+	})
+	for _, input := range []product.CreateVariantInput{
+		{SKU: "GG-M", Size: "M", Color: "Rojo", Stock: 5},
+		{SKU: "GG-XL", Size: "XL", Color: "Rojo", Stock: 0, Price: &variantPrice},
+	} {
+		_, err := s.svc.CreateVariant(s.ctx, s.p.ID, input)
+		s.Require().NoError(err)
+	}
+	s.create(product.CreateProductInput{Name: "Virgen María", Slug: "virgen", Price: 500000, Featured: true})
+	trashed := s.create(product.CreateProductInput{Name: "San Expedito", Price: 500000})
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, trashed.ID, 0))
+}
+
+// create creates a product
+func (s *exportSuite) create(input product.CreateProductInput) *product.Product {
+	p, err := s.svc.CreateProduct(s.ctx, input)
+	s.Require().NoError(err)
+	return p
+}
+
+// export exports a catalog in a format
+func (s *exportSuite) export(svc *product.Service, format string, includeDeleted bool) []byte {
+	var buf bytes.Buffer
+	s.Require().NoError(svc.ExportProducts(s.ctx, &buf, format, includeDeleted))
+	return buf.Bytes()
+}
+
+// importFile imports an exported file into a catalog
+func (s *exportSuite) importFile(svc *product.Service, data []byte, format string) *product.ImportResult {
+	records, err := product.ReadRecords(data, format)
+	s.Require().NoError(err)
+	result, err := svc.ImportProducts(s.ctx, records, product.ImportOptions{})
+	s.Require().NoError(err)
+	s.Require().Empty(result.Errors)
+	return result
+}
+
+var roundTripFormats = []string{product.FormatCSV, product.FormatXLSX}
+
+func (s *exportSuite) TestExportImportRoundTrips() {
+	for _, format := range roundTripFormats {
+		exported := s.export(s.svc, format, true)
+		other := product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+		result := s.importFile(other, exported, format)
+		want, wantErr := product.ReadRecords(s.export(s.svc, format, false), format)
+		got, gotErr := product.ReadRecords(s.export(other, format, false), format)
+
+		s.True(result.Applied, format)
+		// The trashed product is left out of the import
+		s.Equal(2, result.Created, format)
+		s.Require().NoError(wantErr, format)
+		s.Require().NoError(gotErr, format)
+		s.Equal(want, got, format)
+	}
+}
+
+func (s *exportSuite) TestReimportChangesNothing() {
+	for _, format := range roundTripFormats {
+		before := s.export(s.svc, format, false)
+		revisions, err := s.svc.GetRevisions(s.ctx, s.p.ID)
+		s.Require().NoError(err)
+
+		result := s.importFile(s.svc, before, format)
+		after, afterErr := product.ReadRecords(s.export(s.svc, format, false), format)
+		unchanged, unchangedErr := product.ReadRecords(before, format)
+		revisionsAfter, revisionsErr := s.svc.GetRevisions(s.ctx, s.p.ID)
+
+		s.Equal(2, result.Updated, format)
+		s.Zero(result.Created, format)
+		s.Require().NoError(afterErr, format)
+		s.Require().NoError(unchangedErr, format)
+		s.Equal(unchanged, after, format)
+		s.Require().NoError(revisionsErr, format)
+		s.Len(revisionsAfter, len(revisions), format)
+	}
+}
+
+func (s *exportSuite) TestExportLayout() {
+	records, err := product.ReadRecords(s.export(s.svc, product.FormatCSV, false), product.FormatCSV)
+
+	s.Require().NoError(err)
+	s.Require().Len(records, 4)
+	s.Equal(product.Columns, records[0])
+	s.Equal([]string{
+		"gauchito-gil", "Gauchito Gil", "Remera de algodón,\ncon estampa \"vintage\"", "550000", "Remeras",
+		"gauchito.jpg|gauchito-espalda.jpg", "santos populares|rojo", "M|XL", "Rojo", "unisex", "true", "false",
+		"700000", "450000", "2024-11-01T03:00:00Z", "2024-11-30T03:00:00Z",
+		"GG-M", "M", "Rojo", "5", "",
+	}, records[1])
+	// Later variant rows only repeat the slug
+	s.Equal([]string{
+		"gauchito-gil", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "",
+		"GG-XL", "XL", "Rojo", "0", "600000",
+	}, records[2])
+	s.Equal("virgen", records[3][0])
+}
+
+func (s *exportSuite) TestExportIncludesDeletedOnRequest() {
+	records, err := product.ReadRecords(s.export(s.svc, product.FormatCSV, true), product.FormatCSV)
+
+	s.Require().NoError(err)
+	s.Require().Len(records, 5)
+	s.Equal(product.DeletedColumn, records[0][len(records[0])-1])
+	s.Equal("san-expedito", records[4][0])
+	s.NotEmpty(records[4][len(records[4])-1])
+	s.Empty(records[1][len(records[1])-1])
+}
+
+func (s *exportSuite) TestExportJSONLines() {
+	var objects []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(s.export(s.svc, product.FormatJSONL, false)))
+	for scanner.Scan() {
+		var object map[string]interface{}
+		s.Require().NoError(json.Unmarshal(scanner.Bytes(), &object))
+		objects = append(objects, object)
+	}
+
+	s.Require().Len(objects, 3)
+	s.Equal("gauchito-gil", objects[0]["slug"])
+	s.Equal(float64(550000), objects[0]["price"])
+	s.Equal(true, objects[0]["oversize"])
+	// Empty cells are left out
+	s.Equal(map[string]interface{}{"slug": "gauchito-gil", "sku": "GG-XL", "size": "XL", "color": "Rojo", "stock": float64(0), "variant_price": float64(600000)}, objects[1])
+	s.NotContains(objects[2], "sku")
+}
@@ -0,0 +1,27 @@
+package product
+
+// FacetValue is a filter value with the number of matching products
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// PriceRange is the lowest and highest price of matching products, in cents
+type PriceRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// Facets contains product counts per filter value. Each facet is computed
+// with every other active filter applied but not its own, so selecting
+// "Negro" still shows how many products exist in "Blanco".
+type Facets struct {
+	Categories []FacetValue `json:"categories"`
+	Genders    []FacetValue `json:"genders"`
+	Tags       []FacetValue `json:"tags"`
+	Sizes      []FacetValue `json:"sizes"`
+	Colors     []FacetValue `json:"colors"`
+	Oversize   int          `json:"oversize"`
+	Featured   int          `json:"featured"`
+	Price      PriceRange   `json:"price"`
+}
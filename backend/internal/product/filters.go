@@ -0,0 +1,153 @@
+package product
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Facet names accepted by buildProductQuery to skip their own filter
+const (
+	facetCategory = "category"
+	facetGender   = "gender"
+	facetTag      = "tag"
+	facetSize     = "size"
+	facetColor    = "color"
+	facetOversize = "oversize"
+	facetFeatured = "featured"
+	facetPrice    = "price"
+)
+
+// effectivePriceExpr is the SQL counterpart of the effective price set by
+// applyPricing: the sale price while the sale runs, the price otherwise. It
+// reads the current time from the clock joined by buildProductQuery.
+const effectivePriceExpr = `CASE WHEN products.sale_price IS NOT NULL
+	AND (products.sale_starts_at IS NULL OR products.sale_starts_at <= clock.now)
+	AND (products.sale_ends_at IS NULL OR products.sale_ends_at > clock.now)
+	THEN products.sale_price ELSE products.price END`
+
+// productQuery holds the FROM and WHERE clauses shared by listing, counting and facets
+type productQuery struct {
+	from   string
+	where  string
+	args   []interface{}
+	search string // FTS query, empty when not searching
+}
+
+// buildProductQuery translates filters into SQL. The filter of the facet named
+// by exclude is left out so facet counts show alternatives to the current choice.
+func buildProductQuery(filters GetAllFilters, exclude string) productQuery {
+	var whereClauses []string
+	var args []interface{}
+
+	// Full-text search joins the ranked FTS matches
+	from := "products"
+	search := buildFTSQuery(filters.Search)
+	if search != "" {
+		from = "products JOIN (" + ftsMatchQuery + ") AS fts ON fts.rowid = products.id"
+		args = append(args, search)
+	}
+
+	// The clock lets the effective price be used in sorts without placeholders
+	from += " CROSS JOIN (SELECT ? AS now) AS clock"
+	args = append(args, time.Now())
+
+	// Exclude deleted products
+	whereClauses = append(whereClauses, "products.deleted_at IS NULL")
+
+	// Category filter matches the category by slug or name, including its subcategories
+	if filters.Category != "" && exclude != facetCategory {
+		whereClauses = append(whereClauses, `(products.category_id IN (
+			WITH RECURSIVE tree(id) AS (
+				SELECT id FROM categories WHERE slug = ?
+				UNION ALL
+				SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+			)
+			SELECT id FROM tree
+		) OR (products.category_id IS NULL AND products.category = ? COLLATE NOCASE))`)
+		args = append(args, slug.Make(filters.Category), filters.Category)
+	}
+
+	// Gender filter
+	if filters.Gender != "" && exclude != facetGender {
+		whereClauses = append(whereClauses, "products.gender = ?")
+		args = append(args, filters.Gender)
+	}
+
+	// Boolean filters
+	if filters.Oversize != nil && exclude != facetOversize {
+		whereClauses = append(whereClauses, "COALESCE(products.oversize, 0) = ?")
+		args = append(args, boolToInt(*filters.Oversize))
+	}
+	if filters.Featured != nil && exclude != facetFeatured {
+		whereClauses = append(whereClauses, "COALESCE(products.featured, 0) = ?")
+		args = append(args, boolToInt(*filters.Featured))
+	}
+
+	// JSON array filters match products having any of the requested values
+	if len(filters.Tags) > 0 && exclude != facetTag {
+		whereClauses = append(whereClauses, jsonArrayContainsAny("products.tags", len(filters.Tags)))
+		args = appendStrings(args, filters.Tags)
+	}
+	if len(filters.Sizes) > 0 && exclude != facetSize {
+		whereClauses = append(whereClauses, jsonArrayContainsAny("products.sizes", len(filters.Sizes)))
+		args = appendStrings(args, filters.Sizes)
+	}
+	if len(filters.Colors) > 0 && exclude != facetColor {
+		whereClauses = append(whereClauses, jsonArrayContainsAny("products.colors", len(filters.Colors)))
+		args = appendStrings(args, filters.Colors)
+	}
+
+	// Price range filter on the price charged right now, in cents
+	if exclude != facetPrice {
+		if filters.MinPrice > 0 {
+			whereClauses = append(whereClauses, "("+effectivePriceExpr+") >= ?")
+			args = append(args, filters.MinPrice)
+		}
+		if filters.MaxPrice > 0 {
+			whereClauses = append(whereClauses, "("+effectivePriceExpr+") <= ?")
+			args = append(args, filters.MaxPrice)
+		}
+	}
+
+	// Hide out-of-stock products; untracked products are always listed
+	if filters.InStockOnly {
+		whereClauses = append(whereClauses, "COALESCE((SELECT SUM(quantity) FROM inventory_movements WHERE product_id = products.id), 1) > 0")
+	}
+
+	return productQuery{
+		from:   from,
+		where:  "WHERE " + strings.Join(whereClauses, " AND "),
+		args:   args,
+		search: search,
+	}
+}
+
+// jsonArrayContainsAny returns a condition matching a JSON array column
+// that contains any of n placeholder values, ignoring case
+func jsonArrayContainsAny(column string, n int) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+	return "EXISTS (SELECT 1 FROM json_each(" + jsonArray(column) + ") WHERE json_each.value COLLATE NOCASE IN (" + placeholders + "))"
+}
+
+// jsonArray guards a JSON column so rows with malformed or empty values read as []
+func jsonArray(column string) string {
+	return "CASE WHEN json_valid(" + column + ") THEN " + column + " ELSE '[]' END"
+}
+
+// appendStrings appends string values to a query argument list
+func appendStrings(args []interface{}, values []string) []interface{} {
+	for _, value := range values {
+		args = append(args, value)
+	}
+	return args
+}
+
+// boolToInt converts a bool to the 0/1 integer stored in SQLite
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// Products listed by the filter tests, by the price charged right now
+const (
+	onSale    = "Remera en Oferta"  // 900000 on sale for 400000
+	scheduled = "Remera Programada" // 450000, on sale for 100000 from tomorrow
+	regular   = "Remera Lisa"       // 500000
+)
+
+type filterSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+}
+
+func TestFilterSuite(t *testing.T) {
+	suite.Run(t, new(filterSuite))
+}
+
+func (s *filterSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	for _, input := range []product.CreateProductInput{
+		{Name: onSale, Price: 900000, SalePrice: intPtr(400000), Tags: []string{"Básico"}, Colors: []string{"Blanco"}},
+		{Name: scheduled, Price: 450000, SalePrice: intPtr(100000), SaleStartsAt: &tomorrow, Sizes: []string{"M"}},
+		{Name: regular, Price: 500000, Tags: []string{"Verano"}, Sizes: []string{"L"}, Colors: []string{"Negro"}},
+	} {
+		_, err := s.svc.CreateProduct(s.ctx, input)
+		s.Require().NoError(err)
+	}
+}
+
+// names lists the names of the products matching filters, in order
+func (s *filterSuite) names(filters product.GetAllFilters) []string {
+	products, _, err := s.svc.GetProducts(s.ctx, filters)
+	s.Require().NoError(err)
+
+	names := []string{}
+	for _, p := range products {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+var filterCases = []struct {
+	name    string
+	filters product.GetAllFilters
+	want    []string
+}{
+	{name: "Price ascending", filters: product.GetAllFilters{Sort: "price", Order: "asc"}, want: []string{onSale, scheduled, regular}},
+	{name: "Price descending", filters: product.GetAllFilters{Sort: "price", Order: "desc"}, want: []string{regular, scheduled, onSale}},
+	{name: "Maximum below the regular price of a sale", filters: product.GetAllFilters{MaxPrice: 420000}, want: []string{onSale}},
+	{name: "Range ignoring a sale yet to start", filters: product.GetAllFilters{MinPrice: 420000, MaxPrice: 480000}, want: []string{scheduled}},
+	{name: "Tag in another case", filters: product.GetAllFilters{Tags: []string{"verano"}}, want: []string{regular}},
+	{name: "Size in another case", filters: product.GetAllFilters{Sizes: []string{"m"}}, want: []string{scheduled}},
+	{name: "Colors in another case", filters: product.GetAllFilters{Colors: []string{"NEGRO", "blanco"}, Sort: "name", Order: "asc"}, want: []string{regular, onSale}},
+}
+
+func (s *filterSuite) TestFilters() {
+	for _, tc := range filterCases {
+		s.Equal(tc.want, s.names(tc.filters), tc.name)
+	}
+}
+
+func (s *filterSuite) TestPriceFacetUsesChargedPrice() {
+	facets, err := s.svc.GetFacets(s.ctx, product.GetAllFilters{MaxPrice: 420000})
+
+	s.Require().NoError(err)
+	s.Equal(400000, facets.Price.Min)
+	s.Equal(500000, facets.Price.Max)
+}
+
+func (s *filterSuite) TestCursorFollowsChargedPrice() {
+	filters := product.GetAllFilters{Sort: "price", Order: "asc", Limit: 2}
+
+	first, firstErr := s.svc.GetProductsByCursor(s.ctx, filters, "")
+	s.Require().NoError(firstErr)
+	second, secondErr := s.svc.GetProductsByCursor(s.ctx, filters, first.NextCursor)
+
+	s.Require().Len(first.Products, 2)
+	s.Equal(onSale, first.Products[0].Name)
+	s.Equal(scheduled, first.Products[1].Name)
+	s.Require().NoError(secondErr)
+	s.Require().Len(second.Products, 1)
+	s.Equal(regular, second.Products[0].Name)
+	s.Empty(second.NextCursor)
+}
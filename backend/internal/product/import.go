@@ -0,0 +1,377 @@
+package product
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+	"github.com/tomas/tienda-backend/internal/platform/xlsx"
+)
+
+// Columns is the spreadsheet layout accepted by the import and produced by
+// the export. Products with variants span one row per variant; product
+// columns only need to be filled on the first of those rows.
+var Columns = []string{
+	"slug", "name", "description", "price", "category",
+	"images", "tags", "sizes", "colors", "gender", "oversize", "featured",
+	"compare_at_price", "sale_price", "sale_starts_at", "sale_ends_at",
+	"sku", "size", "color", "stock", "variant_price",
+}
+
+// ListSeparator separates the values of list columns such as "S|M|L"
+const ListSeparator = "|"
+
+// Import file formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ImportOptions controls how an import batch is applied
+type ImportOptions struct {
+	DryRun  bool  // Validate and report without saving anything
+	AdminID int64 // Recorded on the revisions of imported products
+}
+
+// ImportResult reports the outcome of an import batch
+type ImportResult struct {
+	DryRun   bool              `json:"dry_run"`
+	Applied  bool              `json:"applied"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Products []ImportedProduct `json:"products"`
+	Errors   []ImportError     `json:"errors"`
+}
+
+// ImportedProduct describes what the import does with one product
+type ImportedProduct struct {
+	Row       int    `json:"row"` // First row of the product in the file
+	Action    string `json:"action"`
+	ProductID int64  `json:"product_id,omitempty"` // Set once applied
+	Slug      string `json:"slug"`
+	Variants  int    `json:"variants"`
+}
+
+// ImportError is a validation error on a row of the file
+type ImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportProduct groups the rows of one product in an import file. Create
+// holds every column of the file; Update only the non-empty ones, so an
+// existing product keeps the values left blank.
+type ImportProduct struct {
+	Row      int    // First row of the product
+	Slug     string // Empty when the file gives none
+	Create   CreateProductInput
+	Update   UpdateProductInput
+	Variants []ImportVariant
+
+	invalid bool // Has errors in its cells, so it is not run against the database
+}
+
+// ImportVariant is a variant row of an import file
+type ImportVariant struct {
+	Row    int
+	Create CreateVariantInput
+	Update UpdateVariantInput
+}
+
+// ReadRecords decodes a CSV or XLSX file into rows of cells. CSV files may
+// use "," or ";" as separator, as spreadsheets configured for Spanish do.
+func ReadRecords(data []byte, format string) ([][]string, error) {
+	switch format {
+	case FormatXLSX:
+		records, err := xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, ErrInvalidInput(err.Error())
+		}
+		return records, nil
+	case FormatCSV:
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		firstLine, _, _ := strings.Cut(string(data), "\n")
+		if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+			reader.Comma = ';'
+		}
+
+		var records [][]string
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, ErrInvalidInput(fmt.Sprintf("malformed csv: %v", err))
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	default:
+		return nil, ErrInvalidInput("unsupported format, use csv or xlsx")
+	}
+}
+
+// parseImport maps the rows of an import file to products. The first row
+// holds the column names. Rows are grouped into one product by slug, or by
+// SKU or name when the slug is empty.
+func parseImport(records [][]string) ([]*ImportProduct, []ImportError) {
+	var errs []ImportError
+	if len(records) == 0 {
+		return nil, []ImportError{{Row: 1, Message: "file is empty"}}
+	}
+
+	known := map[string]bool{DeletedColumn: true}
+	for _, column := range Columns {
+		known[column] = true
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !known[name] {
+			errs = append(errs, ImportError{Row: 1, Column: name, Message: "unknown column"})
+		}
+		header[i] = name
+	}
+
+	var products []*ImportProduct
+	byKey := make(map[string]*ImportProduct)
+
+	for i, record := range records[1:] {
+		row := i + 2
+		cells := make(map[string]string, len(header))
+		for j, value := range record {
+			if j < len(header) && header[j] != "" {
+				if value = strings.TrimSpace(value); value != "" {
+					cells[header[j]] = value
+				}
+			}
+		}
+		// Skip blank rows and trashed products of an export
+		if len(cells) == 0 || cells[DeletedColumn] != "" {
+			continue
+		}
+
+		key := slug.Make(cells["slug"])
+		switch {
+		case key != "":
+		case cells["sku"] != "" && cells["name"] == "":
+			key = "sku:" + cells["sku"]
+		default:
+			key = slug.Make(cells["name"])
+		}
+		if key == "" {
+			errs = append(errs, ImportError{Row: row, Message: "row needs a slug, name or sku"})
+			continue
+		}
+
+		p, ok := byKey[key]
+		if !ok {
+			p = &ImportProduct{Row: row, Slug: slug.Make(cells["slug"])}
+			byKey[key] = p
+			products = append(products, p)
+			fieldErrs := p.setFields(row, cells)
+			errs = append(errs, fieldErrs...)
+			p.invalid = len(fieldErrs) > 0
+		}
+
+		if cells["sku"] != "" || cells["size"] != "" || cells["color"] != "" {
+			v, variantErrs := parseVariant(row, cells)
+			errs = append(errs, variantErrs...)
+			p.invalid = p.invalid || len(variantErrs) > 0
+			p.Variants = append(p.Variants, v)
+		}
+	}
+
+	if len(products) == 0 && len(errs) == 0 {
+		errs = append(errs, ImportError{Row: 1, Message: "file has no product rows"})
+	}
+
+	valid := products[:0]
+	for _, p := range products {
+		if !p.invalid {
+			valid = append(valid, p)
+		}
+	}
+
+	return valid, errs
+}
+
+// setFields fills the product inputs from the product columns of a row.
+// Empty cells keep the current value of existing products.
+func (p *ImportProduct) setFields(row int, cells map[string]string) []ImportError {
+	var errs []ImportError
+
+	p.Create.Slug = p.Slug
+	if v, ok := cells["name"]; ok {
+		p.Create.Name, p.Update.Name = v, &v
+	}
+	if v, ok := cells["description"]; ok {
+		// Spreadsheets often escape line breaks when editing cells
+		v = strings.ReplaceAll(v, `\n`, "\n")
+		p.Create.Description, p.Update.Description = v, &v
+	}
+	if v, ok := cells["price"]; ok {
+		price, err := strconv.Atoi(v)
+		if err != nil || price <= 0 {
+			errs = append(errs, ImportError{Row: row, Column: "price", Message: "must be a whole number greater than 0"})
+		}
+		p.Create.Price, p.Update.Price = price, &price
+	}
+	if v, ok := cells["category"]; ok {
+		p.Create.Category, p.Update.Category = v, &v
+	}
+	if v, ok := cells["gender"]; ok {
+		p.Create.Gender, p.Update.Gender = v, &v
+	}
+
+	if v, ok := cells["images"]; ok {
+		images := splitList(v)
+		p.Create.Images, p.Update.Images = images, &images
+	}
+	if v, ok := cells["tags"]; ok {
+		tags := splitList(v)
+		p.Create.Tags, p.Update.Tags = tags, &tags
+	}
+	if v, ok := cells["sizes"]; ok {
+		sizes := splitList(v)
+		p.Create.Sizes, p.Update.Sizes = sizes, &sizes
+	}
+	if v, ok := cells["colors"]; ok {
+		colors := splitList(v)
+		p.Create.Colors, p.Update.Colors = colors, &colors
+	}
+	if v, ok := cells["oversize"]; ok {
+		oversize, err := parseBool(v)
+		if err != nil {
+			errs = append(errs, ImportError{Row: row, Column: "oversize", Message: err.Error()})
+		}
+		p.Create.Oversize, p.Update.Oversize = oversize, &oversize
+	}
+	if v, ok := cells["featured"]; ok {
+		featured, err := parseBool(v)
+		if err != nil {
+			errs = append(errs, ImportError{Row: row, Column: "featured", Message: err.Error()})
+		}
+		p.Create.Featured, p.Update.Featured = featured, &featured
+	}
+
+	// A 0 price removes the compare-at price or ends the sale
+	if v, ok := cells["compare_at_price"]; ok {
+		price, err := strconv.Atoi(v)
+		if err != nil || price < 0 {
+			errs = append(errs, ImportError{Row: row, Column: "compare_at_price", Message: "must be a whole number of 0 or more"})
+		}
+		p.Create.CompareAtPrice, p.Update.CompareAtPrice = &price, &price
+	}
+	if v, ok := cells["sale_price"]; ok {
+		price, err := strconv.Atoi(v)
+		if err != nil || price < 0 {
+			errs = append(errs, ImportError{Row: row, Column: "sale_price", Message: "must be a whole number of 0 or more"})
+		}
+		p.Create.SalePrice, p.Update.SalePrice = &price, &price
+	}
+	if v, ok := cells["sale_starts_at"]; ok {
+		t, err := parseTime(v)
+		if err != nil {
+			errs = append(errs, ImportError{Row: row, Column: "sale_starts_at", Message: err.Error()})
+		}
+		p.Create.SaleStartsAt, p.Update.SaleStartsAt = &t, &t
+	}
+	if v, ok := cells["sale_ends_at"]; ok {
+		t, err := parseTime(v)
+		if err != nil {
+			errs = append(errs, ImportError{Row: row, Column: "sale_ends_at", Message: err.Error()})
+		}
+		p.Create.SaleEndsAt, p.Update.SaleEndsAt = &t, &t
+	}
+	if len(errs) == 0 {
+		if err := validatePricing(p.Update.CompareAtPrice, p.Update.SalePrice, p.Update.SaleStartsAt, p.Update.SaleEndsAt); err != nil {
+			errs = append(errs, ImportError{Row: row, Message: strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")})
+		}
+	}
+
+	return errs
+}
+
+// parseVariant reads the variant columns of a row
+func parseVariant(row int, cells map[string]string) (ImportVariant, []ImportError) {
+	var errs []ImportError
+	v := ImportVariant{Row: row}
+
+	if sku, ok := cells["sku"]; ok {
+		v.Create.SKU, v.Update.SKU = sku, &sku
+	}
+	if size, ok := cells["size"]; ok {
+		v.Create.Size, v.Update.Size = size, &size
+	}
+	if color, ok := cells["color"]; ok {
+		v.Create.Color, v.Update.Color = color, &color
+	}
+	if raw, ok := cells["stock"]; ok {
+		stock, err := strconv.Atoi(raw)
+		if err != nil || stock < 0 {
+			errs = append(errs, ImportError{Row: row, Column: "stock", Message: "must be a whole number of 0 or more"})
+		}
+		v.Create.Stock, v.Update.Stock = stock, &stock
+	}
+	if raw, ok := cells["variant_price"]; ok {
+		price, err := strconv.Atoi(raw)
+		if err != nil || price <= 0 {
+			errs = append(errs, ImportError{Row: row, Column: "variant_price", Message: "must be a whole number greater than 0"})
+		}
+		v.Create.Price, v.Update.Price = &price, &price
+	}
+
+	return v, errs
+}
+
+// splitList splits a list cell such as "S|M|L"
+func splitList(value string) []string {
+	values := []string{}
+	for _, item := range strings.Split(value, ListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// parseBool accepts the usual spreadsheet spellings of yes and no
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(slug.FoldAccents(value)) {
+	case "1", "true", "yes", "si", "x":
+		return true, nil
+	case "0", "false", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("must be true or false")
+}
+
+// parseTime accepts RFC 3339 times, as the export writes them, and dates,
+// which start at midnight UTC
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be a date such as 2024-12-01 or 2024-12-01T09:00:00-03:00")
+}
+
+// sortImportErrors orders errors by row so they read top to bottom
+func sortImportErrors(errs []ImportError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+}
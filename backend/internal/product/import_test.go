@@ -0,0 +1,187 @@
+package product_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// Columns of the import files the tests build
+var importHeader = []string{"slug", "name", "price", "sku", "size", "color", "stock"}
+
+type importSuite struct {
+	suite.Suite
+	ctx context.Context
+	db  *sql.DB
+	svc *product.Service
+	p   *product.Product // "Gauchito Gil", with variant GG-M
+}
+
+func TestImportSuite(t *testing.T) {
+	suite.Run(t, new(importSuite))
+}
+
+func (s *importSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.db = testdouble.NewDB(s.T())
+	s.svc = product.NewService(product.NewSQLiteRepository(s.db))
+
+	var err error
+	s.p, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: "Gauchito Gil", Price: 550000})
+	s.Require().NoError(err)
+	_, err = s.svc.CreateVariant(s.ctx, s.p.ID, product.CreateVariantInput{SKU: "GG-M", Size: "M", Color: "Rojo", Stock: 5})
+	s.Require().NoError(err)
+}
+
+// importRows imports rows under importHeader
+func (s *importSuite) importRows(opts product.ImportOptions, rows ...[]string) *product.ImportResult {
+	result, err := s.svc.ImportProducts(s.ctx, append([][]string{importHeader}, rows...), opts)
+	s.Require().NoError(err)
+	return result
+}
+
+// count counts the rows of a table
+func (s *importSuite) count(table string) int {
+	var count int
+	s.Require().NoError(s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count))
+	return count
+}
+
+// tableCounts counts the rows of every table an import writes to
+func (s *importSuite) tableCounts() map[string]int {
+	counts := map[string]int{}
+	for _, table := range []string{"products", "product_variants", "product_revisions", "product_slug_history"} {
+		counts[table] = s.count(table)
+	}
+	return counts
+}
+
+func (s *importSuite) TestDryRunWritesNothing() {
+	before := s.tableCounts()
+
+	result := s.importRows(product.ImportOptions{DryRun: true},
+		[]string{"gauchito-gil", "Gauchito Gil Rojo", "600000", "GG-M", "M", "Rojo", "9"},
+		[]string{"", "Virgen María", "550000", "VM-S", "S", "Blanco", "3"},
+	)
+	unchanged, err := s.svc.GetProduct(s.ctx, s.p.ID)
+
+	s.Require().NoError(err)
+	s.True(result.DryRun)
+	s.False(result.Applied)
+	s.Equal(1, result.Created)
+	s.Equal(1, result.Updated)
+	s.Empty(result.Errors)
+	s.Require().Len(result.Products, 2)
+	s.Zero(result.Products[0].ProductID)
+	s.Equal("gauchito-gil-rojo", result.Products[0].Slug)
+	s.Equal("virgen-maria", result.Products[1].Slug)
+	s.Equal(before, s.tableCounts())
+	s.Equal("Gauchito Gil", unchanged.Name)
+	s.Equal(5, unchanged.Variants[0].Stock)
+}
+
+var upsertCases = []struct {
+	name string
+	row  []string
+}{
+	{name: "By slug", row: []string{"gauchito-gil", "", "600000", "", "", "", ""}},
+	{name: "By sku", row: []string{"", "", "600000", "GG-M", "", "", ""}},
+	{name: "By name", row: []string{"", "Gauchito Gil", "600000", "", "", "", ""}},
+}
+
+func (s *importSuite) TestImportUpdatesExistingProducts() {
+	for _, tc := range upsertCases {
+		s.SetupTest()
+
+		result := s.importRows(product.ImportOptions{}, tc.row)
+		updated, err := s.svc.GetProduct(s.ctx, s.p.ID)
+
+		s.Require().NoError(err, tc.name)
+		s.True(result.Applied, tc.name)
+		s.Equal(1, result.Updated, tc.name)
+		s.Zero(result.Created, tc.name)
+		s.Equal(1, s.count("products"), tc.name)
+		s.Equal(600000, updated.Price, tc.name)
+		// Blank cells keep the current values
+		s.Equal("Gauchito Gil", updated.Name, tc.name)
+		s.Require().Len(updated.Variants, 1, tc.name)
+		s.Equal(5, updated.Variants[0].Stock, tc.name)
+	}
+}
+
+func (s *importSuite) TestImportGroupsVariantRows() {
+	result := s.importRows(product.ImportOptions{},
+		[]string{"gauchito-gil", "", "", "GG-M", "", "", "8"},
+		[]string{"gauchito-gil", "", "", "", "L", "Rojo", "2"},
+		[]string{"", "Virgen María", "550000", "VM-S", "S", "Blanco", "3"},
+		[]string{"", "Virgen María", "", "VM-M", "M", "Blanco", "4"},
+	)
+	gaucho, gauchoErr := s.svc.GetProduct(s.ctx, s.p.ID)
+	virgin, virginErr := s.svc.GetProductBySlug(s.ctx, "virgen-maria")
+
+	s.True(result.Applied)
+	s.Equal(1, result.Created)
+	s.Equal(1, result.Updated)
+	s.Require().NoError(gauchoErr)
+	s.Require().Len(gaucho.Variants, 2)
+	s.Equal(8, gaucho.Variants[0].Stock)
+	s.Equal("L", gaucho.Variants[1].Size)
+	s.Require().NoError(virginErr)
+	s.Len(virgin.Variants, 2)
+}
+
+func (s *importSuite) TestInvalidRowsSaveNothing() {
+	before := s.tableCounts()
+
+	result := s.importRows(product.ImportOptions{},
+		[]string{"", "Virgen María", "550000", "", "", "", ""},
+		[]string{"", "Remera Lisa", "gratis", "", "", "", ""},
+		[]string{"", "San Expedito", "550000", "SE-M", "M", "", ""},
+		[]string{"", "San Expedito", "", "SE-M", "L", "", ""},
+		[]string{"", "Difunta Correa", "550000", "DC-M", "M", "", "-1"},
+	)
+
+	s.False(result.Applied)
+	s.False(result.DryRun)
+	s.Equal(before, s.tableCounts())
+	s.Require().Len(result.Errors, 3)
+	s.Equal(product.ImportError{Row: 3, Column: "price", Message: "must be a whole number greater than 0"}, result.Errors[0])
+	// A repeated SKU is only found by running the rows against the database
+	s.Equal(5, result.Errors[1].Row)
+	s.Equal(product.ImportError{Row: 6, Column: "stock", Message: "must be a whole number of 0 or more"}, result.Errors[2])
+}
+
+func (s *importSuite) TestUnchangedImportRecordsNoRevision() {
+	revisions := s.count("product_revisions")
+
+	result := s.importRows(product.ImportOptions{}, []string{"gauchito-gil", "Gauchito Gil", "550000", "GG-M", "M", "Rojo", "5"})
+
+	s.True(result.Applied)
+	s.Equal(1, result.Updated)
+	s.Equal(revisions, s.count("product_revisions"))
+}
+
+var readRecordsCases = []struct {
+	name string
+	data string
+	want [][]string
+}{
+	{name: "Comma separated", data: "name,price\nRemera,550000\n", want: [][]string{{"name", "price"}, {"Remera", "550000"}}},
+	{name: "Semicolon separated", data: "name;price\n\"Remera, negra\";550000\n", want: [][]string{{"name", "price"}, {"Remera, negra", "550000"}}},
+	{name: "Byte order mark", data: "\xef\xbb\xbfname,price\nRemera,550000\n", want: [][]string{{"name", "price"}, {"Remera", "550000"}}},
+	{name: "Rows of different lengths", data: "name,price,sku\nRemera\n", want: [][]string{{"name", "price", "sku"}, {"Remera"}}},
+}
+
+func (s *importSuite) TestReadRecords() {
+	for _, tc := range readRecordsCases {
+		records, err := product.ReadRecords([]byte(tc.data), product.FormatCSV)
+
+		s.Require().NoError(err, tc.name)
+		s.Equal(tc.want, records, tc.name)
+	}
+}
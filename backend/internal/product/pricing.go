@@ -0,0 +1,87 @@
+package product
+
+import (
+	"math"
+	"time"
+)
+
+// PriceChange is an entry of a product's price history
+type PriceChange struct {
+	Price          int        `json:"price"`
+	CompareAtPrice *int       `json:"compare_at_price,omitempty"`
+	SalePrice      *int       `json:"sale_price,omitempty"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty"`
+	ChangedAt      time.Time  `json:"changed_at"`
+}
+
+// SaleActive reports whether the sale price applies at t
+func (p *Product) SaleActive(t time.Time) bool {
+	if p.SalePrice == nil {
+		return false
+	}
+	if p.SaleStartsAt != nil && t.Before(*p.SaleStartsAt) {
+		return false
+	}
+	if p.SaleEndsAt != nil && !t.Before(*p.SaleEndsAt) {
+		return false
+	}
+	return true
+}
+
+// applyPricing sets the price charged at now and, when it is lower than the
+// regular or compare-at price, the crossed-out price and discount
+func (p *Product) applyPricing(now time.Time) {
+	p.EffectivePrice = p.Price
+	if p.SaleActive(now) {
+		p.EffectivePrice = *p.SalePrice
+	}
+
+	original := p.Price
+	if p.CompareAtPrice != nil && *p.CompareAtPrice > original {
+		original = *p.CompareAtPrice
+	}
+
+	p.OriginalPrice = nil
+	p.DiscountPercent = 0
+	if original > p.EffectivePrice {
+		p.OriginalPrice = &original
+		p.DiscountPercent = int(math.Round(float64(original-p.EffectivePrice) * 100 / float64(original)))
+	}
+}
+
+// VariantPrice returns the price charged right now for a variant. A running
+// sale lowers a variant price override by the same proportion as the
+// product price.
+func (p *Product) VariantPrice(v *Variant) int {
+	if v.Price == nil {
+		return p.EffectivePrice
+	}
+	if p.EffectivePrice == p.Price {
+		return *v.Price
+	}
+	return int(math.Round(float64(*v.Price) * float64(p.EffectivePrice) / float64(p.Price)))
+}
+
+// validatePricing checks the compare-at and sale fields of a product input.
+// A 0 price is accepted as the way to remove it.
+func validatePricing(compareAtPrice, salePrice *int, saleStartsAt, saleEndsAt *time.Time) error {
+	if compareAtPrice != nil && *compareAtPrice < 0 {
+		return ErrInvalidInput("compare_at_price cannot be negative")
+	}
+	if salePrice != nil && *salePrice < 0 {
+		return ErrInvalidInput("sale_price cannot be negative")
+	}
+	if saleStartsAt != nil && saleEndsAt != nil && !saleEndsAt.After(*saleStartsAt) {
+		return ErrInvalidInput("sale_ends_at must be after sale_starts_at")
+	}
+	return nil
+}
+
+// validateSalePrice checks that a sale lowers the price it replaces
+func validateSalePrice(price int, salePrice *int) error {
+	if salePrice != nil && *salePrice > 0 && *salePrice >= price {
+		return ErrInvalidInput("sale_price must be lower than price")
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package product_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	listPrice      = 550000
+	salePrice      = 440000
+	compareAtPrice = 600000
+	saleTag        = "hot-sale"
+)
+
+type pricingSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+	p   *product.Product
+}
+
+func TestPricingSuite(t *testing.T) {
+	suite.Run(t, new(pricingSuite))
+}
+
+func (s *pricingSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	sale, compareAt := salePrice, compareAtPrice
+	var err error
+	s.p, err = s.svc.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:           "Remera en Oferta",
+		Price:          listPrice,
+		Tags:           []string{saleTag},
+		CompareAtPrice: &compareAt,
+		SalePrice:      &sale,
+	})
+	s.Require().NoError(err)
+}
+
+// intPtr returns a pointer to a price
+func intPtr(v int) *int {
+	return &v
+}
+
+var createPricingCases = []struct {
+	name      string
+	salePrice *int
+	wantErr   error
+}{
+	{name: "Sale below price", salePrice: intPtr(listPrice - 1)},
+	{name: "Sale equal to price", salePrice: intPtr(listPrice), wantErr: product.ErrValidation},
+	{name: "Sale above price", salePrice: intPtr(listPrice + 1), wantErr: product.ErrValidation},
+	{name: "Negative sale", salePrice: intPtr(-1), wantErr: product.ErrValidation},
+	{name: "No sale", salePrice: intPtr(0)},
+}
+
+func (s *pricingSuite) TestCreateSalePrice() {
+	for _, tc := range createPricingCases {
+		_, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: tc.name, Price: listPrice, SalePrice: tc.salePrice})
+
+		s.ErrorIs(err, tc.wantErr, tc.name)
+	}
+}
+
+var updatePricingCases = []struct {
+	name          string
+	input         product.UpdateProductInput
+	wantErr       error
+	wantPrice     int
+	wantSalePrice *int
+}{
+	{name: "Sale alone at the current price", input: product.UpdateProductInput{SalePrice: intPtr(listPrice)}, wantErr: product.ErrValidation, wantPrice: listPrice, wantSalePrice: intPtr(salePrice)},
+	{name: "Price alone down to the sale", input: product.UpdateProductInput{Price: intPtr(salePrice)}, wantErr: product.ErrValidation, wantPrice: listPrice, wantSalePrice: intPtr(salePrice)},
+	{name: "Price and sale together", input: product.UpdateProductInput{Price: intPtr(400000), SalePrice: intPtr(350000)}, wantPrice: 400000, wantSalePrice: intPtr(350000)},
+	{name: "Price down after ending the sale", input: product.UpdateProductInput{Price: intPtr(salePrice), SalePrice: intPtr(0)}, wantPrice: salePrice},
+	{name: "Sale below the current price", input: product.UpdateProductInput{SalePrice: intPtr(500000)}, wantPrice: listPrice, wantSalePrice: intPtr(500000)},
+}
+
+func (s *pricingSuite) TestUpdateSalePrice() {
+	for _, tc := range updatePricingCases {
+		s.SetupTest()
+
+		_, err := s.svc.UpdateProduct(s.ctx, s.p.ID, tc.input)
+		p, getErr := s.svc.GetProduct(s.ctx, s.p.ID)
+
+		s.ErrorIs(err, tc.wantErr, tc.name)
+		s.Require().NoError(getErr)
+		s.Equal(tc.wantPrice, p.Price, tc.name)
+		s.Equal(tc.wantSalePrice, p.SalePrice, tc.name)
+	}
+}
+
+func (s *pricingSuite) TestUpdateSaleDates() {
+	starts, ends := time.Now().Add(2*time.Hour), time.Now().Add(time.Hour)
+	plain, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera sin oferta", Price: listPrice})
+	s.Require().NoError(err)
+
+	_, datesOnlyErr := s.svc.UpdateProduct(s.ctx, plain.ID, product.UpdateProductInput{SaleStartsAt: &starts})
+	undated, plainErr := s.svc.GetProduct(s.ctx, plain.ID)
+	_, startsErr := s.svc.UpdateProduct(s.ctx, s.p.ID, product.UpdateProductInput{SaleStartsAt: &starts})
+	_, endsErr := s.svc.UpdateProduct(s.ctx, s.p.ID, product.UpdateProductInput{SaleEndsAt: &ends})
+	scheduled, scheduledErr := s.svc.GetProduct(s.ctx, s.p.ID)
+	_, endSaleErr := s.svc.UpdateProduct(s.ctx, s.p.ID, product.UpdateProductInput{SalePrice: intPtr(0)})
+	ended, endedErr := s.svc.GetProduct(s.ctx, s.p.ID)
+
+	s.Require().NoError(datesOnlyErr)
+	s.Require().NoError(plainErr)
+	s.Nil(undated.SaleStartsAt)
+	s.Require().NoError(startsErr)
+	s.ErrorIs(endsErr, product.ErrValidation)
+	s.Require().NoError(scheduledErr)
+	s.WithinDuration(starts, *scheduled.SaleStartsAt, time.Second)
+	s.Nil(scheduled.SaleEndsAt)
+	s.Require().NoError(endSaleErr)
+	s.Require().NoError(endedErr)
+	s.Nil(ended.SalePrice)
+	s.Nil(ended.SaleStartsAt)
+}
+
+func (s *pricingSuite) TestBulkPriceBelowSaleFails() {
+	result, err := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{s.p.ID},
+		Operation: product.BulkUpdate,
+		Update:    &product.UpdateProductInput{Price: intPtr(salePrice)},
+	})
+
+	s.Require().NoError(err)
+	s.False(result.Applied)
+	s.Equal(1, result.Failed)
+}
+
+var adjustmentSaleCases = []struct {
+	name          string
+	input         product.PriceAdjustmentInput
+	wantPrice     int
+	wantCompareAt int
+	wantSale      int
+}{
+	{name: "Raise", input: product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: 10}, wantPrice: 605000, wantCompareAt: 660000, wantSale: 484000},
+	{name: "Discount", input: product.PriceAdjustmentInput{Type: product.AdjustmentPercentage, Value: -50}, wantPrice: 275000, wantCompareAt: 300000, wantSale: 220000},
+	{name: "Fixed", input: product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: -100000, Tag: saleTag}, wantPrice: 450000, wantCompareAt: 500000, wantSale: 340000},
+}
+
+func (s *pricingSuite) TestAdjustmentScalesSaleAndCompareAt() {
+	for _, tc := range adjustmentSaleCases {
+		a, err := s.svc.CreatePriceAdjustment(s.ctx, tc.input)
+		s.Require().NoError(err, tc.name)
+		adjusted, err := s.svc.GetProduct(s.ctx, s.p.ID)
+		s.Require().NoError(err, tc.name)
+
+		result, revertErr := s.svc.RevertPriceAdjustment(s.ctx, a.ID, 0)
+		reverted, err := s.svc.GetProduct(s.ctx, s.p.ID)
+		s.Require().NoError(err, tc.name)
+
+		s.Equal(tc.wantPrice, adjusted.Price, tc.name)
+		s.Equal(tc.wantCompareAt, *adjusted.CompareAtPrice, tc.name)
+		s.Equal(tc.wantSale, *adjusted.SalePrice, tc.name)
+		s.Equal(tc.wantSale, *a.Items[0].NewSalePrice, tc.name)
+		s.Require().NoError(revertErr, tc.name)
+		s.Equal(1, result.Reverted, tc.name)
+		s.Equal(listPrice, reverted.Price, tc.name)
+		s.Equal(compareAtPrice, *reverted.CompareAtPrice, tc.name)
+		s.Equal(salePrice, *reverted.SalePrice, tc.name)
+	}
+}
+
+func (s *pricingSuite) TestAdjustmentRoundingSaleUpToPriceFails() {
+	_, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{
+		Type:    product.AdjustmentFixed,
+		Value:   -1000,
+		RoundTo: 1000000,
+	})
+	p, getErr := s.svc.GetProduct(s.ctx, s.p.ID)
+
+	s.ErrorIs(err, product.ErrValidation)
+	s.Require().NoError(getErr)
+	s.Equal(listPrice, p.Price)
+}
+
+func (s *pricingSuite) TestRevertSkipsSaleEditedSince() {
+	a, err := s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 10000})
+	s.Require().NoError(err)
+	_, err = s.svc.UpdateProduct(s.ctx, s.p.ID, product.UpdateProductInput{SalePrice: intPtr(0)})
+	s.Require().NoError(err)
+
+	result, err := s.svc.RevertPriceAdjustment(s.ctx, a.ID, 0)
+	p, getErr := s.svc.GetProduct(s.ctx, s.p.ID)
+
+	s.Require().NoError(err)
+	s.Require().NoError(getErr)
+	s.Zero(result.Reverted)
+	s.Len(result.Skipped, 1)
+	s.Equal(listPrice+10000, p.Price)
+	s.Nil(p.SalePrice)
+}
+
+func (s *pricingSuite) TestExportImportRoundTripsSale() {
+	startsAt := time.Date(2024, 11, 1, 3, 0, 0, 0, time.UTC)
+	_, err := s.svc.UpdateProduct(s.ctx, s.p.ID, product.UpdateProductInput{SaleStartsAt: &startsAt})
+	s.Require().NoError(err)
+	var buf bytes.Buffer
+	s.Require().NoError(s.svc.ExportProducts(s.ctx, &buf, product.FormatCSV, false))
+	records, err := product.ReadRecords(buf.Bytes(), product.FormatCSV)
+	s.Require().NoError(err)
+	other := product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+
+	result, err := other.ImportProducts(s.ctx, records, product.ImportOptions{})
+	s.Require().NoError(err)
+	imported, err := other.GetProductBySlug(s.ctx, s.p.Slug)
+
+	s.Require().NoError(err)
+	s.Equal(1, result.Created)
+	s.Equal(product.Columns, records[0])
+	s.Equal(compareAtPrice, *imported.CompareAtPrice)
+	s.Equal(salePrice, *imported.SalePrice)
+	s.True(startsAt.Equal(*imported.SaleStartsAt))
+	s.Nil(imported.SaleEndsAt)
+}
+
+var importPricingCases = []struct {
+	name    string
+	records [][]string
+}{
+	{name: "Sale not below price", records: [][]string{{"name", "price", "sale_price"}, {"Remera", "1000", "1000"}}},
+	{name: "Negative compare-at price", records: [][]string{{"name", "price", "compare_at_price"}, {"Remera", "1000", "-1"}}},
+	{name: "Malformed sale date", records: [][]string{{"name", "price", "sale_starts_at"}, {"Remera", "1000", "mañana"}}},
+	{name: "Sale ending before it starts", records: [][]string{{"name", "price", "sale_starts_at", "sale_ends_at"}, {"Remera", "1000", "2024-12-02", "2024-12-01"}}},
+}
+
+func (s *pricingSuite) TestImportRejectsInvalidPricing() {
+	for _, tc := range importPricingCases {
+		result, err := s.svc.ImportProducts(s.ctx, tc.records, product.ImportOptions{})
+
+		s.Require().NoError(err, tc.name)
+		s.False(result.Applied, tc.name)
+		s.Len(result.Errors, 1, tc.name)
+	}
+}
@@ -10,9 +10,11 @@ import (
 type Product struct {
 	ID          int64      `json:"id"`
 	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
 	Description string     `json:"description"`
 	Price       int        `json:"price"` // Price in cents
 	Category    string     `json:"category"`
+	CategoryID  *int64     `json:"category_id,omitempty"`
 	Images      []string   `json:"images"`
 	Tags        []string   `json:"tags"`
 	Sizes       []string   `json:"sizes"`
@@ -23,14 +25,28 @@ type Product struct {
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Stock       *int       `json:"stock,omitempty"` // On-hand from the inventory ledger, nil when untracked
+	OutOfStock  bool       `json:"out_of_stock"`
+	Variants    []*Variant `json:"variants,omitempty"`
+	Highlight   *Highlight `json:"highlight,omitempty"` // Set on search results
+
+	CompareAtPrice  *int       `json:"compare_at_price,omitempty"` // List price shown crossed out, in cents
+	SalePrice       *int       `json:"sale_price,omitempty"`       // Replaces price while the sale runs, in cents
+	SaleStartsAt    *time.Time `json:"sale_starts_at,omitempty"`   // Sale runs from creation when empty
+	SaleEndsAt      *time.Time `json:"sale_ends_at,omitempty"`     // Sale runs until removed when empty
+	EffectivePrice  int        `json:"effective_price"`            // Price charged right now
+	OriginalPrice   *int       `json:"original_price,omitempty"`   // Crossed-out price, set when discounted
+	DiscountPercent int        `json:"discount_percent,omitempty"`
 }
 
 // CreateProductInput represents input for creating a product
 type CreateProductInput struct {
 	Name        string   `json:"name"`
+	Slug        string   `json:"slug,omitempty"` // Derived from name when empty
 	Description string   `json:"description"`
 	Price       int      `json:"price"`
 	Category    string   `json:"category"`
+	CategoryID  *int64   `json:"category_id,omitempty"`
 	Images      []string `json:"images"`
 	Tags        []string `json:"tags"`
 	Sizes       []string `json:"sizes"`
@@ -38,14 +54,25 @@ type CreateProductInput struct {
 	Gender      string   `json:"gender"`
 	Oversize    bool     `json:"oversize"`
 	Featured    bool     `json:"featured"`
+
+	CompareAtPrice *int       `json:"compare_at_price,omitempty"`
+	SalePrice      *int       `json:"sale_price,omitempty"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty"`
+
+	Variants []CreateVariantInput `json:"variants,omitempty"`
+
+	AdminID int64 `json:"-"` // Taken from the authenticated admin
 }
 
 // UpdateProductInput represents input for updating a product
 type UpdateProductInput struct {
 	Name        *string   `json:"name,omitempty"`
+	Slug        *string   `json:"slug,omitempty"` // Empty derives the slug from the name again
 	Description *string   `json:"description,omitempty"`
 	Price       *int      `json:"price,omitempty"`
 	Category    *string   `json:"category,omitempty"`
+	CategoryID  *int64    `json:"category_id,omitempty"` // Takes precedence over category
 	Images      *[]string `json:"images,omitempty"`
 	Tags        *[]string `json:"tags,omitempty"`
 	Sizes       *[]string `json:"sizes,omitempty"`
@@ -53,6 +80,17 @@ type UpdateProductInput struct {
 	Gender      *string   `json:"gender,omitempty"`
 	Oversize    *bool     `json:"oversize,omitempty"`
 	Featured    *bool     `json:"featured,omitempty"`
+
+	CompareAtPrice *int       `json:"compare_at_price,omitempty"` // 0 removes the compare-at price
+	SalePrice      *int       `json:"sale_price,omitempty"`       // 0 ends the sale and clears its dates
+	SaleStartsAt   *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at,omitempty"`
+
+	AdminID int64 `json:"-"` // Taken from the authenticated admin
+
+	// replaceSaleDates sets the sale dates as given, clearing nil ones
+	// instead of leaving them unchanged, as rollbacks need
+	replaceSaleDates bool
 }
 
 // Validate validates product creation input
@@ -63,6 +101,23 @@ func (input *CreateProductInput) Validate() error {
 	if input.Price <= 0 {
 		return ErrInvalidInput("price must be greater than 0")
 	}
+	if err := validatePricing(input.CompareAtPrice, input.SalePrice, input.SaleStartsAt, input.SaleEndsAt); err != nil {
+		return err
+	}
+	if err := validateSalePrice(input.Price, input.SalePrice); err != nil {
+		return err
+	}
+	if input.SalePrice != nil && *input.SalePrice == 0 {
+		input.SalePrice = nil
+	}
+	if input.CompareAtPrice != nil && *input.CompareAtPrice == 0 {
+		input.CompareAtPrice = nil
+	}
+	for i := range input.Variants {
+		if err := input.Variants[i].Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -73,6 +128,10 @@ func scanProduct(row interface{ Scan(...interface{}) error }) (*Product, error)
 	var gender sql.NullString
 	var oversize, featured sql.NullInt64
 	var deletedAt sql.NullTime
+	var categoryID, stock sql.NullInt64
+	var productSlug sql.NullString
+	var compareAtPrice, salePrice sql.NullInt64
+	var saleStartsAt, saleEndsAt sql.NullTime
 
 	err := row.Scan(
 		&p.ID,
@@ -90,6 +149,13 @@ func scanProduct(row interface{ Scan(...interface{}) error }) (*Product, error)
 		&p.CreatedAt,
 		&p.UpdatedAt,
 		&deletedAt,
+		&categoryID,
+		&productSlug,
+		&compareAtPrice,
+		&salePrice,
+		&saleStartsAt,
+		&saleEndsAt,
+		&stock,
 	)
 	if err != nil {
 		return nil, err
@@ -124,6 +190,34 @@ func scanProduct(row interface{ Scan(...interface{}) error }) (*Product, error)
 		p.DeletedAt = &deletedAt.Time
 	}
 
+	if categoryID.Valid {
+		p.CategoryID = &categoryID.Int64
+	}
+
+	p.Slug = productSlug.String
+
+	if compareAtPrice.Valid {
+		price := int(compareAtPrice.Int64)
+		p.CompareAtPrice = &price
+	}
+	if salePrice.Valid {
+		price := int(salePrice.Int64)
+		p.SalePrice = &price
+	}
+	if saleStartsAt.Valid {
+		p.SaleStartsAt = &saleStartsAt.Time
+	}
+	if saleEndsAt.Valid {
+		p.SaleEndsAt = &saleEndsAt.Time
+	}
+	p.applyPricing(time.Now())
+
+	if stock.Valid {
+		onHand := int(stock.Int64)
+		p.Stock = &onHand
+		p.OutOfStock = onHand <= 0
+	}
+
 	// Initialize empty slices if nil
 	if p.Images == nil {
 		p.Images = []string{}
@@ -1,23 +1,117 @@
 package product
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines the interface for product data access
 type Repository interface {
 	// GetAll retrieves products with pagination and filters
 	GetAll(ctx context.Context, filters GetAllFilters) ([]*Product, int, error)
 	
+	// GetAllByCursor retrieves a page of products after or before an opaque cursor
+	GetAllByCursor(ctx context.Context, filters GetAllFilters, cursor string) (*CursorPage, error)
+
+	// GetFacets counts products per filter value for the given filters
+	GetFacets(ctx context.Context, filters GetAllFilters) (*Facets, error)
+
 	// GetByID retrieves a single product by ID
 	GetByID(ctx context.Context, id int64) (*Product, error)
 	
-	// Create creates a new product
+	// GetBySlug retrieves a single product by its current slug
+	GetBySlug(ctx context.Context, slug string) (*Product, error)
+
+	// GetCurrentSlug resolves a previous slug to the product's current slug
+	GetCurrentSlug(ctx context.Context, oldSlug string) (string, error)
+
+	// Create creates a new product and records its first revision
 	Create(ctx context.Context, input CreateProductInput) (*Product, error)
 	
-	// Update updates an existing product
+	// Update updates an existing product and records the revision
 	Update(ctx context.Context, id int64, input UpdateProductInput) (*Product, error)
 	
-	// SoftDelete marks a product as deleted
-	SoftDelete(ctx context.Context, id int64) error
+	// SoftDelete marks a product as deleted and records the revision
+	SoftDelete(ctx context.Context, id int64, adminID int64) error
+
+	// GetTrashed retrieves soft-deleted products, most recently deleted first
+	GetTrashed(ctx context.Context, page, limit int) ([]*Product, int, error)
+
+	// Restore clears the deletion mark of a soft-deleted product and records the revision
+	Restore(ctx context.Context, id int64, adminID int64) (*Product, error)
+
+	// Purge permanently removes products soft-deleted before the cutoff
+	Purge(ctx context.Context, deletedBefore time.Time) (*PurgeResult, error)
+
+	// ReferencedImages retrieves the upload filenames still in use by products, trashed or not, categories and the designer
+	ReferencedImages(ctx context.Context) (map[string]bool, error)
+
+	// GetIDs retrieves the IDs of all products matching the filters, ignoring pagination
+	GetIDs(ctx context.Context, filters GetAllFilters) ([]int64, error)
+
+	// Bulk applies an operation to many products in a single transaction
+	Bulk(ctx context.Context, ids []int64, input BulkInput) (*BulkResult, error)
+
+	// Import creates or updates a batch of products in a single transaction
+	Import(ctx context.Context, products []*ImportProduct, opts ImportOptions) (*ImportResult, error)
+
+	// Export streams every product with its variants to fn in ID order
+	Export(ctx context.Context, includeDeleted bool, fn func(*Product, []*Variant) error) error
+
+	// Rollback sets the fields of a product to a revision, moving it into or
+	// out of the trash as it was then, and records the rollback
+	Rollback(ctx context.Context, productID int64, rev *Revision, adminID int64) (*Product, error)
+
+	// GetRevisions retrieves all revisions of a product, oldest first
+	GetRevisions(ctx context.Context, productID int64) ([]*Revision, error)
+
+	// GetRevision retrieves a single revision of a product by its number
+	GetRevision(ctx context.Context, productID int64, number int) (*Revision, error)
+
+	// GetPriceHistory retrieves the price changes of a product, newest first
+	GetPriceHistory(ctx context.Context, productID int64) ([]*PriceChange, error)
+
+	// PreviewPriceAdjustment computes the prices an adjustment would change
+	PreviewPriceAdjustment(ctx context.Context, input PriceAdjustmentInput) ([]PriceAdjustmentItem, error)
+
+	// CreatePriceAdjustment records a price adjustment, applying it unless it is scheduled after now
+	CreatePriceAdjustment(ctx context.Context, input PriceAdjustmentInput, now time.Time) (*PriceAdjustment, error)
+
+	// GetPriceAdjustments retrieves all price adjustments, newest first
+	GetPriceAdjustments(ctx context.Context) ([]*PriceAdjustment, error)
+
+	// GetPriceAdjustment retrieves a price adjustment with its items
+	GetPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error)
+
+	// GetDuePriceAdjustments retrieves the IDs of scheduled adjustments due at now
+	GetDuePriceAdjustments(ctx context.Context, now time.Time) ([]int64, error)
+
+	// ApplyPriceAdjustment applies a scheduled adjustment
+	ApplyPriceAdjustment(ctx context.Context, id int64, now time.Time) (*PriceAdjustment, error)
+
+	// FailPriceAdjustment marks a scheduled adjustment as failed
+	FailPriceAdjustment(ctx context.Context, id int64, message string) error
+
+	// CancelPriceAdjustment cancels a scheduled adjustment
+	CancelPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error)
+
+	// RevertPriceAdjustment restores the prices changed by an applied adjustment
+	RevertPriceAdjustment(ctx context.Context, id int64, adminID int64, now time.Time) (*RevertResult, error)
+
+	// GetVariants retrieves all variants of a product
+	GetVariants(ctx context.Context, productID int64) ([]*Variant, error)
+
+	// GetVariantByID retrieves a single variant of a product
+	GetVariantByID(ctx context.Context, productID, variantID int64) (*Variant, error)
+
+	// CreateVariant adds a variant to an existing product
+	CreateVariant(ctx context.Context, productID int64, input CreateVariantInput) (*Variant, error)
+
+	// UpdateVariant updates an existing variant of a product
+	UpdateVariant(ctx context.Context, productID, variantID int64, input UpdateVariantInput) (*Variant, error)
+
+	// DeleteVariant removes a variant from a product
+	DeleteVariant(ctx context.Context, productID, variantID int64) error
 }
 
 // GetAllFilters contains filters for product listing
@@ -28,4 +122,15 @@ type GetAllFilters struct {
 	Category string
 	Sort     string // name, price, created_at
 	Order    string // asc, desc
+
+	Gender   string
+	Oversize *bool
+	Featured *bool
+	Tags     []string // any of
+	Sizes    []string // any of
+	Colors   []string // any of
+	MinPrice int      // in cents, 0 means no minimum
+	MaxPrice int      // in cents, 0 means no maximum
+
+	InStockOnly bool // hide products whose tracked stock is depleted
 }
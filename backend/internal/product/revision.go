@@ -0,0 +1,214 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// RevisionAction describes the change that produced a revision
+type RevisionAction string
+
+const (
+	RevisionCreate   RevisionAction = "create"
+	RevisionUpdate   RevisionAction = "update"
+	RevisionDelete   RevisionAction = "delete"
+	RevisionRestore  RevisionAction = "restore"
+	RevisionRollback RevisionAction = "rollback"
+)
+
+// Snapshot holds the editable fields of a product at a point in time
+type Snapshot struct {
+	Name        string   `json:"name"`
+	Slug        string   `json:"slug"`
+	Description string   `json:"description"`
+	Price       int      `json:"price"`
+	Category    string   `json:"category"`
+	CategoryID  *int64   `json:"category_id"`
+	Images      []string `json:"images"`
+	Tags        []string `json:"tags"`
+	Sizes       []string `json:"sizes"`
+	Colors      []string `json:"colors"`
+	Gender      string   `json:"gender"`
+	Oversize    bool     `json:"oversize"`
+	Featured    bool     `json:"featured"`
+	Deleted     bool     `json:"deleted"`
+
+	CompareAtPrice *int       `json:"compare_at_price"`
+	SalePrice      *int       `json:"sale_price"`
+	SaleStartsAt   *time.Time `json:"sale_starts_at"`
+	SaleEndsAt     *time.Time `json:"sale_ends_at"`
+}
+
+// Revision is a recorded snapshot of a product after a change
+type Revision struct {
+	ID             int64          `json:"id"`
+	ProductID      int64          `json:"product_id"`
+	Number         int            `json:"revision"`
+	Action         RevisionAction `json:"action"`
+	SourceRevision *int           `json:"source_revision,omitempty"` // Revision restored by a rollback
+	Snapshot       Snapshot       `json:"snapshot"`
+	AdminID        *int64         `json:"admin_id,omitempty"`
+	AdminUsername  string         `json:"admin_username,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Changes        []FieldChange  `json:"changes"` // Relative to the previous revision
+}
+
+// FieldChange is a single field difference between two revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// CreateRevisionInput represents input for recording a revision
+type CreateRevisionInput struct {
+	ProductID      int64
+	Action         RevisionAction
+	SourceRevision *int
+	Snapshot       Snapshot
+	AdminID        int64
+}
+
+// snapshotOf captures the editable fields of a product
+func snapshotOf(p *Product) Snapshot {
+	return Snapshot{
+		Name:        p.Name,
+		Slug:        p.Slug,
+		Description: p.Description,
+		Price:       p.Price,
+		Category:    p.Category,
+		CategoryID:  p.CategoryID,
+		Images:      nonNil(p.Images),
+		Tags:        nonNil(p.Tags),
+		Sizes:       nonNil(p.Sizes),
+		Colors:      nonNil(p.Colors),
+		Gender:      p.Gender,
+		Oversize:    p.Oversize,
+		Featured:    p.Featured,
+		Deleted:     p.DeletedAt != nil,
+
+		CompareAtPrice: p.CompareAtPrice,
+		SalePrice:      p.SalePrice,
+		SaleStartsAt:   p.SaleStartsAt,
+		SaleEndsAt:     p.SaleEndsAt,
+	}
+}
+
+// updateInput builds an update that sets every field of the snapshot,
+// clearing those the snapshot leaves empty
+func (s Snapshot) updateInput() UpdateProductInput {
+	images, tags, sizes, colors := s.Images, s.Tags, s.Sizes, s.Colors
+
+	// Zero prices remove the compare-at price and the sale
+	var compareAtPrice, salePrice int
+	if s.CompareAtPrice != nil {
+		compareAtPrice = *s.CompareAtPrice
+	}
+	if s.SalePrice != nil {
+		salePrice = *s.SalePrice
+	}
+
+	return UpdateProductInput{
+		Name:        &s.Name,
+		Slug:        &s.Slug,
+		Description: &s.Description,
+		Price:       &s.Price,
+		Category:    &s.Category,
+		CategoryID:  s.CategoryID,
+		Images:      &images,
+		Tags:        &tags,
+		Sizes:       &sizes,
+		Colors:      &colors,
+		Gender:      &s.Gender,
+		Oversize:    &s.Oversize,
+		Featured:    &s.Featured,
+
+		CompareAtPrice: &compareAtPrice,
+		SalePrice:      &salePrice,
+		SaleStartsAt:   s.SaleStartsAt,
+		SaleEndsAt:     s.SaleEndsAt,
+
+		replaceSaleDates: true,
+	}
+}
+
+// diffSnapshots lists the fields that differ between two snapshots by JSON name.
+// A nil previous snapshot reports every field as new.
+func diffSnapshots(prev *Snapshot, next Snapshot) []FieldChange {
+	var before map[string]interface{}
+	if prev != nil {
+		before = snapshotFields(*prev)
+	}
+	after := snapshotFields(next)
+
+	fields := make([]string, 0, len(after))
+	for field := range after {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []FieldChange{}
+	for _, field := range fields {
+		if prev != nil && reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, From: before[field], To: after[field]})
+	}
+	return changes
+}
+
+// snapshotFields converts a snapshot into a map keyed by JSON field name
+func snapshotFields(s Snapshot) map[string]interface{} {
+	data, _ := json.Marshal(s)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// nonNil returns an empty slice instead of nil so snapshots compare cleanly
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// scanRevision scans a database row into a Revision
+func scanRevision(row interface{ Scan(...interface{}) error }) (*Revision, error) {
+	var rev Revision
+	var snapshotJSON string
+	var source, adminID sql.NullInt64
+	var adminUsername sql.NullString
+
+	err := row.Scan(
+		&rev.ID,
+		&rev.ProductID,
+		&rev.Number,
+		&rev.Action,
+		&source,
+		&snapshotJSON,
+		&adminID,
+		&adminUsername,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(snapshotJSON), &rev.Snapshot); err != nil {
+		return nil, err
+	}
+	if source.Valid {
+		n := int(source.Int64)
+		rev.SourceRevision = &n
+	}
+	if adminID.Valid {
+		rev.AdminID = &adminID.Int64
+	}
+	rev.AdminUsername = adminUsername.String
+
+	return &rev, nil
+}
@@ -0,0 +1,205 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const (
+	revisionName    = "Remera Básica"
+	revisionRename  = "Remera Clásica"
+	revisionPrice   = 550000
+	revisionRaise   = 600000
+	revisionAdminID = int64(0)
+	revisionSale    = 500000
+)
+
+type revisionSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+}
+
+func TestRevisionSuite(t *testing.T) {
+	suite.Run(t, new(revisionSuite))
+}
+
+func (s *revisionSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+}
+
+// create creates the product every test starts from
+func (s *revisionSuite) create() *product.Product {
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: revisionName, Price: revisionPrice})
+	s.Require().NoError(err)
+	return p
+}
+
+// actions returns the actions of the revisions of a product, oldest first
+func (s *revisionSuite) actions(productID int64) []product.RevisionAction {
+	revisions, err := s.svc.GetRevisions(s.ctx, productID)
+	s.Require().NoError(err)
+
+	actions := make([]product.RevisionAction, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		actions = append(actions, revisions[i].Action)
+	}
+	return actions
+}
+
+func (s *revisionSuite) TestEveryChangeRecordsARevision() {
+	p := s.create()
+	price := revisionRaise
+
+	_, updateErr := s.svc.UpdateProduct(s.ctx, p.ID, product.UpdateProductInput{Price: &price})
+	deleteErr := s.svc.DeleteProduct(s.ctx, p.ID, revisionAdminID)
+	_, restoreErr := s.svc.RestoreProduct(s.ctx, p.ID, revisionAdminID)
+
+	s.Require().NoError(updateErr)
+	s.Require().NoError(deleteErr)
+	s.Require().NoError(restoreErr)
+	s.Equal([]product.RevisionAction{
+		product.RevisionCreate,
+		product.RevisionUpdate,
+		product.RevisionDelete,
+		product.RevisionRestore,
+	}, s.actions(p.ID))
+}
+
+func (s *revisionSuite) TestRevisionsSnapshotTheCommittedProduct() {
+	p := s.create()
+	name := revisionRename
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, revisionAdminID))
+	_, err := s.svc.RestoreProduct(s.ctx, p.ID, revisionAdminID)
+	s.Require().NoError(err)
+
+	_, err = s.svc.UpdateProduct(s.ctx, p.ID, product.UpdateProductInput{Name: &name})
+	s.Require().NoError(err)
+	revisions, err := s.svc.GetRevisions(s.ctx, p.ID)
+
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 4)
+	s.Equal(revisionRename, revisions[0].Snapshot.Name)
+	s.False(revisions[1].Snapshot.Deleted)
+	s.True(revisions[2].Snapshot.Deleted)
+	s.Equal(revisionName, revisions[3].Snapshot.Name)
+}
+
+func (s *revisionSuite) TestFailedChangesRecordNoRevision() {
+	p := s.create()
+
+	_, createErr := s.svc.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     revisionRename,
+		Price:    revisionPrice,
+		Variants: []product.CreateVariantInput{{Size: "M"}, {Size: "M"}},
+	})
+	deleteErr := s.svc.DeleteProduct(s.ctx, p.ID+1, revisionAdminID)
+	_, restoreErr := s.svc.RestoreProduct(s.ctx, p.ID, revisionAdminID)
+	_, revisionsErr := s.svc.GetRevisions(s.ctx, p.ID+1)
+
+	s.Error(createErr)
+	s.ErrorIs(deleteErr, product.ErrNotFound)
+	s.ErrorIs(restoreErr, product.ErrNotFound)
+	s.ErrorIs(revisionsErr, product.ErrNotFound)
+	s.Equal([]product.RevisionAction{product.RevisionCreate}, s.actions(p.ID))
+}
+
+func (s *revisionSuite) TestRollbackRestoresTrashedProduct() {
+	p := s.create()
+	price := revisionRaise
+	_, err := s.svc.UpdateProduct(s.ctx, p.ID, product.UpdateProductInput{Price: &price})
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, revisionAdminID))
+
+	rolledBack, err := s.svc.RollbackProduct(s.ctx, p.ID, 1, revisionAdminID)
+	s.Require().NoError(err)
+	revisions, err := s.svc.GetRevisions(s.ctx, p.ID)
+
+	s.Require().NoError(err)
+	s.Equal(revisionPrice, rolledBack.Price)
+	s.Nil(rolledBack.DeletedAt)
+	s.Equal(product.RevisionRollback, revisions[0].Action)
+	s.Equal(1, *revisions[0].SourceRevision)
+	s.Equal(revisionPrice, revisions[0].Snapshot.Price)
+	s.False(revisions[0].Snapshot.Deleted)
+}
+
+func (s *revisionSuite) TestRollbackClearsFieldsSetSince() {
+	sale := revisionSale
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: revisionName, Price: revisionPrice, SalePrice: &sale})
+	s.Require().NoError(err)
+	starts, ends := time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+	compareAt, category, tags := revisionRaise, "Remeras", []string{"verano"}
+	_, err = s.svc.UpdateProduct(s.ctx, p.ID, product.UpdateProductInput{
+		CompareAtPrice: &compareAt,
+		SaleStartsAt:   &starts,
+		SaleEndsAt:     &ends,
+		Category:       &category,
+		Tags:           &tags,
+	})
+	s.Require().NoError(err)
+
+	rolledBack, err := s.svc.RollbackProduct(s.ctx, p.ID, 1, revisionAdminID)
+	s.Require().NoError(err)
+	revisions, err := s.svc.GetRevisions(s.ctx, p.ID)
+
+	s.Require().NoError(err)
+	s.Equal(revisionSale, *rolledBack.SalePrice)
+	s.Nil(rolledBack.SaleStartsAt)
+	s.Nil(rolledBack.SaleEndsAt)
+	s.Nil(rolledBack.CompareAtPrice)
+	s.Empty(rolledBack.Category)
+	s.Nil(rolledBack.CategoryID)
+	s.Empty(rolledBack.Tags)
+	s.Equal(revisions[2].Snapshot, revisions[0].Snapshot)
+}
+
+func (s *revisionSuite) TestRollbackToDeletedRevision() {
+	p := s.create()
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, revisionAdminID))
+	trashed, _, err := s.svc.ListTrash(s.ctx, 1, 10)
+	s.Require().NoError(err)
+	_, err = s.svc.RestoreProduct(s.ctx, p.ID, revisionAdminID)
+	s.Require().NoError(err)
+	price := revisionRaise
+	_, err = s.svc.UpdateProduct(s.ctx, p.ID, product.UpdateProductInput{Price: &price})
+	s.Require().NoError(err)
+
+	_, rollbackErr := s.svc.RollbackProduct(s.ctx, p.ID, 2, revisionAdminID)
+	_, getErr := s.svc.GetProduct(s.ctx, p.ID)
+	deletedAgain, _, trashErr := s.svc.ListTrash(s.ctx, 1, 10)
+	// Rolling back while in the trash keeps the product there and its deletion time
+	_, againErr := s.svc.RollbackProduct(s.ctx, p.ID, 2, revisionAdminID)
+	stillTrashed, _, stillErr := s.svc.ListTrash(s.ctx, 1, 10)
+	revisions, revisionsErr := s.svc.GetRevisions(s.ctx, p.ID)
+
+	s.Require().NoError(rollbackErr)
+	s.ErrorIs(getErr, product.ErrNotFound)
+	s.Require().NoError(trashErr)
+	s.Require().Len(deletedAgain, 1)
+	s.Equal(revisionPrice, deletedAgain[0].Price)
+	s.True(deletedAgain[0].DeletedAt.After(*trashed[0].DeletedAt))
+	s.Require().NoError(againErr)
+	s.Require().NoError(stillErr)
+	s.Require().Len(stillTrashed, 1)
+	s.True(stillTrashed[0].DeletedAt.Equal(*deletedAgain[0].DeletedAt))
+	s.Require().NoError(revisionsErr)
+	s.True(revisions[0].Snapshot.Deleted)
+	s.True(revisions[1].Snapshot.Deleted)
+}
+
+func (s *revisionSuite) TestRollbackToMissingRevision() {
+	p := s.create()
+
+	_, err := s.svc.RollbackProduct(s.ctx, p.ID, 2, revisionAdminID)
+
+	s.ErrorIs(err, product.ErrRevisionNotFound)
+	s.Equal([]product.RevisionAction{product.RevisionCreate}, s.actions(p.ID))
+}
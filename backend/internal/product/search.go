@@ -0,0 +1,70 @@
+package product
+
+import (
+	"database/sql"
+	"strings"
+	"unicode"
+)
+
+// ftsMatchQuery selects ranked matches and highlighted fragments from products_fts.
+// bm25 weights favour name over tags, category and description.
+const ftsMatchQuery = `
+	SELECT rowid,
+		bm25(products_fts, 10.0, 2.0, 5.0, 3.0) AS score,
+		highlight(products_fts, 0, '<mark>', '</mark>') AS name_hl,
+		snippet(products_fts, 1, '<mark>', '</mark>', '…', 16) AS description_hl
+	FROM products_fts
+	WHERE products_fts MATCH ?`
+
+// Highlight contains search matches wrapped in <mark> tags
+type Highlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// buildFTSQuery turns free text into an FTS5 query where every word must
+// match as a prefix. Accents are folded by the FTS tokenizer itself.
+// It returns an empty string when the input has no searchable words.
+func buildFTSQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+
+	return strings.Join(terms, " AND ")
+}
+
+// scanSearchResult scans a product row followed by its name and description highlights
+func scanSearchResult(row interface{ Scan(...interface{}) error }) (*Product, error) {
+	var nameHL, descriptionHL sql.NullString
+
+	p, err := scanProduct(extraScanner{row: row, extra: []interface{}{&nameHL, &descriptionHL}})
+	if err != nil {
+		return nil, err
+	}
+
+	if nameHL.Valid || descriptionHL.Valid {
+		p.Highlight = &Highlight{
+			Name:        nameHL.String,
+			Description: descriptionHL.String,
+		}
+	}
+
+	return p, nil
+}
+
+// extraScanner appends extra destinations after those passed to Scan,
+// so scanProduct can be reused for queries selecting additional columns
+type extraScanner struct {
+	row   interface{ Scan(...interface{}) error }
+	extra []interface{}
+}
+
+// Scan implements the row scanner interface
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
@@ -0,0 +1,120 @@
+package product_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// Products the search tests look for
+const (
+	virgin  = "Virgen María"
+	gaucho  = "Gauchito Gil"
+	printed = "Remera Estampada"
+)
+
+type searchSuite struct {
+	suite.Suite
+	ctx context.Context
+	db  *sql.DB
+	svc *product.Service
+	ids map[string]int64
+}
+
+func TestSearchSuite(t *testing.T) {
+	suite.Run(t, new(searchSuite))
+}
+
+func (s *searchSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.db = testdouble.NewDB(s.T())
+	s.svc = product.NewService(product.NewSQLiteRepository(s.db))
+
+	s.ids = make(map[string]int64)
+	for _, input := range []product.CreateProductInput{
+		{Name: virgin, Description: "Estampa de la Virgen en remera blanca", Price: 550000, Tags: []string{"religioso"}},
+		{Name: gaucho, Description: "El santo popular correntino", Price: 550000, Tags: []string{"santos populares"}},
+		{Name: printed, Description: "Con la imagen de María Auxiliadora", Price: 450000, Category: "Remeras"},
+	} {
+		p, err := s.svc.CreateProduct(s.ctx, input)
+		s.Require().NoError(err)
+		s.ids[input.Name] = p.ID
+	}
+}
+
+// search returns the names of the products found for a search, best first
+func (s *searchSuite) search(text string) []string {
+	products, _, err := s.svc.GetProducts(s.ctx, product.GetAllFilters{Search: text})
+	s.Require().NoError(err)
+
+	names := []string{}
+	for _, p := range products {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// indexed counts the rows of the full-text index matching a query
+func (s *searchSuite) indexed(query string) int {
+	var count int
+	s.Require().NoError(s.db.QueryRow("SELECT COUNT(*) FROM products_fts WHERE products_fts MATCH ?", query).Scan(&count))
+	return count
+}
+
+var searchCases = []struct {
+	name   string
+	search string
+	want   []string
+}{
+	{name: "Without the accent", search: "maria", want: []string{virgin, printed}},
+	{name: "With the accent and in capitals", search: "MARÍA", want: []string{virgin, printed}},
+	{name: "Word prefix", search: "gauch", want: []string{gaucho}},
+	{name: "Every word must match", search: "virgen remera", want: []string{virgin}},
+	{name: "Tags", search: "populares", want: []string{gaucho}},
+	{name: "Category", search: "remeras", want: []string{printed}},
+	{name: "No match", search: "difunta", want: []string{}},
+}
+
+func (s *searchSuite) TestSearch() {
+	for _, tc := range searchCases {
+		s.Equal(tc.want, s.search(tc.search), tc.name)
+	}
+}
+
+func (s *searchSuite) TestSearchHighlightsMatches() {
+	products, _, err := s.svc.GetProducts(s.ctx, product.GetAllFilters{Search: "maria"})
+
+	s.Require().NoError(err)
+	s.Require().NotEmpty(products)
+	s.Require().NotNil(products[0].Highlight)
+	s.Equal("Virgen <mark>María</mark>", products[0].Highlight.Name)
+}
+
+func (s *searchSuite) TestSearchFollowsUpdates() {
+	name, tags := "Gaucho Antonio Gil", []string{"correntino"}
+
+	_, err := s.svc.UpdateProduct(s.ctx, s.ids[gaucho], product.UpdateProductInput{Name: &name, Tags: &tags})
+	s.Require().NoError(err)
+
+	s.Empty(s.search("gauchito"))
+	s.Empty(s.search("populares"))
+	s.Equal([]string{name}, s.search("antonio"))
+	s.Equal(1, s.indexed("antonio"))
+}
+
+func (s *searchSuite) TestSearchFollowsDeletes() {
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, s.ids[virgin], 0))
+
+	trashed := s.search("maria")
+	_, err := s.svc.PurgeTrash(s.ctx, 0)
+	s.Require().NoError(err)
+
+	s.Equal([]string{printed}, trashed)
+	s.Equal([]string{printed}, s.search("maria"))
+	s.Zero(s.indexed("virgen"))
+}
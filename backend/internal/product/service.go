@@ -1,6 +1,13 @@
 package product
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
 
 // Service provides business logic for products
 type Service struct {
@@ -17,9 +24,42 @@ func (s *Service) GetProducts(ctx context.Context, filters GetAllFilters) ([]*Pr
 	return s.repo.GetAll(ctx, filters)
 }
 
-// GetProduct retrieves a single product by ID
+// GetProductsByCursor retrieves a page of products using keyset pagination
+func (s *Service) GetProductsByCursor(ctx context.Context, filters GetAllFilters, cursor string) (*CursorPage, error) {
+	return s.repo.GetAllByCursor(ctx, filters, cursor)
+}
+
+// GetFacets retrieves filter value counts for the given filters
+func (s *Service) GetFacets(ctx context.Context, filters GetAllFilters) (*Facets, error) {
+	return s.repo.GetFacets(ctx, filters)
+}
+
+// GetProduct retrieves a single product by ID including its variants
 func (s *Service) GetProduct(ctx context.Context, id int64) (*Product, error) {
-	return s.repo.GetByID(ctx, id)
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVariants(ctx, p)
+}
+
+// GetProductBySlug retrieves a single product by slug including its variants.
+// Previous slugs of renamed products return a *SlugMovedError with the current slug.
+func (s *Service) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	p, err := s.repo.GetBySlug(ctx, slug)
+	if errors.Is(err, ErrNotFound) {
+		current, lookupErr := s.repo.GetCurrentSlug(ctx, slug)
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		return nil, &SlugMovedError{Slug: current}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVariants(ctx, p)
 }
 
 // CreateProduct creates a new product with validation
@@ -29,7 +69,12 @@ func (s *Service) CreateProduct(ctx context.Context, input CreateProductInput) (
 		return nil, err
 	}
 	
-	return s.repo.Create(ctx, input)
+	p, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVariants(ctx, p)
 }
 
 // UpdateProduct updates an existing product
@@ -38,11 +83,306 @@ func (s *Service) UpdateProduct(ctx context.Context, id int64, input UpdateProdu
 	if input.Price != nil && *input.Price <= 0 {
 		return nil, ErrInvalidInput("price must be greater than 0")
 	}
+	if err := validatePricing(input.CompareAtPrice, input.SalePrice, input.SaleStartsAt, input.SaleEndsAt); err != nil {
+		return nil, err
+	}
 	
 	return s.repo.Update(ctx, id, input)
 }
 
 // DeleteProduct soft-deletes a product
-func (s *Service) DeleteProduct(ctx context.Context, id int64) error {
-	return s.repo.SoftDelete(ctx, id)
+func (s *Service) DeleteProduct(ctx context.Context, id int64, adminID int64) error {
+	return s.repo.SoftDelete(ctx, id, adminID)
+}
+
+// ListTrash retrieves soft-deleted products
+func (s *Service) ListTrash(ctx context.Context, page, limit int) ([]*Product, int, error) {
+	return s.repo.GetTrashed(ctx, page, limit)
+}
+
+// RestoreProduct undoes the soft deletion of a product
+func (s *Service) RestoreProduct(ctx context.Context, id int64, adminID int64) (*Product, error) {
+	p, err := s.repo.Restore(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVariants(ctx, p)
+}
+
+// PurgeTrash permanently removes products that have been in the trash
+// for longer than the retention period
+func (s *Service) PurgeTrash(ctx context.Context, retention time.Duration) (*PurgeResult, error) {
+	if retention < 0 {
+		return nil, ErrInvalidInput("retention cannot be negative")
+	}
+
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
+}
+
+// ReferencedImages retrieves the upload filenames still in use. Images of
+// trashed products count, since restoring them brings the images back.
+func (s *Service) ReferencedImages(ctx context.Context) (map[string]bool, error) {
+	return s.repo.ReferencedImages(ctx)
+}
+
+// BulkUpdate applies an operation to the listed or filtered products. Either
+// every product succeeds or nothing is saved; each one is reported either way.
+func (s *Service) BulkUpdate(ctx context.Context, input BulkInput) (*BulkResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	ids := input.IDs
+	if input.Filter != nil {
+		var err error
+		if ids, err = s.repo.GetIDs(ctx, input.Filter.filters()); err != nil {
+			return nil, err
+		}
+		if len(ids) > MaxBulkItems {
+			return nil, ErrInvalidInput(fmt.Sprintf("filter matches %d products, at most %d allowed", len(ids), MaxBulkItems))
+		}
+	}
+
+	// Each product is processed once even if listed twice
+	seen := make(map[int64]bool, len(ids))
+	unique := ids[:0:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return s.repo.Bulk(ctx, unique, input)
+}
+
+// ImportProducts creates or updates products from the rows of an import
+// file. Nothing is saved when any row is invalid or opts.DryRun is set.
+func (s *Service) ImportProducts(ctx context.Context, records [][]string, opts ImportOptions) (*ImportResult, error) {
+	products, errs := parseImport(records)
+
+	// Still run valid rows against the database to report all errors at once
+	dryRun := opts.DryRun
+	if len(errs) > 0 {
+		opts.DryRun = true
+	}
+
+	result, err := s.repo.Import(ctx, products, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.DryRun = dryRun
+
+	if len(errs) > 0 {
+		result.Errors = append(errs, result.Errors...)
+		sortImportErrors(result.Errors)
+	}
+
+	return result, nil
+}
+
+// ExportProducts writes the catalog to w as CSV, JSON Lines or XLSX using
+// the import column layout. Soft-deleted products are only included when
+// includeDeleted is set, with an extra deleted_at column.
+func (s *Service) ExportProducts(ctx context.Context, w io.Writer, format string, includeDeleted bool) error {
+	columns := Columns
+	if includeDeleted {
+		columns = append(append([]string{}, Columns...), DeletedColumn)
+	}
+
+	rw, err := newRowWriter(w, format, columns)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Export(ctx, includeDeleted, func(p *Product, variants []*Variant) error {
+		for _, row := range exportRows(p, variants, includeDeleted) {
+			if err := rw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return rw.Close()
+}
+
+// GetRevisions retrieves the revision history of a product, newest first,
+// with the fields each revision changed
+func (s *Service) GetRevisions(ctx context.Context, productID int64) ([]*Revision, error) {
+	revisions, err := s.repo.GetRevisions(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var prev *Snapshot
+	for _, rev := range revisions {
+		rev.Changes = diffSnapshots(prev, rev.Snapshot)
+		prev = &rev.Snapshot
+	}
+
+	reverse(revisions)
+
+	return revisions, nil
+}
+
+// RollbackProduct restores the fields of a product to a previous revision.
+// The product goes back to the trash, or out of it, as it was then.
+func (s *Service) RollbackProduct(ctx context.Context, productID int64, number int, adminID int64) (*Product, error) {
+	rev, err := s.repo.GetRevision(ctx, productID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.repo.Rollback(ctx, productID, rev, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withVariants(ctx, p)
+}
+
+// PreviewPriceAdjustment lists the old and new prices an adjustment would set
+func (s *Service) PreviewPriceAdjustment(ctx context.Context, input PriceAdjustmentInput) (*PriceAdjustmentPreview, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.PreviewPriceAdjustment(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make(map[int64]bool)
+	for _, item := range items {
+		products[item.ProductID] = true
+	}
+
+	return &PriceAdjustmentPreview{Products: len(products), Items: items}, nil
+}
+
+// CreatePriceAdjustment applies a price adjustment right away, or schedules
+// it when ScheduledAt is in the future
+func (s *Service) CreatePriceAdjustment(ctx context.Context, input PriceAdjustmentInput) (*PriceAdjustment, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreatePriceAdjustment(ctx, input, time.Now())
+}
+
+// ListPriceAdjustments retrieves all price adjustments, newest first
+func (s *Service) ListPriceAdjustments(ctx context.Context) ([]*PriceAdjustment, error) {
+	return s.repo.GetPriceAdjustments(ctx)
+}
+
+// GetPriceAdjustment retrieves a price adjustment with the prices it changed
+func (s *Service) GetPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error) {
+	return s.repo.GetPriceAdjustment(ctx, id)
+}
+
+// CancelPriceAdjustment cancels a scheduled price adjustment
+func (s *Service) CancelPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error) {
+	return s.repo.CancelPriceAdjustment(ctx, id)
+}
+
+// RevertPriceAdjustment restores the prices an applied adjustment changed
+func (s *Service) RevertPriceAdjustment(ctx context.Context, id int64, adminID int64) (*RevertResult, error) {
+	return s.repo.RevertPriceAdjustment(ctx, id, adminID, time.Now())
+}
+
+// ApplyDuePriceAdjustments applies the scheduled adjustments whose time has
+// come. Adjustments that turn out to be invalid, such as ones leaving a
+// product without a price, are marked as failed; other errors leave the
+// adjustment scheduled so the next run retries it.
+func (s *Service) ApplyDuePriceAdjustments(ctx context.Context) ([]*PriceAdjustment, error) {
+	now := time.Now()
+
+	ids, err := s.repo.GetDuePriceAdjustments(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var processed []*PriceAdjustment
+	for _, id := range ids {
+		a, err := s.repo.ApplyPriceAdjustment(ctx, id, now)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrValidation):
+			message := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+			if err := s.repo.FailPriceAdjustment(ctx, id, message); err != nil {
+				return processed, err
+			}
+			if a, err = s.repo.GetPriceAdjustment(ctx, id); err != nil {
+				return processed, err
+			}
+		case errors.Is(err, ErrAdjustmentStatus):
+			// Cancelled or applied since it was listed
+			continue
+		default:
+			return processed, err
+		}
+		processed = append(processed, a)
+	}
+
+	return processed, nil
+}
+
+// GetPriceHistory retrieves the price changes of a product, newest first
+func (s *Service) GetPriceHistory(ctx context.Context, productID int64) ([]*PriceChange, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPriceHistory(ctx, productID)
+}
+
+// GetVariants retrieves the variants of a product
+func (s *Service) GetVariants(ctx context.Context, productID int64) ([]*Variant, error) {
+	if _, err := s.repo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetVariants(ctx, productID)
+}
+
+// CreateVariant adds a variant to a product with validation
+func (s *Service) CreateVariant(ctx context.Context, productID int64, input CreateVariantInput) (*Variant, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateVariant(ctx, productID, input)
+}
+
+// UpdateVariant updates a variant of a product with validation
+func (s *Service) UpdateVariant(ctx context.Context, productID, variantID int64, input UpdateVariantInput) (*Variant, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateVariant(ctx, productID, variantID, input)
+}
+
+// DeleteVariant removes a variant from a product
+func (s *Service) DeleteVariant(ctx context.Context, productID, variantID int64) error {
+	return s.repo.DeleteVariant(ctx, productID, variantID)
+}
+
+// withVariants loads and attaches the variant matrix to a product
+func (s *Service) withVariants(ctx context.Context, p *Product) (*Product, error) {
+	variants, err := s.repo.GetVariants(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.Variants = variants
+
+	return p, nil
 }
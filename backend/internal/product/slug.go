@@ -0,0 +1,76 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// uniqueSlug derives a slug from name that no other product uses or used before,
+// appending -2, -3... on collisions. productID is 0 for new products.
+func uniqueSlug(ctx context.Context, db queryRower, name string, productID int64) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "producto"
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		var taken bool
+		err := db.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM products WHERE slug = ? AND id != ?)
+				OR EXISTS (SELECT 1 FROM product_slug_history WHERE slug = ? AND product_id != ?)
+		`, candidate, productID, candidate, productID).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// generatedSlug reports whether productSlug is one uniqueSlug derives from
+// name, rather than one an admin chose
+func generatedSlug(productSlug, name string) bool {
+	base := slug.Make(name)
+	if base == "" {
+		base = "producto"
+	}
+	if productSlug == base {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(productSlug, base+"-")
+	n, err := strconv.Atoi(suffix)
+	return ok && err == nil && n >= 2 && strconv.Itoa(n) == suffix
+}
+
+// retireSlug records oldSlug in the product's slug history so it keeps
+// resolving, and drops newSlug from the history when a product gets it back
+func retireSlug(ctx context.Context, db execer, productID int64, oldSlug, newSlug string) error {
+	if oldSlug != "" {
+		_, err := db.ExecContext(ctx,
+			"INSERT OR REPLACE INTO product_slug_history (slug, product_id, created_at) VALUES (?, ?, ?)",
+			oldSlug, productID, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record slug history: %w", err)
+		}
+	}
+
+	_, err := db.ExecContext(ctx,
+		"DELETE FROM product_slug_history WHERE slug = ? AND product_id = ?",
+		newSlug, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update slug history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+const slugPrice = 550000
+
+type slugSuite struct {
+	suite.Suite
+	ctx context.Context
+	svc *product.Service
+}
+
+func TestSlugSuite(t *testing.T) {
+	suite.Run(t, new(slugSuite))
+}
+
+func (s *slugSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(testdouble.NewDB(s.T())))
+}
+
+// create creates a product, with a chosen slug when given one
+func (s *slugSuite) create(name, productSlug string) *product.Product {
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: name, Slug: productSlug, Price: slugPrice})
+	s.Require().NoError(err)
+	return p
+}
+
+// update applies an update and returns the product
+func (s *slugSuite) update(id int64, input product.UpdateProductInput) *product.Product {
+	p, err := s.svc.UpdateProduct(s.ctx, id, input)
+	s.Require().NoError(err)
+	return p
+}
+
+// strPtr returns a pointer to a string
+func strPtr(v string) *string {
+	return &v
+}
+
+func (s *slugSuite) TestSlugsAreUniqueAndAccentFree() {
+	first := s.create("Virgen María", "")
+	second := s.create("Virgen Maria", "")
+
+	s.Equal("virgen-maria", first.Slug)
+	s.Equal("virgen-maria-2", second.Slug)
+}
+
+var renameCases = []struct {
+	name     string
+	slug     string // Chosen on creation, empty to generate it
+	rename   string
+	wantSlug string
+}{
+	{name: "Gauchito Gil", rename: "Gauchito Gil Rojo", wantSlug: "gauchito-gil-rojo"},
+	{name: "San Expedito", slug: "expedito", rename: "San Expedito Negro", wantSlug: "expedito"},
+	{name: "Difunta Correa", slug: "difunta-correa-2", rename: "Difunta Correa Azul", wantSlug: "difunta-correa-azul"},
+	{name: "San Cayetano 2024", slug: "san-cayetano", rename: "San Cayetano 2025", wantSlug: "san-cayetano"},
+}
+
+func (s *slugSuite) TestRenameKeepsChosenSlugs() {
+	for _, tc := range renameCases {
+		p := s.create(tc.name, tc.slug)
+
+		renamed := s.update(p.ID, product.UpdateProductInput{Name: strPtr(tc.rename)})
+
+		s.Equal(tc.wantSlug, renamed.Slug, tc.name)
+	}
+}
+
+func (s *slugSuite) TestUpdateSetsSlug() {
+	p := s.create("Remera Lisa", "")
+	taken := s.create("Remera Estampada", "")
+
+	chosen := s.update(p.ID, product.UpdateProductInput{Slug: strPtr("Remera Básica")})
+	collided := s.update(p.ID, product.UpdateProductInput{Slug: strPtr(taken.Slug)})
+	regenerated := s.update(p.ID, product.UpdateProductInput{Slug: strPtr("")})
+	old, err := s.svc.GetProductBySlug(s.ctx, "remera-basica")
+
+	s.Equal("remera-basica", chosen.Slug)
+	s.Equal("remera-estampada-2", collided.Slug)
+	s.Equal("remera-lisa", regenerated.Slug)
+	s.Nil(old)
+	var moved *product.SlugMovedError
+	s.Require().ErrorAs(err, &moved)
+	s.Equal("remera-lisa", moved.Slug)
+}
+
+func (s *slugSuite) TestOldSlugsMoveToTheCurrentOne() {
+	p := s.create("Gauchito Gil", "")
+	s.update(p.ID, product.UpdateProductInput{Name: strPtr("Gauchito Gil Rojo")})
+	s.update(p.ID, product.UpdateProductInput{Name: strPtr("Gauchito Gil Azul")})
+
+	_, firstErr := s.svc.GetProductBySlug(s.ctx, "gauchito-gil")
+	_, secondErr := s.svc.GetProductBySlug(s.ctx, "gauchito-gil-rojo")
+	current, currentErr := s.svc.GetProductBySlug(s.ctx, "gauchito-gil-azul")
+	_, missingErr := s.svc.GetProductBySlug(s.ctx, "san-la-muerte")
+	// Another product cannot take a slug that still redirects
+	other := s.create("Gauchito Gil", "")
+
+	var moved *product.SlugMovedError
+	s.Require().ErrorAs(firstErr, &moved)
+	s.Equal("gauchito-gil-azul", moved.Slug)
+	s.Require().ErrorAs(secondErr, &moved)
+	s.Equal("gauchito-gil-azul", moved.Slug)
+	s.Require().NoError(currentErr)
+	s.Equal(p.ID, current.ID)
+	s.ErrorIs(missingErr, product.ErrNotFound)
+	s.Equal("gauchito-gil-2", other.Slug)
+}
+
+func (s *slugSuite) TestRollbackRestoresSlug() {
+	p := s.create("Remera Lisa", "remera-clasica")
+	s.update(p.ID, product.UpdateProductInput{Slug: strPtr("remera-nueva")})
+
+	rolledBack, err := s.svc.RollbackProduct(s.ctx, p.ID, 1, 0)
+
+	s.Require().NoError(err)
+	s.Equal("remera-clasica", rolledBack.Slug)
+}
+
+func (s *slugSuite) TestBulkCannotSetSlug() {
+	p := s.create("Remera Lisa", "")
+
+	_, err := s.svc.BulkUpdate(s.ctx, product.BulkInput{
+		IDs:       []int64{p.ID},
+		Operation: product.BulkUpdate,
+		Update:    &product.UpdateProductInput{Slug: strPtr("remera")},
+	})
+
+	s.ErrorIs(err, product.ErrValidation)
+}
@@ -0,0 +1,516 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const adjustmentColumns = `id, type, value, category, tag, round_to, round_mode, note, status, error,
+	scheduled_at, applied_at, reverted_at, admin_id, created_at`
+
+// PreviewPriceAdjustment computes the prices an adjustment would change without saving anything
+func (r *SQLiteRepository) PreviewPriceAdjustment(ctx context.Context, input PriceAdjustmentInput) ([]PriceAdjustmentItem, error) {
+	return adjustmentItems(ctx, r.db, input)
+}
+
+// CreatePriceAdjustment records a price adjustment. Adjustments without a
+// future schedule are applied in the same transaction, so a failing
+// adjustment leaves no trace.
+func (r *SQLiteRepository) CreatePriceAdjustment(ctx context.Context, input PriceAdjustmentInput, now time.Time) (*PriceAdjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var scheduledAt interface{}
+	if input.ScheduledAt != nil {
+		scheduledAt = input.ScheduledAt.UTC().Truncate(time.Second)
+	}
+	var adminID interface{}
+	if input.AdminID != 0 {
+		adminID = input.AdminID
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO price_adjustments (type, value, category, tag, round_to, round_mode, note, status, scheduled_at, admin_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Type, input.Value, input.Category, input.Tag, input.RoundTo, input.RoundMode, input.Note,
+		AdjustmentScheduled, scheduledAt, adminID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert price adjustment: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price adjustment ID: %w", err)
+	}
+
+	if input.ScheduledAt == nil || !input.ScheduledAt.After(now) {
+		if err := applyAdjustment(ctx, tx, id, now); err != nil {
+			return nil, err
+		}
+	}
+
+	a, err := getAdjustment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit price adjustment: %w", err)
+	}
+
+	return a, nil
+}
+
+// GetPriceAdjustments retrieves all price adjustments, newest first
+func (r *SQLiteRepository) GetPriceAdjustments(ctx context.Context) ([]*PriceAdjustment, error) {
+	query := fmt.Sprintf("SELECT %s FROM price_adjustments ORDER BY id DESC", adjustmentColumns)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adjustments := []*PriceAdjustment{}
+	for rows.Next() {
+		a, err := scanPriceAdjustment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price adjustment: %w", err)
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// GetPriceAdjustment retrieves a price adjustment with the prices it changed
+func (r *SQLiteRepository) GetPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error) {
+	return getAdjustment(ctx, r.db, id)
+}
+
+// GetDuePriceAdjustments retrieves the IDs of scheduled adjustments whose time has come
+func (r *SQLiteRepository) GetDuePriceAdjustments(ctx context.Context, now time.Time) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM price_adjustments
+		WHERE status = ? AND scheduled_at <= ?
+		ORDER BY scheduled_at, id
+	`, AdjustmentScheduled, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due price adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan price adjustment ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ApplyPriceAdjustment applies a scheduled adjustment in a single transaction
+func (r *SQLiteRepository) ApplyPriceAdjustment(ctx context.Context, id int64, now time.Time) (*PriceAdjustment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := applyAdjustment(ctx, tx, id, now); err != nil {
+		return nil, err
+	}
+
+	a, err := getAdjustment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit price adjustment: %w", err)
+	}
+
+	return a, nil
+}
+
+// FailPriceAdjustment marks a scheduled adjustment that could not be applied
+func (r *SQLiteRepository) FailPriceAdjustment(ctx context.Context, id int64, message string) error {
+	return setAdjustmentStatus(ctx, r.db, id, AdjustmentScheduled, AdjustmentFailed, "error = ?", message)
+}
+
+// CancelPriceAdjustment cancels a scheduled adjustment before it runs
+func (r *SQLiteRepository) CancelPriceAdjustment(ctx context.Context, id int64) (*PriceAdjustment, error) {
+	if err := setAdjustmentStatus(ctx, r.db, id, AdjustmentScheduled, AdjustmentCancelled, ""); err != nil {
+		return nil, err
+	}
+	return getAdjustment(ctx, r.db, id)
+}
+
+// RevertPriceAdjustment restores the prices changed by an applied
+// adjustment. Prices edited again since the adjustment are left alone and
+// reported as skipped.
+func (r *SQLiteRepository) RevertPriceAdjustment(ctx context.Context, id int64, adminID int64, now time.Time) (*RevertResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setAdjustmentStatus(ctx, tx, id, AdjustmentApplied, AdjustmentReverted, "reverted_at = ?", now); err != nil {
+		return nil, err
+	}
+
+	a, err := getAdjustment(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RevertResult{Adjustment: a, Skipped: []PriceAdjustmentItem{}}
+	var changed []int64
+
+	for _, item := range a.Items {
+		existing, err := getProduct(ctx, tx, item.ProductID)
+		if err == ErrNotFound {
+			result.Skipped = append(result.Skipped, item)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if item.VariantID == nil {
+			if existing.Price != item.NewPrice ||
+				!samePrice(existing.CompareAtPrice, item.NewCompareAtPrice) ||
+				!samePrice(existing.SalePrice, item.NewSalePrice) {
+				result.Skipped = append(result.Skipped, item)
+				continue
+			}
+			if err := updateProduct(ctx, tx, existing, priceUpdate(item.OldPrice, item.OldCompareAtPrice, item.OldSalePrice)); err != nil {
+				return nil, err
+			}
+			changed = append(changed, item.ProductID)
+		} else {
+			reverted, err := revertVariantPrice(ctx, tx, item)
+			if err != nil {
+				return nil, err
+			}
+			if !reverted {
+				result.Skipped = append(result.Skipped, item)
+				continue
+			}
+		}
+		result.Reverted++
+	}
+
+	if err := recordPriceRevisions(ctx, tx, changed, adminID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit price adjustment revert: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyAdjustment changes the prices of a scheduled adjustment within tx and
+// records them as its items. Claiming the adjustment first keeps the
+// scheduler and the API from applying it twice.
+func applyAdjustment(ctx context.Context, tx *sql.Tx, id int64, now time.Time) error {
+	if err := setAdjustmentStatus(ctx, tx, id, AdjustmentScheduled, AdjustmentApplied, "applied_at = ?", now); err != nil {
+		return err
+	}
+
+	a, err := getAdjustment(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	items, err := adjustmentItems(ctx, tx, a.input())
+	if err != nil {
+		return err
+	}
+
+	var changed []int64
+	for _, item := range items {
+		newPrice := item.NewPrice
+		if item.VariantID == nil {
+			existing, err := getProduct(ctx, tx, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if err := updateProduct(ctx, tx, existing, priceUpdate(item.NewPrice, item.NewCompareAtPrice, item.NewSalePrice)); err != nil {
+				return err
+			}
+			changed = append(changed, item.ProductID)
+		} else {
+			if err := updateVariant(ctx, tx, item.ProductID, *item.VariantID, UpdateVariantInput{Price: &newPrice}); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO price_adjustment_items (adjustment_id, product_id, variant_id, old_price, new_price,
+				old_compare_at_price, new_compare_at_price, old_sale_price, new_sale_price)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, item.ProductID, item.VariantID, item.OldPrice, item.NewPrice,
+			item.OldCompareAtPrice, item.NewCompareAtPrice, item.OldSalePrice, item.NewSalePrice)
+		if err != nil {
+			return fmt.Errorf("failed to insert price adjustment item: %w", err)
+		}
+	}
+
+	var adminID int64
+	if a.AdminID != nil {
+		adminID = *a.AdminID
+	}
+	return recordPriceRevisions(ctx, tx, changed, adminID)
+}
+
+// adjustmentItems computes the new prices of every product in the scope of
+// an adjustment and of their variants overriding the product price.
+// Prices the rounding leaves unchanged are omitted.
+func adjustmentItems(ctx context.Context, db queryer, input PriceAdjustmentInput) ([]PriceAdjustmentItem, error) {
+	q := buildProductQuery(input.filters(), "")
+	query := fmt.Sprintf(`
+		SELECT products.id, products.name, products.price, products.compare_at_price, products.sale_price, v.id, v.sku, v.price
+		FROM %s
+		LEFT JOIN product_variants v ON v.product_id = products.id AND v.price IS NOT NULL
+		%s
+		ORDER BY products.id, v.id
+	`, q.from, q.where)
+
+	rows, err := db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adjusted products: %w", err)
+	}
+	defer rows.Close()
+
+	items := []PriceAdjustmentItem{}
+	add := func(item PriceAdjustmentItem) error {
+		if item.NewPrice <= 0 {
+			return ErrInvalidInput(fmt.Sprintf("%s would cost %d", item.Name, item.NewPrice))
+		}
+		if item.NewSalePrice != nil && (*item.NewSalePrice <= 0 || *item.NewSalePrice >= item.NewPrice) {
+			return ErrInvalidInput(fmt.Sprintf("%s would be on sale at %d for a price of %d", item.Name, *item.NewSalePrice, item.NewPrice))
+		}
+		if item.NewPrice != item.OldPrice ||
+			!samePrice(item.NewCompareAtPrice, item.OldCompareAtPrice) ||
+			!samePrice(item.NewSalePrice, item.OldSalePrice) {
+			items = append(items, item)
+		}
+		return nil
+	}
+
+	var lastProductID int64
+	for rows.Next() {
+		var item PriceAdjustmentItem
+		var compareAtPrice, salePrice, variantID, variantPrice sql.NullInt64
+		var sku sql.NullString
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.OldPrice, &compareAtPrice, &salePrice, &variantID, &sku, &variantPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan adjusted product: %w", err)
+		}
+
+		// The product prices come first, once per product
+		if item.ProductID != lastProductID {
+			lastProductID = item.ProductID
+			p := item
+			p.NewPrice = input.adjustPrice(item.OldPrice)
+			p.OldCompareAtPrice = nullablePrice(compareAtPrice)
+			p.NewCompareAtPrice = input.adjustOptionalPrice(p.OldCompareAtPrice)
+			p.OldSalePrice = nullablePrice(salePrice)
+			p.NewSalePrice = input.adjustOptionalPrice(p.OldSalePrice)
+			if err := add(p); err != nil {
+				return nil, err
+			}
+		}
+
+		if variantID.Valid {
+			item.VariantID = &variantID.Int64
+			item.SKU = sku.String
+			item.OldPrice = int(variantPrice.Int64)
+			item.NewPrice = input.adjustPrice(item.OldPrice)
+			if err := add(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return items, nil
+}
+
+// revertVariantPrice restores the price override of a variant unless it
+// changed since the adjustment
+func revertVariantPrice(ctx context.Context, tx *sql.Tx, item PriceAdjustmentItem) (bool, error) {
+	result, err := tx.ExecContext(ctx,
+		"UPDATE product_variants SET price = ?, updated_at = ? WHERE id = ? AND product_id = ? AND price = ?",
+		item.OldPrice, time.Now(), *item.VariantID, item.ProductID, item.NewPrice,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revert variant price: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// recordPriceRevisions records a revision for each product whose price changed
+func recordPriceRevisions(ctx context.Context, tx *sql.Tx, productIDs []int64, adminID int64) error {
+	for _, id := range productIDs {
+		p, err := getProduct(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = insertRevision(ctx, tx, CreateRevisionInput{
+			ProductID: id,
+			Action:    RevisionUpdate,
+			Snapshot:  snapshotOf(p),
+			AdminID:   adminID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setAdjustmentStatus moves an adjustment from one status to another,
+// optionally setting one more column. It fails with ErrAdjustmentStatus when
+// the adjustment is not in the expected status.
+func setAdjustmentStatus(ctx context.Context, db interface {
+	execer
+	queryRower
+}, id int64, from, to, set string, args ...interface{}) error {
+	query := "UPDATE price_adjustments SET status = ?"
+	if set != "" {
+		query += ", " + set
+	}
+	query += " WHERE id = ? AND status = ?"
+
+	args = append(append([]interface{}{to}, args...), id, from)
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update price adjustment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	var status string
+	err = db.QueryRowContext(ctx, "SELECT status FROM price_adjustments WHERE id = ?", id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrAdjustmentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get price adjustment status: %w", err)
+	}
+
+	return fmt.Errorf("%w: adjustment is %s", ErrAdjustmentStatus, status)
+}
+
+// getAdjustment retrieves a price adjustment with its items
+func getAdjustment(ctx context.Context, db interface {
+	queryer
+	queryRower
+}, id int64) (*PriceAdjustment, error) {
+	query := fmt.Sprintf("SELECT %s FROM price_adjustments WHERE id = ?", adjustmentColumns)
+
+	a, err := scanPriceAdjustment(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrAdjustmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price adjustment: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT i.product_id, i.variant_id, COALESCE(p.name, ''), COALESCE(v.sku, ''), i.old_price, i.new_price,
+			i.old_compare_at_price, i.new_compare_at_price, i.old_sale_price, i.new_sale_price
+		FROM price_adjustment_items i
+		LEFT JOIN products p ON p.id = i.product_id
+		LEFT JOIN product_variants v ON v.id = i.variant_id
+		WHERE i.adjustment_id = ?
+		ORDER BY i.id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price adjustment items: %w", err)
+	}
+	defer rows.Close()
+
+	a.Items = []PriceAdjustmentItem{}
+	for rows.Next() {
+		var item PriceAdjustmentItem
+		var variantID, oldCompareAt, newCompareAt, oldSale, newSale sql.NullInt64
+		if err := rows.Scan(&item.ProductID, &variantID, &item.Name, &item.SKU, &item.OldPrice, &item.NewPrice,
+			&oldCompareAt, &newCompareAt, &oldSale, &newSale); err != nil {
+			return nil, fmt.Errorf("failed to scan price adjustment item: %w", err)
+		}
+		if variantID.Valid {
+			item.VariantID = &variantID.Int64
+		}
+		item.OldCompareAtPrice = nullablePrice(oldCompareAt)
+		item.NewCompareAtPrice = nullablePrice(newCompareAt)
+		item.OldSalePrice = nullablePrice(oldSale)
+		item.NewSalePrice = nullablePrice(newSale)
+		a.Items = append(a.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return a, nil
+}
+
+// priceUpdate returns the update setting the prices of a product. Unset
+// compare-at and sale prices are left as they are.
+func priceUpdate(price int, compareAtPrice, salePrice *int) UpdateProductInput {
+	return UpdateProductInput{Price: &price, CompareAtPrice: compareAtPrice, SalePrice: salePrice}
+}
+
+// samePrice reports whether two optional prices are equal
+func samePrice(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// nullablePrice converts a nullable price column
+func nullablePrice(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	price := int(n.Int64)
+	return &price
+}
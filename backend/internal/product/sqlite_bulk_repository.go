@@ -0,0 +1,161 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GetIDs retrieves the IDs of all products matching the filters, ignoring pagination
+func (r *SQLiteRepository) GetIDs(ctx context.Context, filters GetAllFilters) ([]int64, error) {
+	q := buildProductQuery(filters, "")
+	query := fmt.Sprintf("SELECT products.id FROM %s %s ORDER BY products.id", q.from, q.where)
+
+	rows, err := r.db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan product ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Bulk applies an operation to each product in a single transaction. Every
+// product is applied inside a savepoint and reported on its own; the
+// transaction is only committed when all of them succeed.
+func (r *SQLiteRepository) Bulk(ctx context.Context, ids []int64, input BulkInput) (*BulkResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &BulkResult{Operation: input.Operation, Results: []BulkItemResult{}}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT bulk_item"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		p, err := bulkOne(ctx, tx, id, input)
+		switch {
+		case err == nil:
+			result.Succeeded++
+			result.Results = append(result.Results, BulkItemResult{ID: id, Status: "ok", Product: p})
+		case errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation):
+			result.Failed++
+			message := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+			result.Results = append(result.Results, BulkItemResult{ID: id, Status: "error", Error: message})
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO bulk_item"); err != nil {
+				return nil, fmt.Errorf("failed to roll back savepoint: %w", err)
+			}
+		default:
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE bulk_item"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if result.Failed > 0 {
+		// Nothing was saved, so the changed products would be misleading
+		for i := range result.Results {
+			result.Results[i].Product = nil
+		}
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk operation: %w", err)
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// bulkOne applies a bulk operation to a single product within tx and
+// records a revision. Deleted products are returned as nil.
+func bulkOne(ctx context.Context, tx *sql.Tx, id int64, input BulkInput) (*Product, error) {
+	action := RevisionUpdate
+
+	switch input.Operation {
+	case BulkRestore:
+		action = RevisionRestore
+		if err := restoreProduct(ctx, tx, id); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w in trash", ErrNotFound)
+			}
+			return nil, err
+		}
+	default:
+		existing, err := getProduct(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch input.Operation {
+		case BulkUpdate:
+			err = updateProduct(ctx, tx, existing, *input.Update)
+		case BulkAddTag, BulkRemoveTag:
+			tags := withTag(existing.Tags, input.Tag, input.Operation == BulkAddTag)
+			err = updateProduct(ctx, tx, existing, UpdateProductInput{Tags: &tags})
+		case BulkDelete:
+			action = RevisionDelete
+			err = softDeleteProduct(ctx, tx, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := getProductIncludingDeleted(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = insertRevision(ctx, tx, CreateRevisionInput{
+		ProductID: id,
+		Action:    action,
+		Snapshot:  snapshotOf(p),
+		AdminID:   input.AdminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.DeletedAt != nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// getProductIncludingDeleted retrieves a product by ID even if it is in the trash
+func getProductIncludingDeleted(ctx context.Context, db queryRower, id int64) (*Product, error) {
+	query := fmt.Sprintf("SELECT %s FROM products WHERE id = ?", productColumns)
+
+	p, err := scanProduct(db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,122 @@
+package product
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetAllByCursor retrieves a page of products after or before an opaque cursor
+// using keyset pagination over the sort column and ID. An empty cursor
+// returns the first page. Unlike GetAll it does not count matching products.
+func (r *SQLiteRepository) GetAllByCursor(ctx context.Context, filters GetAllFilters, token string) (*CursorPage, error) {
+	q := buildProductQuery(filters, "")
+	sort, sortExpr, order := productOrder(filters, q.search != "")
+
+	highlightColumns := "NULL, NULL"
+	if q.search != "" {
+		highlightColumns = "fts.name_hl, fts.description_hl"
+	}
+	whereClause, args := q.where, q.args
+
+	var after *cursor
+	if token != "" {
+		var err error
+		if after, err = decodeCursor(token, sort, order); err != nil {
+			return nil, err
+		}
+	}
+
+	// Walking backwards reverses the scan direction; rows are flipped back below
+	scanOrder := order
+	if after != nil && after.Backward {
+		scanOrder = reverseOrder(order)
+	}
+
+	if after != nil {
+		cmp := "<"
+		if scanOrder == "ASC" {
+			cmp = ">"
+		}
+		whereClause += fmt.Sprintf(" AND (%[1]s %[2]s ? OR (%[1]s = ? AND products.id %[2]s ?))", sortExpr, cmp)
+		args = append(args, after.Value, after.Value, after.ID)
+	}
+
+	// Pagination, fetching one extra row to know whether more follow
+	limit := filters.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s, %s, %s
+		 FROM %s
+		 %s
+		 ORDER BY %s %s, products.id %s
+		 LIMIT ?`,
+		productColumns, highlightColumns, sortExpr, q.from, whereClause, sortExpr, scanOrder, scanOrder,
+	)
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*Product{}
+	var sortValues []interface{}
+	for rows.Next() {
+		var sortValue interface{}
+		product, err := scanSearchResult(extraScanner{row: rows, extra: []interface{}{&sortValue}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+		sortValues = append(sortValues, sortValue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	hasMore := len(products) > limit
+	if hasMore {
+		products, sortValues = products[:limit], sortValues[:limit]
+	}
+
+	backward := after != nil && after.Backward
+	if backward {
+		reverse(products)
+		reverse(sortValues)
+	}
+
+	page := &CursorPage{Products: products}
+	if len(products) == 0 {
+		return page, nil
+	}
+
+	first, last := 0, len(products)-1
+	if backward || hasMore {
+		page.NextCursor = cursor{Sort: sort, Order: order, Value: sortValues[last], ID: products[last].ID}.encode()
+	}
+	if (after != nil && !backward) || (backward && hasMore) {
+		page.PrevCursor = cursor{Sort: sort, Order: order, Value: sortValues[first], ID: products[first].ID, Backward: true}.encode()
+	}
+
+	return page, nil
+}
+
+// reverseOrder flips an ASC/DESC direction
+func reverseOrder(order string) string {
+	if order == "ASC" {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// reverse reverses a slice in place
+func reverse[T any](s []T) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
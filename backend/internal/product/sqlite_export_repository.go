@@ -0,0 +1,87 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Export streams every product with its variants to fn in ID order, one
+// product at a time, without loading the catalog in memory
+func (r *SQLiteRepository) Export(ctx context.Context, includeDeleted bool, fn func(*Product, []*Variant) error) error {
+	where := "WHERE products.deleted_at IS NULL"
+	if includeDeleted {
+		where = ""
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+			v.variant_id, v.product_id, v.sku, v.size, v.color, v.stock, v.variant_price, v.variant_created_at, v.variant_updated_at
+		FROM products
+		LEFT JOIN (
+			SELECT id AS variant_id, product_id, sku, size, color, stock, price AS variant_price,
+				created_at AS variant_created_at, updated_at AS variant_updated_at
+			FROM product_variants
+		) v ON v.product_id = products.id
+		%s
+		ORDER BY products.id, v.variant_id
+	`, productColumns, where)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	var current *Product
+	var variants []*Variant
+	for rows.Next() {
+		var variantID, productID, stock, price sql.NullInt64
+		var sku, size, color sql.NullString
+		var createdAt, updatedAt sql.NullTime
+
+		p, err := scanProduct(extraScanner{row: rows, extra: []interface{}{
+			&variantID, &productID, &sku, &size, &color, &stock, &price, &createdAt, &updatedAt,
+		}})
+		if err != nil {
+			return fmt.Errorf("failed to scan product: %w", err)
+		}
+
+		if current == nil || current.ID != p.ID {
+			if current != nil {
+				if err := fn(current, variants); err != nil {
+					return err
+				}
+			}
+			current, variants = p, nil
+		}
+
+		if variantID.Valid {
+			v := &Variant{
+				ID:        variantID.Int64,
+				ProductID: productID.Int64,
+				SKU:       sku.String,
+				Size:      size.String,
+				Color:     color.String,
+				Stock:     int(stock.Int64),
+				Available: stock.Int64 > 0,
+				CreatedAt: createdAt.Time,
+				UpdatedAt: updatedAt.Time,
+			}
+			if price.Valid {
+				variantPrice := int(price.Int64)
+				v.Price = &variantPrice
+			}
+			variants = append(variants, v)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if current != nil {
+		return fn(current, variants)
+	}
+	return nil
+}
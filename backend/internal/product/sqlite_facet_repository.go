@@ -0,0 +1,107 @@
+package product
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetFacets counts products per filter value for the given filters
+func (r *SQLiteRepository) GetFacets(ctx context.Context, filters GetAllFilters) (*Facets, error) {
+	var facets Facets
+	var err error
+
+	if facets.Categories, err = r.columnFacet(ctx, filters, facetCategory, "products.category"); err != nil {
+		return nil, err
+	}
+	if facets.Genders, err = r.columnFacet(ctx, filters, facetGender, "products.gender"); err != nil {
+		return nil, err
+	}
+	if facets.Tags, err = r.jsonArrayFacet(ctx, filters, facetTag, "products.tags"); err != nil {
+		return nil, err
+	}
+	if facets.Sizes, err = r.jsonArrayFacet(ctx, filters, facetSize, "products.sizes"); err != nil {
+		return nil, err
+	}
+	if facets.Colors, err = r.jsonArrayFacet(ctx, filters, facetColor, "products.colors"); err != nil {
+		return nil, err
+	}
+	if facets.Oversize, err = r.flagFacet(ctx, filters, facetOversize, "products.oversize"); err != nil {
+		return nil, err
+	}
+	if facets.Featured, err = r.flagFacet(ctx, filters, facetFeatured, "products.featured"); err != nil {
+		return nil, err
+	}
+
+	q := buildProductQuery(filters, facetPrice)
+	query := fmt.Sprintf("SELECT COALESCE(MIN(%[1]s), 0), COALESCE(MAX(%[1]s), 0) FROM %[2]s %[3]s", effectivePriceExpr, q.from, q.where)
+	if err := r.db.QueryRowContext(ctx, query, q.args...).Scan(&facets.Price.Min, &facets.Price.Max); err != nil {
+		return nil, fmt.Errorf("failed to get price facet: %w", err)
+	}
+
+	return &facets, nil
+}
+
+// columnFacet counts products per distinct non-empty value of a text column
+func (r *SQLiteRepository) columnFacet(ctx context.Context, filters GetAllFilters, facet, column string) ([]FacetValue, error) {
+	q := buildProductQuery(filters, facet)
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) AS count
+		FROM %[2]s
+		%[3]s AND COALESCE(%[1]s, '') != ''
+		GROUP BY %[1]s
+		ORDER BY count DESC, %[1]s ASC
+	`, column, q.from, q.where)
+
+	return r.queryFacetValues(ctx, facet, query, q.args)
+}
+
+// jsonArrayFacet counts products per value stored in a JSON array column
+func (r *SQLiteRepository) jsonArrayFacet(ctx context.Context, filters GetAllFilters, facet, column string) ([]FacetValue, error) {
+	q := buildProductQuery(filters, facet)
+	query := fmt.Sprintf(`
+		SELECT facet.value, COUNT(DISTINCT products.id) AS count
+		FROM %[1]s, json_each(%[2]s) AS facet
+		%[3]s AND facet.type = 'text' AND facet.value != ''
+		GROUP BY facet.value
+		ORDER BY count DESC, facet.value ASC
+	`, q.from, jsonArray(column), q.where)
+
+	return r.queryFacetValues(ctx, facet, query, q.args)
+}
+
+// flagFacet counts products having a boolean column set
+func (r *SQLiteRepository) flagFacet(ctx context.Context, filters GetAllFilters, facet, column string) (int, error) {
+	q := buildProductQuery(filters, facet)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s %s AND %s = 1", q.from, q.where, column)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, q.args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to get %s facet: %w", facet, err)
+	}
+
+	return count, nil
+}
+
+// queryFacetValues runs a value/count query and collects the rows
+func (r *SQLiteRepository) queryFacetValues(ctx context.Context, facet, query string, args []interface{}) ([]FacetValue, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s facet: %w", facet, err)
+	}
+	defer rows.Close()
+
+	values := []FacetValue{}
+	for rows.Next() {
+		var value FacetValue
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan %s facet: %w", facet, err)
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return values, nil
+}
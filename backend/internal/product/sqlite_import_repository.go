@@ -0,0 +1,224 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Import creates or updates the products of an import file in a single
+// transaction. Each product is applied inside a savepoint so a failing
+// product is reported without hiding errors in the rest of the batch. The
+// transaction is only committed when the batch has no errors and is not a
+// dry run.
+func (r *SQLiteRepository) Import(ctx context.Context, products []*ImportProduct, opts ImportOptions) (*ImportResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &ImportResult{
+		DryRun:   opts.DryRun,
+		Products: []ImportedProduct{},
+		Errors:   []ImportError{},
+	}
+
+	for _, p := range products {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_product"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		imported, rowErr, err := importOne(ctx, tx, p, opts.AdminID)
+		if err != nil {
+			return nil, err
+		}
+
+		if rowErr != nil {
+			result.Errors = append(result.Errors, *rowErr)
+			if _, err := tx.ExecContext(ctx, "ROLLBACK TO import_product"); err != nil {
+				return nil, fmt.Errorf("failed to roll back savepoint: %w", err)
+			}
+		} else {
+			result.Products = append(result.Products, *imported)
+			if imported.Action == string(RevisionCreate) {
+				result.Created++
+			} else {
+				result.Updated++
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE import_product"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if opts.DryRun || len(result.Errors) > 0 {
+		// IDs of a rolled back batch are meaningless
+		for i := range result.Products {
+			result.Products[i].ProductID = 0
+		}
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// importOne creates or updates a single product and its variants within tx.
+// Validation problems are returned as an ImportError; err is only set for
+// unexpected database failures.
+func importOne(ctx context.Context, tx *sql.Tx, p *ImportProduct, adminID int64) (*ImportedProduct, *ImportError, error) {
+	rowError := func(row int, err error) (*ImportedProduct, *ImportError, error) {
+		if errors.Is(err, ErrValidation) || errors.Is(err, ErrDuplicateVariant) {
+			message := strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+			return nil, &ImportError{Row: row, Message: message}, nil
+		}
+		return nil, nil, err
+	}
+
+	existing, err := findImportTarget(ctx, tx, p)
+	if err != nil {
+		return rowError(p.Row, err)
+	}
+
+	imported := &ImportedProduct{Row: p.Row, Variants: len(p.Variants)}
+	var id int64
+
+	if existing == nil {
+		imported.Action = string(RevisionCreate)
+		if err := p.Create.Validate(); err != nil {
+			return rowError(p.Row, err)
+		}
+		if id, err = createProduct(ctx, tx, p.Create); err != nil {
+			return rowError(p.Row, err)
+		}
+	} else {
+		imported.Action = string(RevisionUpdate)
+		id = existing.ID
+		if err := updateProduct(ctx, tx, existing, p.Update); err != nil {
+			return rowError(p.Row, err)
+		}
+	}
+
+	variants, err := getVariants(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, v := range p.Variants {
+		if match := matchVariant(variants, v.Create); match != nil {
+			if err := v.Update.Validate(); err != nil {
+				return rowError(v.Row, err)
+			}
+			if err := updateVariant(ctx, tx, id, match.ID, v.Update); err != nil {
+				return rowError(v.Row, err)
+			}
+			continue
+		}
+
+		if err := v.Create.Validate(); err != nil {
+			return rowError(v.Row, err)
+		}
+		if _, err := insertVariant(ctx, tx, id, v.Create); err != nil {
+			return rowError(v.Row, err)
+		}
+	}
+
+	saved, err := getProduct(ctx, tx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Re-importing an unchanged export should not flood the revision history
+	snapshot := snapshotOf(saved)
+	if existing == nil || !reflect.DeepEqual(snapshot, snapshotOf(existing)) {
+		_, err = insertRevision(ctx, tx, CreateRevisionInput{
+			ProductID: id,
+			Action:    RevisionAction(imported.Action),
+			Snapshot:  snapshot,
+			AdminID:   adminID,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	imported.ProductID = id
+	imported.Slug = saved.Slug
+
+	return imported, nil, nil
+}
+
+// findImportTarget returns the existing product an import row updates, matched
+// by its slug, the SKU of one of its variants or the slug derived from its
+// name, in that order. It returns nil when the product is new.
+func findImportTarget(ctx context.Context, tx *sql.Tx, p *ImportProduct) (*Product, error) {
+	if p.Slug != "" {
+		if existing, err := getProductBySlug(ctx, tx, p.Slug); err != ErrNotFound {
+			return existing, err
+		}
+	}
+
+	var targetID int64
+	for _, v := range p.Variants {
+		if v.Create.SKU == "" {
+			continue
+		}
+
+		var productID int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT v.product_id
+			FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.sku = ? AND p.deleted_at IS NULL
+		`, v.Create.SKU).Scan(&productID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find variant by sku: %w", err)
+		}
+
+		if targetID != 0 && targetID != productID {
+			return nil, ErrInvalidInput(fmt.Sprintf("sku %s belongs to another product", v.Create.SKU))
+		}
+		targetID = productID
+	}
+	if targetID != 0 {
+		return getProduct(ctx, tx, targetID)
+	}
+
+	if p.Slug == "" && p.Create.Name != "" {
+		if existing, err := getProductBySlug(ctx, tx, slug.Make(p.Create.Name)); err != ErrNotFound {
+			return existing, err
+		}
+	}
+
+	return nil, nil
+}
+
+// matchVariant finds the existing variant an import row refers to, by SKU or
+// by size and color
+func matchVariant(variants []*Variant, input CreateVariantInput) *Variant {
+	for _, v := range variants {
+		if input.SKU != "" && v.SKU == input.SKU {
+			return v
+		}
+	}
+	for _, v := range variants {
+		if v.Size == input.Size && v.Color == input.Color {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// GetPriceHistory retrieves the price changes of a product, newest first
+func (r *SQLiteRepository) GetPriceHistory(ctx context.Context, productID int64) ([]*PriceChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT price, compare_at_price, sale_price, sale_starts_at, sale_ends_at, changed_at
+		FROM product_prices
+		WHERE product_id = ?
+		ORDER BY id DESC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*PriceChange{}
+	for rows.Next() {
+		var c PriceChange
+		var compareAtPrice, salePrice sql.NullInt64
+		var saleStartsAt, saleEndsAt sql.NullTime
+		if err := rows.Scan(&c.Price, &compareAtPrice, &salePrice, &saleStartsAt, &saleEndsAt, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan price change: %w", err)
+		}
+
+		if compareAtPrice.Valid {
+			price := int(compareAtPrice.Int64)
+			c.CompareAtPrice = &price
+		}
+		if salePrice.Valid {
+			price := int(salePrice.Int64)
+			c.SalePrice = &price
+		}
+		if saleStartsAt.Valid {
+			c.SaleStartsAt = &saleStartsAt.Time
+		}
+		if saleEndsAt.Valid {
+			c.SaleEndsAt = &saleEndsAt.Time
+		}
+		history = append(history, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return history, nil
+}
+
+// recordPrice appends the current pricing of a product to its price history
+// unless it matches the latest entry
+func recordPrice(ctx context.Context, db execer, productID int64) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO product_prices (product_id, price, compare_at_price, sale_price, sale_starts_at, sale_ends_at, changed_at)
+		SELECT p.id, p.price, p.compare_at_price, p.sale_price, p.sale_starts_at, p.sale_ends_at, p.updated_at
+		FROM products p
+		WHERE p.id = ? AND NOT EXISTS (
+			SELECT 1 FROM product_prices h
+			WHERE h.id = (SELECT MAX(id) FROM product_prices WHERE product_id = p.id)
+				AND h.price = p.price
+				AND h.compare_at_price IS p.compare_at_price
+				AND h.sale_price IS p.sale_price
+				AND h.sale_starts_at IS p.sale_starts_at
+				AND h.sale_ends_at IS p.sale_ends_at
+		)
+	`, productID)
+	if err != nil {
+		return fmt.Errorf("failed to record price history: %w", err)
+	}
+	return nil
+}
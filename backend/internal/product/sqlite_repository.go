@@ -7,8 +7,16 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
 )
 
+// productColumns lists the selected product columns in scanProduct order.
+// stock is NULL for products that have no inventory movements yet.
+const productColumns = `id, name, description, price, category, images, tags, sizes, colors, gender, oversize, featured, created_at, updated_at, deleted_at, category_id, slug,
+	compare_at_price, sale_price, sale_starts_at, sale_ends_at,
+	(SELECT SUM(quantity) FROM inventory_movements WHERE product_id = products.id) AS stock`
+
 // SQLiteRepository implements Repository using SQLite
 type SQLiteRepository struct {
 	db *sql.DB
@@ -21,51 +29,23 @@ func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
 
 // GetAll retrieves products with pagination and filters
 func (r *SQLiteRepository) GetAll(ctx context.Context, filters GetAllFilters) ([]*Product, int, error) {
-	// Build WHERE clause
-	var whereClauses []string
-	var args []interface{}
+	q := buildProductQuery(filters, "")
 
-	// Exclude deleted products
-	whereClauses = append(whereClauses, "deleted_at IS NULL")
-
-	// Search filter
-	if filters.Search != "" {
-		whereClauses = append(whereClauses, "(name LIKE ? OR description LIKE ?)")
-		searchTerm := "%" + filters.Search + "%"
-		args = append(args, searchTerm, searchTerm)
-	}
-
-	// Category filter
-	if filters.Category != "" {
-		whereClauses = append(whereClauses, "category = ?")
-		args = append(args, filters.Category)
-	}
-
-	whereClause := ""
-	if len(whereClauses) > 0 {
-		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	highlightColumns := "NULL, NULL"
+	if q.search != "" {
+		highlightColumns = "fts.name_hl, fts.description_hl"
 	}
+	fromClause, whereClause, args := q.from, q.where, q.args
 
 	// Get total count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM products %s", whereClause)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", fromClause, whereClause)
 	var total int
 	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("failed to count products: %w", err)
 	}
 
 	// Build ORDER BY clause
-	orderBy := "created_at"
-	if filters.Sort != "" {
-		switch filters.Sort {
-		case "name", "price", "created_at":
-			orderBy = filters.Sort
-		}
-	}
-
-	order := "DESC"
-	if filters.Order == "asc" {
-		order = "ASC"
-	}
+	_, orderBy, order := productOrder(filters, q.search != "")
 
 	// Pagination
 	limit := filters.Limit
@@ -80,12 +60,12 @@ func (r *SQLiteRepository) GetAll(ctx context.Context, filters GetAllFilters) ([
 
 	// Build and execute query
 	query := fmt.Sprintf(
-		`SELECT id, name, description, price, category, images, tags, sizes, colors, gender, oversize, featured, created_at, updated_at, deleted_at
-		 FROM products
+		`SELECT %s, %s
+		 FROM %s
 		 %s
-		 ORDER BY %s %s
+		 ORDER BY %s %s, products.id %s
 		 LIMIT ? OFFSET ?`,
-		whereClause, orderBy, order,
+		productColumns, highlightColumns, fromClause, whereClause, orderBy, order, order,
 	)
 
 	args = append(args, limit, offset)
@@ -97,7 +77,7 @@ func (r *SQLiteRepository) GetAll(ctx context.Context, filters GetAllFilters) ([
 
 	var products []*Product
 	for rows.Next() {
-		product, err := scanProduct(rows)
+		product, err := scanSearchResult(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
 		}
@@ -113,13 +93,18 @@ func (r *SQLiteRepository) GetAll(ctx context.Context, filters GetAllFilters) ([
 
 // GetByID retrieves a single product by ID
 func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Product, error) {
-	query := `
-		SELECT id, name, description, price, category, images, tags, sizes, colors, gender, oversize, featured, created_at, updated_at, deleted_at
+	return getProduct(ctx, r.db, id)
+}
+
+// getProduct retrieves a non-deleted product by ID using db or a transaction
+func getProduct(ctx context.Context, db queryRower, id int64) (*Product, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM products
 		WHERE id = ? AND deleted_at IS NULL
-	`
+	`, productColumns)
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	row := db.QueryRowContext(ctx, query, id)
 	product, err := scanProduct(row)
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
@@ -133,6 +118,31 @@ func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Product, err
 
 // Create creates a new product
 func (r *SQLiteRepository) Create(ctx context.Context, input CreateProductInput) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id, err := createProduct(ctx, tx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := recordRevision(ctx, tx, id, RevisionCreate, nil, input.AdminID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit product creation: %w", err)
+	}
+
+	// Return the created product
+	return r.GetByID(ctx, id)
+}
+
+// createProduct inserts a product and its inline variants within tx
+func createProduct(ctx context.Context, tx *sql.Tx, input CreateProductInput) (int64, error) {
 	// Serialize arrays to JSON
 	imagesJSON, _ := json.Marshal(input.Images)
 	tagsJSON, _ := json.Marshal(input.Tags)
@@ -140,8 +150,9 @@ func (r *SQLiteRepository) Create(ctx context.Context, input CreateProductInput)
 	colorsJSON, _ := json.Marshal(input.Colors)
 
 	query := `
-		INSERT INTO products (name, description, price, category, images, tags, sizes, colors, gender, oversize, featured, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO products (name, slug, description, price, category, category_id, images, tags, sizes, colors, gender, oversize, featured,
+			compare_at_price, sale_price, sale_starts_at, sale_ends_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
@@ -154,12 +165,35 @@ func (r *SQLiteRepository) Create(ctx context.Context, input CreateProductInput)
 		featuredInt = 1
 	}
 
-	result, err := r.db.ExecContext(
+	categoryID, categoryName, err := resolveCategory(ctx, tx, input.CategoryID, input.Category)
+	if err != nil {
+		return 0, err
+	}
+
+	// An explicit slug is kept when free, otherwise it is derived from the name
+	slugSource := input.Name
+	if strings.TrimSpace(input.Slug) != "" {
+		slugSource = input.Slug
+	}
+	productSlug, err := uniqueSlug(ctx, tx, slugSource, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	// Sale dates only mean something with a sale price
+	var saleStartsAt, saleEndsAt *time.Time
+	if input.SalePrice != nil {
+		saleStartsAt, saleEndsAt = input.SaleStartsAt, input.SaleEndsAt
+	}
+
+	result, err := tx.ExecContext(
 		ctx, query,
 		input.Name,
+		productSlug,
 		input.Description,
 		input.Price,
-		input.Category,
+		categoryName,
+		categoryID,
 		string(imagesJSON),
 		string(tagsJSON),
 		string(sizesJSON),
@@ -167,30 +201,68 @@ func (r *SQLiteRepository) Create(ctx context.Context, input CreateProductInput)
 		input.Gender,
 		oversizeInt,
 		featuredInt,
+		input.CompareAtPrice,
+		input.SalePrice,
+		saleStartsAt,
+		saleEndsAt,
 		now,
 		now,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create product: %w", err)
+		return 0, fmt.Errorf("failed to create product: %w", err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
 	}
 
-	// Return the created product
-	return r.GetByID(ctx, id)
+	if err := recordPrice(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	// Create inline variants in the same transaction
+	for _, variant := range input.Variants {
+		if _, err := insertVariant(ctx, tx, id, variant); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
 }
 
-// Update updates an existing product
+// Update updates an existing product and records the revision
 func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdateProductInput) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Check if product exists
-	_, err := r.GetByID(ctx, id)
+	existing, err := getProduct(ctx, tx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := updateProduct(ctx, tx, existing, input); err != nil {
+		return nil, err
+	}
+
+	if _, err := recordRevision(ctx, tx, id, RevisionUpdate, nil, input.AdminID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit product update: %w", err)
+	}
+
+	// Return updated product
+	return r.GetByID(ctx, id)
+}
+
+// updateProduct applies input to an existing product within tx
+func updateProduct(ctx context.Context, tx *sql.Tx, existing *Product, input UpdateProductInput) error {
 	// Build UPDATE query dynamically
 	var setClauses []string
 	var args []interface{}
@@ -199,6 +271,35 @@ func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdatePro
 		setClauses = append(setClauses, "name = ?")
 		args = append(args, *input.Name)
 	}
+	// An explicit slug is kept when free, as on creation. Renaming moves a
+	// product to a slug of the new name unless an admin chose its slug. Old
+	// slugs keep resolving.
+	name := existing.Name
+	if input.Name != nil {
+		name = *input.Name
+	}
+	slugSource := ""
+	switch {
+	case input.Slug != nil && strings.TrimSpace(*input.Slug) != "":
+		slugSource = *input.Slug
+	case input.Slug != nil:
+		slugSource = name
+	case name != existing.Name && generatedSlug(existing.Slug, existing.Name):
+		slugSource = name
+	}
+	if slugSource != "" {
+		newSlug, err := uniqueSlug(ctx, tx, slugSource, existing.ID)
+		if err != nil {
+			return err
+		}
+		if newSlug != existing.Slug {
+			if err := retireSlug(ctx, tx, existing.ID, existing.Slug, newSlug); err != nil {
+				return err
+			}
+			setClauses = append(setClauses, "slug = ?")
+			args = append(args, newSlug)
+		}
+	}
 	if input.Description != nil {
 		setClauses = append(setClauses, "description = ?")
 		args = append(args, *input.Description)
@@ -207,9 +308,57 @@ func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdatePro
 		setClauses = append(setClauses, "price = ?")
 		args = append(args, *input.Price)
 	}
-	if input.Category != nil {
-		setClauses = append(setClauses, "category = ?")
-		args = append(args, *input.Category)
+	if input.CompareAtPrice != nil {
+		setClauses = append(setClauses, "compare_at_price = ?")
+		if *input.CompareAtPrice == 0 {
+			args = append(args, nil)
+		} else {
+			args = append(args, *input.CompareAtPrice)
+		}
+	}
+	if input.Price != nil || input.SalePrice != nil || input.SaleStartsAt != nil || input.SaleEndsAt != nil || input.replaceSaleDates {
+		// The sale is checked as the product ends up, and as on creation its
+		// dates only mean something with a sale price
+		price, salePrice := existing.Price, existing.SalePrice
+		saleStartsAt, saleEndsAt := existing.SaleStartsAt, existing.SaleEndsAt
+		if input.Price != nil {
+			price = *input.Price
+		}
+		if input.SalePrice != nil {
+			salePrice = input.SalePrice
+			if *salePrice == 0 {
+				salePrice = nil
+			}
+		}
+		if input.SaleStartsAt != nil || input.replaceSaleDates {
+			saleStartsAt = input.SaleStartsAt
+		}
+		if input.SaleEndsAt != nil || input.replaceSaleDates {
+			saleEndsAt = input.SaleEndsAt
+		}
+		if salePrice == nil {
+			saleStartsAt, saleEndsAt = nil, nil
+		}
+		if err := validateSalePrice(price, salePrice); err != nil {
+			return err
+		}
+		if err := validatePricing(nil, nil, saleStartsAt, saleEndsAt); err != nil {
+			return err
+		}
+		setClauses = append(setClauses, "sale_price = ?", "sale_starts_at = ?", "sale_ends_at = ?")
+		args = append(args, salePrice, saleStartsAt, saleEndsAt)
+	}
+	if input.Category != nil || input.CategoryID != nil {
+		name := ""
+		if input.Category != nil {
+			name = *input.Category
+		}
+		categoryID, categoryName, err := resolveCategory(ctx, tx, input.CategoryID, name)
+		if err != nil {
+			return err
+		}
+		setClauses = append(setClauses, "category = ?", "category_id = ?")
+		args = append(args, categoryName, categoryID)
 	}
 	if input.Images != nil {
 		imagesJSON, _ := json.Marshal(*input.Images)
@@ -257,7 +406,7 @@ func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdatePro
 	args = append(args, time.Now())
 
 	// Add ID to args
-	args = append(args, id)
+	args = append(args, existing.ID)
 
 	// Execute update
 	query := fmt.Sprintf(
@@ -265,28 +414,135 @@ func (r *SQLiteRepository) Update(ctx context.Context, id int64, input UpdatePro
 		strings.Join(setClauses, ", "),
 	)
 
-	_, err = r.db.ExecContext(ctx, query, args...)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if input.Price != nil || input.CompareAtPrice != nil || input.SalePrice != nil ||
+		input.SaleStartsAt != nil || input.SaleEndsAt != nil || input.replaceSaleDates {
+		return recordPrice(ctx, tx, existing.ID)
+	}
+
+	return nil
+}
+
+// GetBySlug retrieves a single product by its current slug
+func (r *SQLiteRepository) GetBySlug(ctx context.Context, productSlug string) (*Product, error) {
+	return getProductBySlug(ctx, r.db, productSlug)
+}
+
+// getProductBySlug retrieves a non-deleted product by slug using db or a transaction
+func getProductBySlug(ctx context.Context, db queryRower, productSlug string) (*Product, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		WHERE slug = ? AND deleted_at IS NULL
+	`, productColumns)
+
+	product, err := scanProduct(db.QueryRowContext(ctx, query, productSlug))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to update product: %w", err)
+		return nil, fmt.Errorf("failed to get product by slug: %w", err)
 	}
 
-	// Return updated product
-	return r.GetByID(ctx, id)
+	return product, nil
 }
 
-// SoftDelete marks a product as deleted
-func (r *SQLiteRepository) SoftDelete(ctx context.Context, id int64) error {
-	// Check if product exists
-	_, err := r.GetByID(ctx, id)
+// GetCurrentSlug resolves a previous slug to the product's current slug
+func (r *SQLiteRepository) GetCurrentSlug(ctx context.Context, oldSlug string) (string, error) {
+	query := `
+		SELECT p.slug
+		FROM product_slug_history h
+		JOIN products p ON p.id = h.product_id
+		WHERE h.slug = ? AND p.deleted_at IS NULL
+	`
+
+	var current string
+	err := r.db.QueryRowContext(ctx, query, oldSlug).Scan(&current)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve slug: %w", err)
+	}
+
+	return current, nil
+}
+
+// SoftDelete marks a product as deleted and records the revision
+func (r *SQLiteRepository) SoftDelete(ctx context.Context, id int64, adminID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check if product exists
+	if _, err := getProduct(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := softDeleteProduct(ctx, tx, id); err != nil {
 		return err
 	}
 
+	if _, err := recordRevision(ctx, tx, id, RevisionDelete, nil, adminID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit product deletion: %w", err)
+	}
+
+	return nil
+}
+
+// softDeleteProduct marks a product as deleted using db or a transaction
+func softDeleteProduct(ctx context.Context, db execer, id int64) error {
 	query := "UPDATE products SET deleted_at = ? WHERE id = ?"
-	_, err = r.db.ExecContext(ctx, query, time.Now(), id)
+	_, err := db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to soft delete product: %w", err)
 	}
 
 	return nil
 }
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// resolveCategory links a product to a category entity. An explicit ID must
+// exist; otherwise a name is matched by slug so "santos" resolves to "Santos".
+// Names without a matching category are kept as free text.
+func resolveCategory(ctx context.Context, db queryRower, id *int64, name string) (*int64, string, error) {
+	var query string
+	var arg interface{}
+
+	switch {
+	case id != nil && *id > 0:
+		query, arg = "SELECT id, name FROM categories WHERE id = ?", *id
+	case strings.TrimSpace(name) != "":
+		query, arg = "SELECT id, name FROM categories WHERE slug = ?", slug.Make(name)
+	default:
+		return nil, "", nil
+	}
+
+	var categoryID int64
+	var categoryName string
+	err := db.QueryRowContext(ctx, query, arg).Scan(&categoryID, &categoryName)
+	if err == sql.ErrNoRows {
+		if id != nil && *id > 0 {
+			return nil, "", ErrInvalidInput("category not found")
+		}
+		return nil, strings.TrimSpace(name), nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve category: %w", err)
+	}
+
+	return &categoryID, categoryName, nil
+}
@@ -0,0 +1,176 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const revisionColumns = `r.id, r.product_id, r.revision, r.action, r.source_revision, r.snapshot,
+	r.admin_id, a.username, r.created_at`
+
+// Rollback sets the fields of a product to a revision and records the
+// rollback. The product ends up in the trash or out of it as it was at the
+// revision; one already in the trash keeps its deletion time.
+func (r *SQLiteRepository) Rollback(ctx context.Context, productID int64, rev *Revision, adminID int64) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, "SELECT deleted_at FROM products WHERE id = ?", productID).Scan(&deletedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	// Products in the trash are taken out to be updated
+	if deletedAt.Valid {
+		if err := restoreProduct(ctx, tx, productID); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := getProduct(ctx, tx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateProduct(ctx, tx, existing, rev.Snapshot.updateInput()); err != nil {
+		return nil, err
+	}
+
+	if rev.Snapshot.Deleted {
+		if !deletedAt.Valid {
+			deletedAt.Time = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE products SET deleted_at = ? WHERE id = ?", deletedAt.Time, productID); err != nil {
+			return nil, fmt.Errorf("failed to soft delete product: %w", err)
+		}
+	}
+
+	p, err := recordRevision(ctx, tx, productID, RevisionRollback, &rev.Number, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit rollback: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetRevisions retrieves all revisions of a product, oldest first
+func (r *SQLiteRepository) GetRevisions(ctx context.Context, productID int64) ([]*Revision, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_revisions r
+		LEFT JOIN admins a ON a.id = r.admin_id
+		WHERE r.product_id = ?
+		ORDER BY r.revision
+	`, revisionColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*Revision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetRevision retrieves a single revision of a product by its number
+func (r *SQLiteRepository) GetRevision(ctx context.Context, productID int64, number int) (*Revision, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_revisions r
+		LEFT JOIN admins a ON a.id = r.admin_id
+		WHERE r.product_id = ? AND r.revision = ?
+	`, revisionColumns)
+
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, query, productID, number))
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+// recordRevision snapshots a product within tx as its next revision and
+// returns the product, so a change and its revision commit together
+func recordRevision(ctx context.Context, tx *sql.Tx, id int64, action RevisionAction, source *int, adminID int64) (*Product, error) {
+	p, err := getProductIncludingDeleted(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = insertRevision(ctx, tx, CreateRevisionInput{
+		ProductID:      id,
+		Action:         action,
+		SourceRevision: source,
+		Snapshot:       snapshotOf(p),
+		AdminID:        adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// insertRevision inserts the next revision of a product and returns its ID
+func insertRevision(ctx context.Context, db execer, input CreateRevisionInput) (int64, error) {
+	snapshotJSON, err := json.Marshal(input.Snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	var adminID interface{}
+	if input.AdminID > 0 {
+		adminID = input.AdminID
+	}
+	var source interface{}
+	if input.SourceRevision != nil {
+		source = *input.SourceRevision
+	}
+
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO product_revisions (product_id, revision, action, source_revision, snapshot, admin_id, created_at)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ?, ?, ?
+		FROM product_revisions
+		WHERE product_id = ?
+	`, input.ProductID, input.Action, source, string(snapshotJSON), adminID, time.Now(), input.ProductID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create revision: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return id, nil
+}
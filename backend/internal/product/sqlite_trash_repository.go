@@ -0,0 +1,252 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetTrashed retrieves soft-deleted products, most recently deleted first
+func (r *SQLiteRepository) GetTrashed(ctx context.Context, page, limit int) ([]*Product, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE deleted_at IS NOT NULL").Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count trashed products: %w", err)
+	}
+
+	// Pagination
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	offset := 0
+	if page > 1 {
+		offset = (page - 1) * limit
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM products
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, productColumns)
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query trashed products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*Product{}
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan product: %w", err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return products, total, nil
+}
+
+// Restore clears the deletion mark of a soft-deleted product and records the revision
+func (r *SQLiteRepository) Restore(ctx context.Context, id int64, adminID int64) (*Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := restoreProduct(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	p, err := recordRevision(ctx, tx, id, RevisionRestore, nil, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit product restore: %w", err)
+	}
+
+	return p, nil
+}
+
+// restoreProduct clears the deletion mark using db or a transaction. It
+// returns ErrNotFound when the product is not in the trash.
+func restoreProduct(ctx context.Context, db execer, id int64) error {
+	result, err := db.ExecContext(ctx,
+		"UPDATE products SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL",
+		time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore product: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge permanently removes products soft-deleted before the cutoff together
+// with their variants, stock movements, slug history, revisions and cart
+// items. Products in orders stay in the trash, so order items, and the stock
+// reservations of their orders, keep pointing to them. It reports the images
+// of purged products that no remaining product, category or designer asset
+// references.
+func (r *SQLiteRepository) Purge(ctx context.Context, deletedBefore time.Time) (*PurgeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, images, EXISTS (SELECT 1 FROM order_items WHERE order_items.product_id = products.id)
+		FROM products
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+	`, deletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query purgeable products: %w", err)
+	}
+
+	result := &PurgeResult{ProductIDs: []int64{}, KeptIDs: []int64{}, Images: []string{}}
+	candidates := make(map[string]bool)
+	for rows.Next() {
+		var id int64
+		var imagesJSON *string
+		var ordered bool
+		if err := rows.Scan(&id, &imagesJSON, &ordered); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan product: %w", err)
+		}
+		if ordered {
+			result.KeptIDs = append(result.KeptIDs, id)
+			continue
+		}
+		result.ProductIDs = append(result.ProductIDs, id)
+		for _, image := range parseImages(imagesJSON) {
+			candidates[imageFilename(image)] = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	if len(result.ProductIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(result.ProductIDs)), ", ")
+	ids := make([]interface{}, len(result.ProductIDs))
+	for i, id := range result.ProductIDs {
+		ids[i] = id
+	}
+
+	// Rows referring to variants go before the variants
+	for _, table := range []string{"inventory_movements", "price_adjustment_items", "product_variants", "product_slug_history", "product_revisions", "product_prices", "cart_items"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE product_id IN (%s)", table, placeholders)
+		if _, err := tx.ExecContext(ctx, query, ids...); err != nil {
+			return nil, fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM products WHERE id IN (%s)", placeholders), ids...); err != nil {
+		return nil, fmt.Errorf("failed to purge products: %w", err)
+	}
+
+	// Keep images still used elsewhere, including by trashed products
+	used, err := referencedImages(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for filename := range candidates {
+		if !used[filename] {
+			result.Images = append(result.Images, filename)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return result, nil
+}
+
+// ReferencedImages retrieves the upload filenames still in use by products,
+// trashed or not, category covers and designer templates and shirt colors
+func (r *SQLiteRepository) ReferencedImages(ctx context.Context) (map[string]bool, error) {
+	return referencedImages(ctx, r.db)
+}
+
+// referencedImages returns the filenames of all images used by any product,
+// category or designer asset
+func referencedImages(ctx context.Context, db queryer) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, "SELECT images FROM products")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query product images: %w", err)
+	}
+	defer rows.Close()
+
+	used := make(map[string]bool)
+	for rows.Next() {
+		var imagesJSON *string
+		if err := rows.Scan(&imagesJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan product images: %w", err)
+		}
+		for _, image := range parseImages(imagesJSON) {
+			used[imageFilename(image)] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	assets, err := db.QueryContext(ctx, `
+		SELECT cover_image FROM categories
+		UNION SELECT preview_filename FROM design_templates
+		UNION SELECT print_filename FROM design_templates
+		UNION SELECT mockup_filename FROM shirt_colors
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category and designer images: %w", err)
+	}
+	defer assets.Close()
+
+	for assets.Next() {
+		var image string
+		if err := assets.Scan(&image); err != nil {
+			return nil, fmt.Errorf("failed to scan category or designer image: %w", err)
+		}
+		if image != "" {
+			used[imageFilename(image)] = true
+		}
+	}
+	if err := assets.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return used, nil
+}
+
+// parseImages decodes a nullable images JSON column
+func parseImages(imagesJSON *string) []string {
+	var images []string
+	if imagesJSON != nil && *imagesJSON != "" {
+		json.Unmarshal([]byte(*imagesJSON), &images)
+	}
+	return images
+}
@@ -0,0 +1,300 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/inventory"
+	"github.com/tomas/tienda-backend/internal/platform/database"
+)
+
+const variantColumns = "id, product_id, sku, size, color, stock, price, created_at, updated_at"
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// GetVariants retrieves all variants of a product
+func (r *SQLiteRepository) GetVariants(ctx context.Context, productID int64) ([]*Variant, error) {
+	return getVariants(ctx, r.db, productID)
+}
+
+// getVariants retrieves the variants of a product using db or a transaction
+func getVariants(ctx context.Context, db queryer, productID int64) ([]*Variant, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants
+		WHERE product_id = ?
+		ORDER BY id
+	`, variantColumns)
+
+	rows, err := db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query variants: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []*Variant{}
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan variant: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return variants, nil
+}
+
+// GetVariantByID retrieves a single variant of a product
+func (r *SQLiteRepository) GetVariantByID(ctx context.Context, productID, variantID int64) (*Variant, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM product_variants
+		WHERE id = ? AND product_id = ?
+	`, variantColumns)
+
+	variant, err := scanVariant(r.db.QueryRowContext(ctx, query, variantID, productID))
+	if err == sql.ErrNoRows {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %w", err)
+	}
+
+	return variant, nil
+}
+
+// CreateVariant adds a variant to an existing product
+func (r *SQLiteRepository) CreateVariant(ctx context.Context, productID int64, input CreateVariantInput) (*Variant, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Check if product exists, within the transaction so it cannot be
+	// deleted before the variant is inserted
+	if _, err := getProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	id, err := insertVariant(ctx, tx, productID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit variant: %w", err)
+	}
+
+	return r.GetVariantByID(ctx, productID, id)
+}
+
+// UpdateVariant updates an existing variant of a product
+func (r *SQLiteRepository) UpdateVariant(ctx context.Context, productID, variantID int64, input UpdateVariantInput) (*Variant, error) {
+	// Check if variant exists
+	if _, err := r.GetVariantByID(ctx, productID, variantID); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateVariant(ctx, tx, productID, variantID, input); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit variant: %w", err)
+	}
+
+	return r.GetVariantByID(ctx, productID, variantID)
+}
+
+// updateVariant applies input to a variant using db or a transaction
+func updateVariant(ctx context.Context, db execer, productID, variantID int64, input UpdateVariantInput) error {
+	// Build UPDATE query dynamically
+	var setClauses []string
+	var args []interface{}
+
+	if input.SKU != nil {
+		setClauses = append(setClauses, "sku = ?")
+		args = append(args, strings.TrimSpace(*input.SKU))
+	}
+	if input.Size != nil {
+		setClauses = append(setClauses, "size = ?")
+		args = append(args, *input.Size)
+	}
+	if input.Color != nil {
+		setClauses = append(setClauses, "color = ?")
+		args = append(args, *input.Color)
+	}
+	if input.Stock != nil {
+		setClauses = append(setClauses, "stock = ?")
+		args = append(args, *input.Stock)
+	}
+	if input.Price != nil {
+		setClauses = append(setClauses, "price = ?")
+		if *input.Price == 0 {
+			args = append(args, nil)
+		} else {
+			args = append(args, *input.Price)
+		}
+	}
+
+	// Always update updated_at
+	setClauses = append(setClauses, "updated_at = ?")
+	args = append(args, time.Now())
+
+	args = append(args, variantID, productID)
+
+	query := fmt.Sprintf(
+		"UPDATE product_variants SET %s WHERE id = ? AND product_id = ?",
+		strings.Join(setClauses, ", "),
+	)
+
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDuplicateVariant
+		}
+		return fmt.Errorf("failed to update variant: %w", err)
+	}
+
+	if input.Stock != nil {
+		return syncVariantLedger(ctx, db, variantID, "Variant stock updated")
+	}
+
+	return nil
+}
+
+// DeleteVariant removes a variant from a product, taking its stock out of
+// the ledger. The variant's stock movements stay in the product's ledger
+// without it; variants that stock reservations or price adjustments refer
+// to are kept, since returning the stock or reverting the price needs them.
+func (r *SQLiteRepository) DeleteVariant(ctx context.Context, productID, variantID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM stock_reservations WHERE variant_id = ?)
+			OR EXISTS (SELECT 1 FROM price_adjustment_items WHERE variant_id = ?)
+	`, variantID, variantID).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("failed to check variant references: %w", err)
+	}
+	if inUse {
+		return ErrVariantInUse
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE product_variants SET stock = 0 WHERE id = ? AND product_id = ?",
+		variantID, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear variant stock: %w", err)
+	}
+	if err := syncVariantLedger(ctx, tx, variantID, "Variant deleted"); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE inventory_movements SET variant_id = NULL WHERE variant_id = ?", variantID); err != nil {
+		return fmt.Errorf("failed to detach variant movements: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"DELETE FROM product_variants WHERE id = ? AND product_id = ?",
+		variantID, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete variant: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrVariantNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit variant deletion: %w", err)
+	}
+
+	return nil
+}
+
+// insertVariant inserts a variant row and returns its ID
+func insertVariant(ctx context.Context, db execer, productID int64, input CreateVariantInput) (int64, error) {
+	sku := strings.TrimSpace(input.SKU)
+	if sku == "" {
+		sku = generateSKU(productID, input.Size, input.Color)
+	}
+
+	var price interface{}
+	if input.Price != nil {
+		price = *input.Price
+	}
+
+	now := time.Now()
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO product_variants (product_id, sku, size, color, stock, price, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, productID, sku, input.Size, input.Color, input.Stock, price, now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return 0, ErrDuplicateVariant
+		}
+		return 0, fmt.Errorf("failed to create variant: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	if err := syncVariantLedger(ctx, db, id, "Initial variant stock"); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// syncVariantLedger records an adjustment bringing the ledger movements of a
+// variant in line with its stock. Variant stock set directly is part of the
+// product's on-hand like any other movement, so both always agree.
+func syncVariantLedger(ctx context.Context, db execer, variantID int64, reason string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO inventory_movements (product_id, variant_id, type, quantity, reason, admin_id, created_at)
+		SELECT v.product_id, v.id, ?, v.stock - COALESCE(SUM(m.quantity), 0), ?, NULL, ?
+		FROM product_variants v
+		LEFT JOIN inventory_movements m ON m.variant_id = v.id
+		WHERE v.id = ?
+		GROUP BY v.id
+		HAVING v.stock - COALESCE(SUM(m.quantity), 0) != 0
+	`, inventory.MovementAdjustment, reason, time.Now(), variantID)
+	if err != nil {
+		return fmt.Errorf("failed to record variant stock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package product
+
+import "path"
+
+// PurgeResult describes a permanent deletion of trashed products
+type PurgeResult struct {
+	ProductIDs []int64  `json:"product_ids"`
+	KeptIDs    []int64  `json:"kept_product_ids"` // Products left in the trash because orders include them
+	Images     []string `json:"images"`           // upload filenames no longer referenced by products, categories or the designer
+}
+
+// imageFilename extracts the upload filename from an image URL such as
+// "https://host/uploads/1700000000_uuid.png"
+func imageFilename(imageURL string) string {
+	return path.Base(imageURL)
+}
@@ -0,0 +1,167 @@
+package product_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/category"
+	"github.com/tomas/tienda-backend/internal/design"
+	"github.com/tomas/tienda-backend/internal/order"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+const (
+	trashPrice     = 550000
+	trashImage     = "https://tienda.example/uploads/1700000000_remera.png"
+	trashFilename  = "1700000000_remera.png"
+	coverImage     = "https://tienda.example/uploads/1700000001_portada.png"
+	coverFilename  = "1700000001_portada.png"
+	printFilename  = "1700000002_estampa.png"
+	unusedImage    = "https://tienda.example/uploads/1700000003_sin_uso.png"
+	unusedFilename = "1700000003_sin_uso.png"
+	holdFor        = 15 * time.Minute
+)
+
+type trashSuite struct {
+	suite.Suite
+	ctx        context.Context
+	svc        *product.Service
+	carts      *cart.Service
+	orders     *order.Service
+	categories *category.Service
+	designs    *design.SQLiteRepository
+}
+
+func TestTrashSuite(t *testing.T) {
+	suite.Run(t, new(trashSuite))
+}
+
+func (s *trashSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.svc = product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), s.svc)
+	s.carts = cart.NewService(cart.NewSQLiteRepository(db), s.svc, promotions, time.Hour)
+	s.orders = order.NewService(order.NewSQLiteRepository(db), s.svc, promotions, holdFor)
+	s.categories = category.NewService(category.NewSQLiteRepository(db))
+	s.designs = design.NewSQLiteRepository(db)
+}
+
+// create creates a product to move to the trash
+func (s *trashSuite) create(name string, images []string) *product.Product {
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{Name: name, Price: trashPrice, Images: images})
+	s.Require().NoError(err)
+	return p
+}
+
+func (s *trashSuite) TestPurgeRemovesCartItems() {
+	p := s.create("Remera en carrito", []string{trashImage})
+	c, err := s.carts.CreateCart(s.ctx)
+	s.Require().NoError(err)
+	_, err = s.carts.AddItem(s.ctx, c.Token, cart.AddItemInput{ProductID: p.ID, Quantity: 1})
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, 0))
+
+	result, err := s.svc.PurgeTrash(s.ctx, 0)
+	s.Require().NoError(err)
+	emptied, cartErr := s.carts.GetCart(s.ctx, c.Token)
+
+	s.Equal([]int64{p.ID}, result.ProductIDs)
+	s.Empty(result.KeptIDs)
+	s.Equal([]string{trashFilename}, result.Images)
+	s.Require().NoError(cartErr)
+	s.Empty(emptied.Items)
+}
+
+func (s *trashSuite) TestPurgeRemovesVariantsWithTheirLedger() {
+	p, err := s.svc.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:     "Remera con talles",
+		Price:    trashPrice,
+		Sizes:    []string{"M"},
+		Variants: []product.CreateVariantInput{{Size: "M", Stock: 5}},
+	})
+	s.Require().NoError(err)
+	_, err = s.svc.CreatePriceAdjustment(s.ctx, product.PriceAdjustmentInput{Type: product.AdjustmentFixed, Value: 1000})
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, 0))
+
+	result, err := s.svc.PurgeTrash(s.ctx, 0)
+
+	s.Require().NoError(err)
+	s.Equal([]int64{p.ID}, result.ProductIDs)
+}
+
+func (s *trashSuite) TestPurgeKeepsImagesUsedElsewhere() {
+	purged := s.create("Remera vieja", []string{trashImage, coverImage, "https://tienda.example/uploads/" + printFilename, unusedImage})
+	ordered := s.create("Remera vendida", []string{trashImage})
+	_, err := s.orders.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:    []order.CreateItemInput{{ProductID: ordered.ID, Quantity: 1}},
+	})
+	s.Require().NoError(err)
+	_, err = s.categories.CreateCategory(s.ctx, category.CreateCategoryInput{Name: "Remeras", CoverImage: coverImage})
+	s.Require().NoError(err)
+	active := true
+	template, err := s.designs.CreateTemplate(s.ctx, design.CreateTemplateInput{Slug: "estampa", Name: "Estampa", Active: &active})
+	s.Require().NoError(err)
+	_, err = s.designs.SetTemplateImage(s.ctx, template.ID, design.ImagePrint, "/uploads/design-assets/"+printFilename, printFilename)
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, purged.ID, 0))
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, ordered.ID, 0))
+
+	result, err := s.svc.PurgeTrash(s.ctx, 0)
+	s.Require().NoError(err)
+	used, usedErr := s.svc.ReferencedImages(s.ctx)
+
+	s.Equal([]int64{purged.ID}, result.ProductIDs)
+	s.Equal([]string{unusedFilename}, result.Images)
+	s.Require().NoError(usedErr)
+	s.Equal(map[string]bool{trashFilename: true, coverFilename: true, printFilename: true}, used)
+}
+
+func (s *trashSuite) TestPurgeKeepsOrderedProducts() {
+	ordered := s.create("Remera vendida", []string{trashImage})
+	unsold := s.create("Remera sin ventas", []string{trashImage})
+	o, err := s.orders.CreateOrder(s.ctx, order.CreateOrderInput{
+		Customer: order.Customer{Name: "Ana", Phone: "+5491100000000"},
+		Items:    []order.CreateItemInput{{ProductID: ordered.ID, Quantity: 1}},
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, ordered.ID, 0))
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, unsold.ID, 0))
+
+	result, err := s.svc.PurgeTrash(s.ctx, 0)
+	s.Require().NoError(err)
+	trash, total, trashErr := s.svc.ListTrash(s.ctx, 1, 10)
+	saved, orderErr := s.orders.GetOrder(s.ctx, o.ID)
+
+	s.Equal([]int64{unsold.ID}, result.ProductIDs)
+	s.Equal([]int64{ordered.ID}, result.KeptIDs)
+	s.Empty(result.Images)
+	s.Require().NoError(trashErr)
+	s.Equal(1, total)
+	s.Equal(ordered.ID, trash[0].ID)
+	s.Require().NoError(orderErr)
+	s.Equal(ordered.ID, saved.Items[0].ProductID)
+}
+
+func (s *trashSuite) TestPurgeHonoursRetention() {
+	p := s.create("Remera reciente", nil)
+	s.Require().NoError(s.svc.DeleteProduct(s.ctx, p.ID, 0))
+
+	result, err := s.svc.PurgeTrash(s.ctx, 24*time.Hour)
+	_, negativeErr := s.svc.PurgeTrash(s.ctx, -time.Hour)
+	restored, restoreErr := s.svc.RestoreProduct(s.ctx, p.ID, 0)
+
+	s.Require().NoError(err)
+	s.Empty(result.ProductIDs)
+	s.ErrorIs(negativeErr, product.ErrValidation)
+	s.Require().NoError(restoreErr)
+	s.Nil(restored.DeletedAt)
+}
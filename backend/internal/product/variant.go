@@ -0,0 +1,190 @@
+package product
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Variant represents a concrete size/color combination of a product
+type Variant struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	SKU       string    `json:"sku"`
+	Size      string    `json:"size"`
+	Color     string    `json:"color"`
+	Stock     int       `json:"stock"`
+	Price     *int      `json:"price,omitempty"` // Overrides product price when set, in cents
+	Available bool      `json:"available"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateVariantInput represents input for creating a product variant
+type CreateVariantInput struct {
+	SKU   string `json:"sku"`
+	Size  string `json:"size"`
+	Color string `json:"color"`
+	Stock int    `json:"stock"`
+	Price *int   `json:"price,omitempty"`
+}
+
+// UpdateVariantInput represents input for updating a product variant.
+// A price of 0 removes the override so the product price applies again.
+type UpdateVariantInput struct {
+	SKU   *string `json:"sku,omitempty"`
+	Size  *string `json:"size,omitempty"`
+	Color *string `json:"color,omitempty"`
+	Stock *int    `json:"stock,omitempty"`
+	Price *int    `json:"price,omitempty"`
+}
+
+// Validate validates variant creation input
+func (input *CreateVariantInput) Validate() error {
+	if input.Size == "" && input.Color == "" {
+		return ErrInvalidInput("variant requires a size or a color")
+	}
+	if input.Stock < 0 {
+		return ErrInvalidInput("variant stock cannot be negative")
+	}
+	if input.Price != nil && *input.Price <= 0 {
+		return ErrInvalidInput("variant price must be greater than 0")
+	}
+	return nil
+}
+
+// Validate validates variant update input
+func (input *UpdateVariantInput) Validate() error {
+	if input.SKU != nil && strings.TrimSpace(*input.SKU) == "" {
+		return ErrInvalidInput("variant sku cannot be empty")
+	}
+	if input.Stock != nil && *input.Stock < 0 {
+		return ErrInvalidInput("variant stock cannot be negative")
+	}
+	if input.Price != nil && *input.Price < 0 {
+		return ErrInvalidInput("variant price cannot be negative")
+	}
+	return nil
+}
+
+var skuInvalidChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// generateSKU builds a default SKU like "P12-M-NEGRO" for a variant
+func generateSKU(productID int64, size, color string) string {
+	parts := []string{fmt.Sprintf("P%d", productID)}
+	for _, value := range []string{size, color} {
+		value = skuInvalidChars.ReplaceAllString(strings.ToUpper(slug.FoldAccents(value)), "")
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// scanVariant scans a database row into a Variant
+func scanVariant(row interface{ Scan(...interface{}) error }) (*Variant, error) {
+	var v Variant
+	var price sql.NullInt64
+
+	err := row.Scan(
+		&v.ID,
+		&v.ProductID,
+		&v.SKU,
+		&v.Size,
+		&v.Color,
+		&v.Stock,
+		&price,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if price.Valid {
+		p := int(price.Int64)
+		v.Price = &p
+	}
+	v.Available = v.Stock > 0
+
+	return &v, nil
+}
+
+// Selection is a size and color of a product as the product spells them,
+// with the variant they correspond to and its price
+type Selection struct {
+	Size      string
+	Color     string
+	Variant   *Variant // Nil for products without variants
+	UnitPrice int      // Price charged right now, in cents
+}
+
+// Select matches a size and color, ignoring case, to the available variants
+// of the product or, when it has none, to its sizes and colors
+func (p *Product) Select(size, color string) (*Selection, error) {
+	sel := &Selection{UnitPrice: p.EffectivePrice}
+
+	if len(p.Variants) > 0 {
+		for _, v := range p.Variants {
+			if strings.EqualFold(v.Size, size) && strings.EqualFold(v.Color, color) {
+				if !v.Available {
+					break
+				}
+				sel.Size, sel.Color, sel.Variant = v.Size, v.Color, v
+				sel.UnitPrice = p.VariantPrice(v)
+				return sel, nil
+			}
+		}
+		return nil, ErrInvalidInput(fmt.Sprintf("%s is not available in %s", p.Name, describeOption(size, color)))
+	}
+
+	if size == "" && len(p.Sizes) > 0 {
+		return nil, ErrInvalidInput("size is required")
+	}
+	if color == "" && len(p.Colors) > 0 {
+		return nil, ErrInvalidInput("color is required")
+	}
+
+	var ok bool
+	if sel.Size, ok = matchOption(p.Sizes, size); !ok {
+		return nil, ErrInvalidInput(fmt.Sprintf("size %q is not available for %s", size, p.Name))
+	}
+	if sel.Color, ok = matchOption(p.Colors, color); !ok {
+		return nil, ErrInvalidInput(fmt.Sprintf("color %q is not available for %s", color, p.Name))
+	}
+
+	return sel, nil
+}
+
+// matchOption finds value among the sizes or colors of a product. Products
+// without options only match an empty value.
+func matchOption(options []string, value string) (string, bool) {
+	if len(options) == 0 {
+		return "", value == ""
+	}
+	for _, o := range options {
+		if strings.EqualFold(o, value) {
+			return o, true
+		}
+	}
+	return "", false
+}
+
+// describeOption describes a size and color for messages
+func describeOption(size, color string) string {
+	parts := make([]string, 0, 2)
+	if size != "" {
+		parts = append(parts, "size "+size)
+	}
+	if color != "" {
+		parts = append(parts, color)
+	}
+	if len(parts) == 0 {
+		return "this option"
+	}
+	return strings.Join(parts, ", ")
+}
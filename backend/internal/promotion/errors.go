@@ -0,0 +1,25 @@
+package promotion
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound indicates a promotion was not found
+	ErrNotFound = errors.New("promotion not found")
+
+	// ErrDuplicateCode indicates another promotion already uses the coupon code
+	ErrDuplicateCode = errors.New("coupon code already exists")
+
+	// ErrUsageLimit indicates a promotion has been used as many times as allowed
+	ErrUsageLimit = errors.New("promotion usage limit reached")
+
+	// ErrValidation is wrapped by every ErrInvalidInput error
+	ErrValidation = errors.New("invalid input")
+
+	// ErrInvalidInput indicates invalid input data
+	ErrInvalidInput = func(msg string) error {
+		return fmt.Errorf("%w: %s", ErrValidation, msg)
+	}
+)
@@ -0,0 +1,241 @@
+package promotion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// Promotion types
+const (
+	TypePercentage = "percentage" // Value percent off each matching unit
+	TypeFixed      = "fixed"      // Value cents off each matching unit
+	TypeBundle     = "bundle"     // Take BuyQuantity units, pay PayQuantity: 3x2, 2x1
+)
+
+// Promotion scopes
+const (
+	ScopeAll      = "all"
+	ScopeCategory = "category" // A category and its subcategories
+	ScopeTag      = "tag"
+	ScopeProduct  = "product"
+)
+
+// MaxQuantity is the largest quantity of a single quote line
+const MaxQuantity = 1000
+
+// Promotion is a discount applied automatically or through a coupon code
+type Promotion struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Code        string     `json:"code,omitempty"` // Coupon code; the promotion applies automatically when empty
+	Type        string     `json:"type"`
+	Value       int        `json:"value,omitempty"`        // Percent or amount in cents
+	BuyQuantity int        `json:"buy_quantity,omitempty"` // Bundles only
+	PayQuantity int        `json:"pay_quantity,omitempty"` // Bundles only
+	Scope       string     `json:"scope"`
+	Category    string     `json:"category,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	ProductIDs  []int64    `json:"product_ids,omitempty"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	UsageLimit  *int       `json:"usage_limit,omitempty"` // Unlimited when empty
+	UsageCount  int        `json:"usage_count"`
+	Active      bool       `json:"active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	categoryIDs map[int64]bool // IDs of the scoped category tree, loaded for quotes
+}
+
+// PromotionInput represents input for creating or replacing a promotion
+type PromotionInput struct {
+	Name        string     `json:"name"`
+	Code        string     `json:"code,omitempty"`
+	Type        string     `json:"type"`
+	Value       int        `json:"value,omitempty"`
+	BuyQuantity int        `json:"buy_quantity,omitempty"`
+	PayQuantity int        `json:"pay_quantity,omitempty"`
+	Scope       string     `json:"scope,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	ProductIDs  []int64    `json:"product_ids,omitempty"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	UsageLimit  *int       `json:"usage_limit,omitempty"`
+	Active      *bool      `json:"active,omitempty"` // Defaults to true
+}
+
+// Validate validates promotion input and normalizes the coupon code
+func (input *PromotionInput) Validate() error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return ErrInvalidInput("name is required")
+	}
+	input.Code = NormalizeCode(input.Code)
+
+	switch input.Type {
+	case TypePercentage:
+		if input.Value <= 0 || input.Value > 100 {
+			return ErrInvalidInput("percentage value must be between 1 and 100")
+		}
+	case TypeFixed:
+		if input.Value <= 0 {
+			return ErrInvalidInput("fixed value must be greater than 0")
+		}
+	case TypeBundle:
+		if input.PayQuantity <= 0 || input.BuyQuantity <= input.PayQuantity {
+			return ErrInvalidInput("bundles need buy_quantity greater than pay_quantity, e.g. 3 and 2")
+		}
+	default:
+		return ErrInvalidInput("type must be percentage, fixed or bundle")
+	}
+
+	input.Category = strings.TrimSpace(input.Category)
+	input.Tag = strings.TrimSpace(input.Tag)
+	switch input.Scope {
+	case "", ScopeAll:
+		input.Scope = ScopeAll
+	case ScopeCategory:
+		if input.Category == "" {
+			return ErrInvalidInput("category scope requires a category")
+		}
+	case ScopeTag:
+		if input.Tag == "" {
+			return ErrInvalidInput("tag scope requires a tag")
+		}
+	case ScopeProduct:
+		if len(input.ProductIDs) == 0 {
+			return ErrInvalidInput("product scope requires product_ids")
+		}
+	default:
+		return ErrInvalidInput("scope must be all, category, tag or product")
+	}
+
+	if input.StartsAt != nil && input.EndsAt != nil && !input.EndsAt.After(*input.StartsAt) {
+		return ErrInvalidInput("ends_at must be after starts_at")
+	}
+	if input.UsageLimit != nil && *input.UsageLimit <= 0 {
+		return ErrInvalidInput("usage_limit must be greater than 0")
+	}
+	if input.Active == nil {
+		active := true
+		input.Active = &active
+	}
+
+	return nil
+}
+
+// NormalizeCode formats a coupon code the way it is stored, so codes match
+// regardless of case and surrounding spaces
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// AvailableAt reports whether the promotion can be applied at t
+func (p *Promotion) AvailableAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return p.UsageLimit == nil || p.UsageCount < *p.UsageLimit
+}
+
+// unavailableReason explains why a coupon cannot be applied at t
+func (p *Promotion) unavailableReason(t time.Time) string {
+	switch {
+	case !p.Active:
+		return "coupon is not active"
+	case p.StartsAt != nil && t.Before(*p.StartsAt):
+		return "coupon is not valid yet"
+	case p.EndsAt != nil && !t.Before(*p.EndsAt):
+		return "coupon has expired"
+	default:
+		return "coupon usage limit reached"
+	}
+}
+
+// appliesTo reports whether a product is in the scope of the promotion
+func (p *Promotion) appliesTo(prod *product.Product) bool {
+	switch p.Scope {
+	case ScopeCategory:
+		if prod.CategoryID != nil {
+			return p.categoryIDs[*prod.CategoryID]
+		}
+		// Free-text categories match by slug
+		return slug.Make(prod.Category) == slug.Make(p.Category)
+	case ScopeTag:
+		for _, tag := range prod.Tags {
+			if strings.EqualFold(tag, p.Tag) {
+				return true
+			}
+		}
+		return false
+	case ScopeProduct:
+		for _, id := range p.ProductIDs {
+			if id == prod.ID {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// scanPromotion scans a database row into a Promotion
+func scanPromotion(row interface{ Scan(...interface{}) error }) (*Promotion, error) {
+	var p Promotion
+	var productIDsJSON string
+	var startsAt, endsAt sql.NullTime
+	var usageLimit sql.NullInt64
+	var active int
+
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Code,
+		&p.Type,
+		&p.Value,
+		&p.BuyQuantity,
+		&p.PayQuantity,
+		&p.Scope,
+		&p.Category,
+		&p.Tag,
+		&productIDsJSON,
+		&startsAt,
+		&endsAt,
+		&usageLimit,
+		&p.UsageCount,
+		&active,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal([]byte(productIDsJSON), &p.ProductIDs)
+	if startsAt.Valid {
+		p.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		p.EndsAt = &endsAt.Time
+	}
+	if usageLimit.Valid {
+		limit := int(usageLimit.Int64)
+		p.UsageLimit = &limit
+	}
+	p.Active = active == 1
+
+	return &p, nil
+}
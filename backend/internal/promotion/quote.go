@@ -0,0 +1,148 @@
+package promotion
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// QuoteInput represents the products and coupon to price
+type QuoteInput struct {
+	Items  []QuoteItemInput `json:"items"`
+	Coupon string           `json:"coupon,omitempty"`
+}
+
+// QuoteItemInput is a product and quantity to price. A variant with its
+// own price overrides the product price.
+type QuoteItemInput struct {
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Quote reports the prices and discounts of a list of products
+type Quote struct {
+	Lines    []QuoteLine   `json:"lines"`
+	Subtotal int           `json:"subtotal"`
+	Discount int           `json:"discount"`
+	Total    int           `json:"total"`
+	Coupon   *CouponResult `json:"coupon,omitempty"`
+}
+
+// QuoteLine is a priced product of a quote. At most one promotion applies
+// to each line: the one giving the largest discount.
+type QuoteLine struct {
+	ProductID int64             `json:"product_id"`
+	VariantID *int64            `json:"variant_id,omitempty"`
+	Name      string            `json:"name"`
+	Quantity  int               `json:"quantity"`
+	UnitPrice int               `json:"unit_price"`
+	Subtotal  int               `json:"subtotal"`
+	Discount  int               `json:"discount"`
+	Total     int               `json:"total"`
+	Promotion *AppliedPromotion `json:"promotion,omitempty"`
+}
+
+// AppliedPromotion identifies the promotion discounting a line
+type AppliedPromotion struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code,omitempty"`
+}
+
+// CouponResult reports whether the coupon of a quote could be applied
+type CouponResult struct {
+	Code  string `json:"code"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// Validate validates quote input
+func (input *QuoteInput) Validate() error {
+	if len(input.Items) == 0 {
+		return ErrInvalidInput("items are required")
+	}
+	for _, item := range input.Items {
+		if item.ProductID <= 0 {
+			return ErrInvalidInput("product_id is required")
+		}
+		if item.Quantity <= 0 || item.Quantity > MaxQuantity {
+			return ErrInvalidInput(fmt.Sprintf("quantity must be between 1 and %d", MaxQuantity))
+		}
+	}
+	input.Coupon = NormalizeCode(input.Coupon)
+	return nil
+}
+
+// discounts computes the discount of a promotion on each line. matches
+// tells which lines are in the promotion's scope.
+func (p *Promotion) discounts(lines []QuoteLine, matches []bool) []int {
+	discounts := make([]int, len(lines))
+
+	switch p.Type {
+	case TypePercentage:
+		for i, line := range lines {
+			if matches[i] {
+				discounts[i] = int(math.Round(float64(line.Subtotal) * float64(p.Value) / 100))
+			}
+		}
+	case TypeFixed:
+		for i, line := range lines {
+			if matches[i] {
+				discounts[i] = min(p.Value, line.UnitPrice) * line.Quantity
+			}
+		}
+	case TypeBundle:
+		// Matching units are grouped across lines and the cheapest ones are free
+		units := 0
+		var order []int
+		for i, line := range lines {
+			if matches[i] {
+				units += line.Quantity
+				order = append(order, i)
+			}
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return lines[order[a]].UnitPrice < lines[order[b]].UnitPrice
+		})
+
+		free := units / p.BuyQuantity * (p.BuyQuantity - p.PayQuantity)
+		for _, i := range order {
+			if free == 0 {
+				break
+			}
+			n := min(free, lines[i].Quantity)
+			discounts[i] = n * lines[i].UnitPrice
+			free -= n
+		}
+	}
+
+	return discounts
+}
+
+// applyPromotions gives each line the promotion with the largest discount
+// and computes the totals
+func applyPromotions(q *Quote, promotions []*Promotion, matches func(*Promotion, int) bool) {
+	for _, p := range promotions {
+		scope := make([]bool, len(q.Lines))
+		for i := range q.Lines {
+			scope[i] = matches(p, i)
+		}
+
+		for i, discount := range p.discounts(q.Lines, scope) {
+			if discount > q.Lines[i].Discount {
+				q.Lines[i].Discount = discount
+				q.Lines[i].Promotion = &AppliedPromotion{ID: p.ID, Name: p.Name, Code: p.Code}
+			}
+		}
+	}
+
+	q.Subtotal, q.Discount, q.Total = 0, 0, 0
+	for i := range q.Lines {
+		line := &q.Lines[i]
+		line.Total = line.Subtotal - line.Discount
+		q.Subtotal += line.Subtotal
+		q.Discount += line.Discount
+		q.Total += line.Total
+	}
+}
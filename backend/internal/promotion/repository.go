@@ -0,0 +1,30 @@
+package promotion
+
+import "context"
+
+// Repository defines the interface for promotion data access
+type Repository interface {
+	// GetAll retrieves all promotions, newest first
+	GetAll(ctx context.Context) ([]*Promotion, error)
+
+	// GetByID retrieves a single promotion by ID
+	GetByID(ctx context.Context, id int64) (*Promotion, error)
+
+	// GetByCode retrieves the promotion of a normalized coupon code
+	GetByCode(ctx context.Context, code string) (*Promotion, error)
+
+	// GetAutomatic retrieves the active promotions that apply without a coupon
+	GetAutomatic(ctx context.Context) ([]*Promotion, error)
+
+	// Create creates a new promotion
+	Create(ctx context.Context, input PromotionInput) (*Promotion, error)
+
+	// Update replaces the settings of an existing promotion, keeping its usage count
+	Update(ctx context.Context, id int64, input PromotionInput) (*Promotion, error)
+
+	// Delete removes a promotion
+	Delete(ctx context.Context, id int64) error
+
+	// GetCategoryTree retrieves the IDs of a category, by slug, and of its subcategories
+	GetCategoryTree(ctx context.Context, category string) (map[int64]bool, error)
+}
@@ -0,0 +1,167 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/product"
+)
+
+// Service provides business logic for promotions
+type Service struct {
+	repo     Repository
+	products *product.Service
+}
+
+// NewService creates a new promotion service. Quotes price products
+// through productService.
+func NewService(repo Repository, productService *product.Service) *Service {
+	return &Service{repo: repo, products: productService}
+}
+
+// GetPromotions retrieves all promotions, newest first
+func (s *Service) GetPromotions(ctx context.Context) ([]*Promotion, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetPromotion retrieves a single promotion by ID
+func (s *Service) GetPromotion(ctx context.Context, id int64) (*Promotion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// CreatePromotion creates a new promotion with validation
+func (s *Service) CreatePromotion(ctx context.Context, input PromotionInput) (*Promotion, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, input)
+}
+
+// UpdatePromotion replaces the settings of a promotion
+func (s *Service) UpdatePromotion(ctx context.Context, id int64, input PromotionInput) (*Promotion, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Update(ctx, id, input)
+}
+
+// DeletePromotion removes a promotion
+func (s *Service) DeletePromotion(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Quote prices a list of products at their current effective price and
+// applies the available automatic promotions and the coupon, if any. An
+// unusable coupon does not fail the quote; it is reported in Quote.Coupon.
+func (s *Service) Quote(ctx context.Context, input QuoteInput) (*Quote, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	q := &Quote{Lines: make([]QuoteLine, 0, len(input.Items))}
+	lineProducts := make([]*product.Product, 0, len(input.Items))
+	cache := make(map[int64]*product.Product)
+
+	for _, item := range input.Items {
+		p, ok := cache[item.ProductID]
+		if !ok {
+			var err error
+			p, err = s.products.GetProduct(ctx, item.ProductID)
+			if errors.Is(err, product.ErrNotFound) {
+				return nil, ErrInvalidInput(fmt.Sprintf("product %d not found", item.ProductID))
+			}
+			if err != nil {
+				return nil, err
+			}
+			cache[item.ProductID] = p
+		}
+
+		unitPrice, err := unitPrice(p, item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		q.Lines = append(q.Lines, QuoteLine{
+			ProductID: p.ID,
+			VariantID: item.VariantID,
+			Name:      p.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: unitPrice,
+			Subtotal:  unitPrice * item.Quantity,
+		})
+		lineProducts = append(lineProducts, p)
+	}
+
+	automatic, err := s.repo.GetAutomatic(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var promotions []*Promotion
+	for _, p := range automatic {
+		if p.AvailableAt(now) {
+			promotions = append(promotions, p)
+		}
+	}
+
+	var coupon *Promotion
+	if input.Coupon != "" {
+		q.Coupon = &CouponResult{Code: input.Coupon}
+		coupon, err = s.repo.GetByCode(ctx, input.Coupon)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			q.Coupon.Error = "unknown coupon"
+		case err != nil:
+			return nil, err
+		case !coupon.AvailableAt(now):
+			q.Coupon.Error = coupon.unavailableReason(now)
+		default:
+			promotions = append(promotions, coupon)
+		}
+	}
+
+	for _, p := range promotions {
+		if p.Scope == ScopeCategory {
+			if p.categoryIDs, err = s.repo.GetCategoryTree(ctx, p.Category); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	applyPromotions(q, promotions, func(p *Promotion, i int) bool {
+		return p.appliesTo(lineProducts[i])
+	})
+
+	if q.Coupon != nil && q.Coupon.Error == "" {
+		for _, line := range q.Lines {
+			if line.Promotion != nil && line.Promotion.ID == coupon.ID {
+				q.Coupon.Valid = true
+			}
+		}
+		if !q.Coupon.Valid {
+			q.Coupon.Error = "coupon gives no discount on these products"
+		}
+	}
+
+	return q, nil
+}
+
+// unitPrice returns the price of a product, or of one of its variants
+func unitPrice(p *product.Product, variantID *int64) (int, error) {
+	if variantID == nil {
+		return p.EffectivePrice, nil
+	}
+
+	for _, v := range p.Variants {
+		if v.ID == *variantID {
+			return p.VariantPrice(v), nil
+		}
+	}
+
+	return 0, ErrInvalidInput(fmt.Sprintf("variant %d not found in product %d", *variantID, p.ID))
+}
@@ -0,0 +1,234 @@
+package promotion_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Products created for every test, by their position in promotionSuite.ids
+const (
+	remera = iota
+	musculosa
+	buzo
+)
+
+const (
+	remeraPrice    = 10000
+	musculosaPrice = 6000
+	buzoPrice      = 20000
+	remerasTag     = "remeras"
+)
+
+type promotionSuite struct {
+	suite.Suite
+	ctx      context.Context
+	products *product.Service
+	svc      *promotion.Service
+	ids      []int64
+}
+
+func TestPromotionSuite(t *testing.T) {
+	suite.Run(t, new(promotionSuite))
+}
+
+func (s *promotionSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	s.products = product.NewService(product.NewSQLiteRepository(db))
+	s.svc = promotion.NewService(promotion.NewSQLiteRepository(db), s.products)
+
+	s.ids = []int64{
+		s.createProduct("Remera", remeraPrice, remerasTag),
+		s.createProduct("Musculosa", musculosaPrice, remerasTag),
+		s.createProduct("Buzo", buzoPrice, ""),
+	}
+}
+
+// createProduct creates a product and returns its ID
+func (s *promotionSuite) createProduct(name string, price int, tag string) int64 {
+	p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{Name: name, Price: price, Tags: []string{tag}})
+	s.Require().NoError(err)
+	return p.ID
+}
+
+// quote prices quantities of the test products, indexed by position, with a coupon
+func (s *promotionSuite) quote(coupon string, quantities map[int]int) *promotion.Quote {
+	input := promotion.QuoteInput{Coupon: coupon}
+	for _, i := range []int{remera, musculosa, buzo} {
+		if quantities[i] > 0 {
+			input.Items = append(input.Items, promotion.QuoteItemInput{ProductID: s.ids[i], Quantity: quantities[i]})
+		}
+	}
+
+	q, err := s.svc.Quote(s.ctx, input)
+	s.Require().NoError(err)
+	return q
+}
+
+var quoteCases = []struct {
+	name          string
+	promotion     promotion.PromotionInput
+	quantities    map[int]int
+	wantDiscounts []int // Per line, in product order
+	wantTotal     int
+}{
+	{
+		name:          "Percentage on every product",
+		promotion:     promotion.PromotionInput{Type: promotion.TypePercentage, Value: 15},
+		quantities:    map[int]int{remera: 1, buzo: 1},
+		wantDiscounts: []int{1500, 3000},
+		wantTotal:     25500,
+	},
+	{
+		name:          "Percentage rounds each line",
+		promotion:     promotion.PromotionInput{Type: promotion.TypePercentage, Value: 33},
+		quantities:    map[int]int{musculosa: 1},
+		wantDiscounts: []int{1980},
+		wantTotal:     4020,
+	},
+	{
+		name:          "Fixed amount per unit",
+		promotion:     promotion.PromotionInput{Type: promotion.TypeFixed, Value: 1000},
+		quantities:    map[int]int{remera: 3},
+		wantDiscounts: []int{3000},
+		wantTotal:     27000,
+	},
+	{
+		name:          "Fixed amount capped at the unit price",
+		promotion:     promotion.PromotionInput{Type: promotion.TypeFixed, Value: 8000},
+		quantities:    map[int]int{musculosa: 2, buzo: 1},
+		wantDiscounts: []int{2 * musculosaPrice, 8000},
+		wantTotal:     12000,
+	},
+	{
+		name:          "Bundle makes the cheapest unit free",
+		promotion:     promotion.PromotionInput{Type: promotion.TypeBundle, BuyQuantity: 3, PayQuantity: 2},
+		quantities:    map[int]int{remera: 2, musculosa: 1},
+		wantDiscounts: []int{0, musculosaPrice},
+		wantTotal:     2 * remeraPrice,
+	},
+	{
+		name:          "Bundle frees units across lines, cheapest first",
+		promotion:     promotion.PromotionInput{Type: promotion.TypeBundle, BuyQuantity: 2, PayQuantity: 1},
+		quantities:    map[int]int{remera: 3, musculosa: 1, buzo: 1},
+		wantDiscounts: []int{remeraPrice, musculosaPrice, 0},
+		wantTotal:     2*remeraPrice + buzoPrice,
+	},
+	{
+		name:          "Bundle without enough units",
+		promotion:     promotion.PromotionInput{Type: promotion.TypeBundle, BuyQuantity: 3, PayQuantity: 2},
+		quantities:    map[int]int{remera: 1, buzo: 1},
+		wantDiscounts: []int{0, 0},
+		wantTotal:     remeraPrice + buzoPrice,
+	},
+	{
+		name:          "Tag scope",
+		promotion:     promotion.PromotionInput{Type: promotion.TypePercentage, Value: 50, Scope: promotion.ScopeTag, Tag: remerasTag},
+		quantities:    map[int]int{remera: 1, buzo: 1},
+		wantDiscounts: []int{5000, 0},
+		wantTotal:     25000,
+	},
+}
+
+func (s *promotionSuite) TestQuote() {
+	for i, tc := range quoteCases {
+		tc.promotion.Name = tc.name
+		tc.promotion.Code = "CASE" + string(rune('A'+i))
+		_, err := s.svc.CreatePromotion(s.ctx, tc.promotion)
+		s.Require().NoError(err, tc.name)
+
+		q := s.quote(tc.promotion.Code, tc.quantities)
+
+		discounts := make([]int, len(q.Lines))
+		for j, line := range q.Lines {
+			discounts[j] = line.Discount
+		}
+		s.Equal(tc.wantDiscounts, discounts, tc.name)
+		s.Equal(tc.wantTotal, q.Total, tc.name)
+		s.Equal(q.Subtotal-q.Discount, q.Total, tc.name)
+	}
+}
+
+func (s *promotionSuite) TestBestPromotionWinsWithoutStacking() {
+	_, err := s.svc.CreatePromotion(s.ctx, promotion.PromotionInput{Name: "10%", Type: promotion.TypePercentage, Value: 10})
+	s.Require().NoError(err)
+	best, err := s.svc.CreatePromotion(s.ctx, promotion.PromotionInput{Name: "$30", Type: promotion.TypeFixed, Value: 3000})
+	s.Require().NoError(err)
+
+	q := s.quote("", map[int]int{remera: 1})
+
+	s.Equal(3000, q.Lines[0].Discount)
+	s.Equal(best.ID, q.Lines[0].Promotion.ID)
+}
+
+func (s *promotionSuite) TestCouponResults() {
+	past := time.Now().Add(-time.Hour)
+	_, err := s.svc.CreatePromotion(s.ctx, promotion.PromotionInput{Name: "Vencido", Code: "viejo", Type: promotion.TypePercentage, Value: 10, EndsAt: &past})
+	s.Require().NoError(err)
+	_, err = s.svc.CreatePromotion(s.ctx, promotion.PromotionInput{Name: "Buzos", Code: "BUZOS", Type: promotion.TypePercentage, Value: 10, Scope: promotion.ScopeProduct, ProductIDs: []int64{s.ids[buzo]}})
+	s.Require().NoError(err)
+
+	unknown := s.quote("NADA", map[int]int{remera: 1})
+	expired := s.quote(" Viejo ", map[int]int{remera: 1})
+	outOfScope := s.quote("buzos", map[int]int{remera: 1})
+	valid := s.quote("buzos", map[int]int{buzo: 1})
+
+	s.Equal("unknown coupon", unknown.Coupon.Error)
+	s.Equal("coupon has expired", expired.Coupon.Error)
+	s.Equal("VIEJO", expired.Coupon.Code)
+	s.Equal("coupon gives no discount on these products", outOfScope.Coupon.Error)
+	s.False(outOfScope.Coupon.Valid)
+	s.True(valid.Coupon.Valid)
+	s.Equal(buzoPrice/10, valid.Discount)
+}
+
+func (s *promotionSuite) TestVariantPriceFollowsProductSale() {
+	salePrice, variantPrice := 8000, 12000
+	p, err := s.products.CreateProduct(s.ctx, product.CreateProductInput{
+		Name:      "Remera Premium",
+		Price:     remeraPrice,
+		SalePrice: &salePrice,
+		Sizes:     []string{"M", "XXL"},
+		Variants:  []product.CreateVariantInput{{Size: "M"}, {Size: "XXL", Stock: 1, Price: &variantPrice}},
+	})
+	s.Require().NoError(err)
+
+	q, err := s.svc.Quote(s.ctx, promotion.QuoteInput{Items: []promotion.QuoteItemInput{
+		{ProductID: p.ID, VariantID: &p.Variants[0].ID, Quantity: 1},
+		{ProductID: p.ID, VariantID: &p.Variants[1].ID, Quantity: 1},
+	}})
+	sel, selErr := p.Select("xxl", "")
+
+	s.Require().NoError(err)
+	s.Require().NoError(selErr)
+	s.Equal(salePrice, q.Lines[0].UnitPrice)
+	s.Equal(9600, q.Lines[1].UnitPrice)
+	s.Equal(9600, sel.UnitPrice)
+}
+
+var promotionValidationCases = []struct {
+	name  string
+	input promotion.PromotionInput
+}{
+	{name: "Missing name", input: promotion.PromotionInput{Type: promotion.TypeFixed, Value: 100}},
+	{name: "Percentage over 100", input: promotion.PromotionInput{Name: "x", Type: promotion.TypePercentage, Value: 101}},
+	{name: "Fixed without value", input: promotion.PromotionInput{Name: "x", Type: promotion.TypeFixed}},
+	{name: "Bundle paying for every unit", input: promotion.PromotionInput{Name: "x", Type: promotion.TypeBundle, BuyQuantity: 2, PayQuantity: 2}},
+	{name: "Tag scope without tag", input: promotion.PromotionInput{Name: "x", Type: promotion.TypeFixed, Value: 100, Scope: promotion.ScopeTag}},
+	{name: "Unknown scope", input: promotion.PromotionInput{Name: "x", Type: promotion.TypeFixed, Value: 100, Scope: "brand"}},
+}
+
+func (s *promotionSuite) TestPromotionValidation() {
+	for _, tc := range promotionValidationCases {
+		_, err := s.svc.CreatePromotion(s.ctx, tc.input)
+
+		s.ErrorIs(err, promotion.ErrValidation, tc.name)
+	}
+}
@@ -0,0 +1,211 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/database"
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+const promotionColumns = `id, name, code, type, value, buy_quantity, pay_quantity, scope, category, tag, product_ids,
+	starts_at, ends_at, usage_limit, usage_count, active, created_at, updated_at`
+
+// SQLiteRepository implements Repository using SQLite
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite promotion repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// GetAll retrieves all promotions, newest first
+func (r *SQLiteRepository) GetAll(ctx context.Context) ([]*Promotion, error) {
+	return r.query(ctx, fmt.Sprintf("SELECT %s FROM promotions ORDER BY id DESC", promotionColumns))
+}
+
+// GetAutomatic retrieves the active promotions that apply without a coupon
+func (r *SQLiteRepository) GetAutomatic(ctx context.Context) ([]*Promotion, error) {
+	return r.query(ctx, fmt.Sprintf("SELECT %s FROM promotions WHERE code = '' AND active = 1 ORDER BY id", promotionColumns))
+}
+
+// query runs a promotion query and scans every row
+func (r *SQLiteRepository) query(ctx context.Context, query string, args ...interface{}) ([]*Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := []*Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan promotion: %w", err)
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// GetByID retrieves a single promotion by ID
+func (r *SQLiteRepository) GetByID(ctx context.Context, id int64) (*Promotion, error) {
+	query := fmt.Sprintf("SELECT %s FROM promotions WHERE id = ?", promotionColumns)
+
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	return p, nil
+}
+
+// GetByCode retrieves the promotion of a normalized coupon code
+func (r *SQLiteRepository) GetByCode(ctx context.Context, code string) (*Promotion, error) {
+	query := fmt.Sprintf("SELECT %s FROM promotions WHERE code = ? AND code != ''", promotionColumns)
+
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, query, code))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promotion by code: %w", err)
+	}
+
+	return p, nil
+}
+
+// Create creates a new promotion
+func (r *SQLiteRepository) Create(ctx context.Context, input PromotionInput) (*Promotion, error) {
+	productIDs, _ := json.Marshal(scopeProductIDs(input))
+	now := time.Now()
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO promotions (name, code, type, value, buy_quantity, pay_quantity, scope, category, tag, product_ids,
+			starts_at, ends_at, usage_limit, active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, input.Name, input.Code, input.Type, input.Value, input.BuyQuantity, input.PayQuantity, input.Scope,
+		input.Category, input.Tag, string(productIDs), input.StartsAt, input.EndsAt, input.UsageLimit,
+		boolInt(*input.Active), now, now)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateCode
+		}
+		return nil, fmt.Errorf("failed to create promotion: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Update replaces the settings of an existing promotion, keeping its usage count
+func (r *SQLiteRepository) Update(ctx context.Context, id int64, input PromotionInput) (*Promotion, error) {
+	productIDs, _ := json.Marshal(scopeProductIDs(input))
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE promotions
+		SET name = ?, code = ?, type = ?, value = ?, buy_quantity = ?, pay_quantity = ?, scope = ?, category = ?, tag = ?,
+			product_ids = ?, starts_at = ?, ends_at = ?, usage_limit = ?, active = ?, updated_at = ?
+		WHERE id = ?
+	`, input.Name, input.Code, input.Type, input.Value, input.BuyQuantity, input.PayQuantity, input.Scope,
+		input.Category, input.Tag, string(productIDs), input.StartsAt, input.EndsAt, input.UsageLimit,
+		boolInt(*input.Active), time.Now(), id)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrDuplicateCode
+		}
+		return nil, fmt.Errorf("failed to update promotion: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return nil, ErrNotFound
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes a promotion
+func (r *SQLiteRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetCategoryTree retrieves the IDs of a category, by slug, and of its subcategories
+func (r *SQLiteRepository) GetCategoryTree(ctx context.Context, category string) (map[int64]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE tree(id) AS (
+			SELECT id FROM categories WHERE slug = ?
+			UNION ALL
+			SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+		)
+		SELECT id FROM tree
+	`, slug.Make(category))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category tree: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan category ID: %w", err)
+		}
+		ids[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// scopeProductIDs returns the product IDs to store, only kept for product scopes
+func scopeProductIDs(input PromotionInput) []int64 {
+	if input.Scope != ScopeProduct {
+		return []int64{}
+	}
+	return input.ProductIDs
+}
+
+// boolInt converts a bool to the integer SQLite stores
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package shipping
+
+// DefaultSettings returns the settings used until an admin saves their own:
+// Correo Argentino and Andreani home delivery in weight bands, motorbike
+// delivery around Buenos Aires and free store pickup
+func DefaultSettings() *Settings {
+	return &Settings{
+		ItemWeight: 300,
+		Zones: []*Zone{
+			{Code: "caba", Name: "CABA", Provinces: []string{"Ciudad Autónoma de Buenos Aires"}},
+			{Code: "gba", Name: "Gran Buenos Aires", PostalCodes: []PostalRange{{From: 1600, To: 1899}}},
+			{Code: "centro", Name: "Centro", Provinces: []string{"Buenos Aires", "Córdoba", "Santa Fe", "Entre Ríos", "La Pampa"}},
+			{Code: "patagonia", Name: "Patagonia", Provinces: []string{"Neuquén", "Río Negro", "Chubut", "Santa Cruz", "Tierra del Fuego"}},
+			{Code: "resto", Name: "Resto del país"},
+		},
+		Methods: []*Method{
+			{
+				Code:     "correo_argentino",
+				Name:     "Correo Argentino a domicilio",
+				Carrier:  "Correo Argentino",
+				FreeFrom: 8000000,
+				Enabled:  true,
+				Rates: weightBands(map[string][4]int{
+					// zone: price in cents up to 1 kg, up to 5 kg, over 5 kg, days
+					"caba":      {520000, 690000, 980000, 2},
+					"gba":       {590000, 760000, 1050000, 3},
+					"centro":    {680000, 890000, 1250000, 3},
+					"resto":     {790000, 990000, 1450000, 4},
+					"patagonia": {890000, 1150000, 1650000, 5},
+				}),
+			},
+			{
+				Code:    "andreani",
+				Name:    "Andreani a domicilio",
+				Carrier: "Andreani",
+				Enabled: true,
+				Rates: weightBands(map[string][4]int{
+					"caba":      {610000, 790000, 1120000, 1},
+					"gba":       {670000, 860000, 1210000, 2},
+					"centro":    {770000, 990000, 1420000, 2},
+					"resto":     {890000, 1120000, 1630000, 3},
+					"patagonia": {990000, 1280000, 1840000, 4},
+				}),
+			},
+			{
+				Code:     "moto",
+				Name:     "Moto mensajería",
+				Carrier:  "Envío propio",
+				FreeFrom: 6000000,
+				Enabled:  true,
+				Rates: []*Rate{
+					{Zone: "caba", MaxQuantity: 10, Price: 350000, MinDays: 0, MaxDays: 1},
+					{Zone: "gba", MaxQuantity: 10, Price: 550000, MinDays: 1, MaxDays: 2},
+				},
+			},
+			{
+				Code:    "retiro",
+				Name:    "Retiro en el local",
+				Pickup:  true,
+				Enabled: true,
+				Rates:   []*Rate{{Price: 0, MinDays: 0, MaxDays: 1}},
+			},
+		},
+	}
+}
+
+// weightBands builds the rates up to 1 kg, up to 5 kg and over 5 kg of each
+// zone. Delivery takes from the given days to two days more.
+func weightBands(zones map[string][4]int) []*Rate {
+	var rates []*Rate
+	for _, code := range []string{"caba", "gba", "centro", "resto", "patagonia"} {
+		z, ok := zones[code]
+		if !ok {
+			continue
+		}
+		days := z[3]
+		rates = append(rates,
+			&Rate{Zone: code, MaxWeight: 1000, Price: z[0], MinDays: days, MaxDays: days + 2},
+			&Rate{Zone: code, MaxWeight: 5000, Price: z[1], MinDays: days, MaxDays: days + 2},
+			&Rate{Zone: code, Price: z[2], MinDays: days, MaxDays: days + 2},
+		)
+	}
+	return rates
+}
@@ -0,0 +1,94 @@
+package shipping
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+)
+
+// Provinces maps the letter that starts an Argentine CPA postal code, such
+// as the X of X5000ABC, to its province
+var Provinces = map[byte]string{
+	'A': "Salta",
+	'B': "Buenos Aires",
+	'C': "Ciudad Autónoma de Buenos Aires",
+	'D': "San Luis",
+	'E': "Entre Ríos",
+	'F': "La Rioja",
+	'G': "Santiago del Estero",
+	'H': "Chaco",
+	'J': "San Juan",
+	'K': "Catamarca",
+	'L': "La Pampa",
+	'M': "Mendoza",
+	'N': "Misiones",
+	'P': "Formosa",
+	'Q': "Neuquén",
+	'R': "Río Negro",
+	'S': "Santa Fe",
+	'T': "Tucumán",
+	'U': "Chubut",
+	'V': "Tierra del Fuego",
+	'W': "Corrientes",
+	'X': "Córdoba",
+	'Y': "Jujuy",
+	'Z': "Santa Cruz",
+}
+
+// provinceAliases are other common spellings of province names
+var provinceAliases = map[string]string{
+	"caba":                      "Ciudad Autónoma de Buenos Aires",
+	"capital-federal":           "Ciudad Autónoma de Buenos Aires",
+	"ciudad-de-buenos-aires":    "Ciudad Autónoma de Buenos Aires",
+	"provincia-de-buenos-aires": "Buenos Aires",
+	"tierra-del-fuego-antartida-e-islas-del-atlantico-sur": "Tierra del Fuego",
+}
+
+// provinceBySlug indexes every province and alias by slug
+var provinceBySlug = func() map[string]string {
+	index := make(map[string]string, len(Provinces)+len(provinceAliases))
+	for _, name := range Provinces {
+		index[slug.Make(name)] = name
+	}
+	for alias, name := range provinceAliases {
+		index[alias] = name
+	}
+	return index
+}()
+
+// NormalizeProvince returns the official name of a province written in any
+// case, with or without accents, or by its CPA letter. It reports false for
+// unknown provinces.
+func NormalizeProvince(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if len(name) == 1 {
+		province, ok := Provinces[strings.ToUpper(name)[0]]
+		return province, ok
+	}
+
+	province, ok := provinceBySlug[slug.Make(name)]
+	return province, ok
+}
+
+// parsePostalCode reads a four digit postal code such as 5000 or a CPA such
+// as X5000ABC, returning its number and, for a CPA, its province
+func parsePostalCode(code string) (int, string, error) {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+
+	var province string
+	if len(code) == 8 {
+		var ok bool
+		if province, ok = Provinces[code[0]]; !ok || strings.Trim(code[5:], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return 0, "", ErrInvalidInput("postal_code must be 4 digits or a CPA like X5000ABC")
+		}
+		code = code[1:5]
+	}
+
+	number, err := strconv.Atoi(code)
+	if err != nil || len(code) != 4 || number < 1000 {
+		return 0, "", ErrInvalidInput("postal_code must be 4 digits or a CPA like X5000ABC")
+	}
+
+	return number, province, nil
+}
@@ -0,0 +1,12 @@
+package shipping
+
+import "context"
+
+// Repository defines the interface for shipping settings data access
+type Repository interface {
+	// Get retrieves the saved settings, or nil when none were saved
+	Get(ctx context.Context) (*Settings, error)
+
+	// Save replaces the saved settings
+	Save(ctx context.Context, settings *Settings, adminID int64) (*Settings, error)
+}
@@ -0,0 +1,146 @@
+package shipping
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Service provides business logic for shipping quotes
+type Service struct {
+	repo       Repository
+	carts      *cart.Service
+	promotions *promotion.Service
+}
+
+// NewService creates a new shipping service
+func NewService(repo Repository, cartService *cart.Service, promotionService *promotion.Service) *Service {
+	return &Service{repo: repo, carts: cartService, promotions: promotionService}
+}
+
+// GetSettings retrieves the shipping settings, or the defaults when none were saved
+func (s *Service) GetSettings(ctx context.Context) (*Settings, error) {
+	settings, err := s.repo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return DefaultSettings(), nil
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings replaces the shipping zones and methods
+func (s *Service) UpdateSettings(ctx context.Context, settings *Settings, adminID int64) (*Settings, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Save(ctx, settings, adminID)
+}
+
+// Quote lists the shipping options for a cart or list of items sent to a
+// province or postal code. Thresholds for free shipping apply to the order
+// total after discounts.
+func (s *Service) Quote(ctx context.Context, input QuoteInput) (*Quote, error) {
+	q := &Quote{Options: []*Option{}}
+
+	var postalCode int
+	if strings.TrimSpace(input.PostalCode) != "" {
+		var province string
+		var err error
+		if postalCode, province, err = parsePostalCode(input.PostalCode); err != nil {
+			return nil, err
+		}
+		q.PostalCode = strings.ToUpper(strings.TrimSpace(input.PostalCode))
+		q.Province = province
+	}
+	if strings.TrimSpace(input.Province) != "" {
+		province, ok := NormalizeProvince(input.Province)
+		if !ok {
+			return nil, ErrInvalidInput("unknown province")
+		}
+		q.Province = province
+	}
+	if q.Province == "" {
+		return nil, ErrInvalidInput("province or a CPA postal code like X5000ABC is required")
+	}
+
+	switch {
+	case input.CartToken != "":
+		c, err := s.carts.GetCart(ctx, input.CartToken)
+		if err != nil {
+			return nil, err
+		}
+		q.Quantity, q.Total = c.ItemCount, c.Total
+	case len(input.Items) > 0:
+		pq, err := s.promotions.Quote(ctx, promotion.QuoteInput{Items: input.Items})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range input.Items {
+			q.Quantity += item.Quantity
+		}
+		q.Total = pq.Total
+	default:
+		return nil, ErrInvalidInput("cart_token or items is required")
+	}
+	if q.Quantity == 0 {
+		return nil, ErrInvalidInput("cart has no available items")
+	}
+
+	settings, err := s.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q.Weight = q.Quantity * settings.ItemWeight
+	q.Zone = settings.zoneFor(q.Province, postalCode)
+
+	for _, m := range settings.Methods {
+		if !m.Enabled {
+			continue
+		}
+		rate := m.rateFor(q.Zone, q.Weight, q.Quantity)
+		if rate == nil {
+			continue
+		}
+
+		option := &Option{
+			Method:   m.Code,
+			Name:     m.Name,
+			Carrier:  m.Carrier,
+			Pickup:   m.Pickup,
+			Address:  m.Address,
+			Price:    rate.Price,
+			Free:     rate.Price == 0,
+			FreeFrom: m.FreeFrom,
+			MinDays:  rate.MinDays,
+			MaxDays:  rate.MaxDays,
+		}
+		if rate.FreeFrom > 0 {
+			option.FreeFrom = rate.FreeFrom
+		}
+		if option.FreeFrom > 0 && !option.Free {
+			if q.Total >= option.FreeFrom {
+				option.Price, option.Free = 0, true
+			} else {
+				option.Remaining = option.FreeFrom - q.Total
+			}
+		}
+		q.Options = append(q.Options, option)
+	}
+
+	sort.SliceStable(q.Options, func(i, j int) bool {
+		if q.Options[i].Price != q.Options[j].Price {
+			return q.Options[i].Price < q.Options[j].Price
+		}
+		return q.Options[i].MaxDays < q.Options[j].MaxDays
+	})
+
+	return q, nil
+}
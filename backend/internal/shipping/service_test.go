@@ -0,0 +1,138 @@
+package shipping_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/tomas/tienda-backend/internal/cart"
+	"github.com/tomas/tienda-backend/internal/platform/testdouble"
+	"github.com/tomas/tienda-backend/internal/product"
+	"github.com/tomas/tienda-backend/internal/promotion"
+	"github.com/tomas/tienda-backend/internal/shipping"
+)
+
+const productPrice = 550000 // $5.500
+
+type shippingSuite struct {
+	suite.Suite
+	ctx       context.Context
+	svc       *shipping.Service
+	productID int64
+}
+
+func TestShippingSuite(t *testing.T) {
+	suite.Run(t, new(shippingSuite))
+}
+
+func (s *shippingSuite) SetupTest() {
+	db := testdouble.NewDB(s.T())
+	s.ctx = context.Background()
+	products := product.NewService(product.NewSQLiteRepository(db))
+	promotions := promotion.NewService(promotion.NewSQLiteRepository(db), products)
+	carts := cart.NewService(cart.NewSQLiteRepository(db), products, promotions, time.Hour)
+	s.svc = shipping.NewService(shipping.NewSQLiteRepository(db), carts, promotions)
+
+	p, err := products.CreateProduct(s.ctx, product.CreateProductInput{Name: "Remera", Price: productPrice})
+	s.Require().NoError(err)
+	s.productID = p.ID
+}
+
+var defaultQuoteCases = []struct {
+	name          string
+	province      string
+	postalCode    string
+	quantity      int
+	wantZone      string
+	wantPrices    map[string]int // By method; methods left out are not offered
+	wantRemaining map[string]int
+}{
+	{
+		name:          "CABA, one unit",
+		province:      "CABA",
+		quantity:      1,
+		wantZone:      "caba",
+		wantPrices:    map[string]int{"retiro": 0, "moto": 350000, "correo_argentino": 520000, "andreani": 610000},
+		wantRemaining: map[string]int{"moto": 6000000 - productPrice, "correo_argentino": 8000000 - productPrice},
+	},
+	{
+		name:          "Gran Buenos Aires by postal code",
+		province:      "Buenos Aires",
+		postalCode:    "1636",
+		quantity:      1,
+		wantZone:      "gba",
+		wantPrices:    map[string]int{"retiro": 0, "moto": 550000, "correo_argentino": 590000, "andreani": 670000},
+		wantRemaining: map[string]int{"moto": 6000000 - productPrice, "correo_argentino": 8000000 - productPrice},
+	},
+	{
+		name:          "Córdoba, one unit",
+		province:      "Córdoba",
+		quantity:      1,
+		wantZone:      "centro",
+		wantPrices:    map[string]int{"retiro": 0, "correo_argentino": 680000, "andreani": 770000},
+		wantRemaining: map[string]int{"correo_argentino": 8000000 - productPrice},
+	},
+	{
+		name:       "Córdoba over the free shipping threshold",
+		province:   "Córdoba",
+		quantity:   15,
+		wantZone:   "centro",
+		wantPrices: map[string]int{"retiro": 0, "correo_argentino": 0, "andreani": 990000},
+	},
+	{
+		name:          "CABA over the motorbike quantity",
+		province:      "CABA",
+		quantity:      11,
+		wantZone:      "caba",
+		wantPrices:    map[string]int{"retiro": 0, "correo_argentino": 690000, "andreani": 790000},
+		wantRemaining: map[string]int{"correo_argentino": 8000000 - 11*productPrice},
+	},
+}
+
+func (s *shippingSuite) TestDefaultRatesAreInCents() {
+	for _, tc := range defaultQuoteCases {
+		q, err := s.svc.Quote(s.ctx, shipping.QuoteInput{
+			Items:      []promotion.QuoteItemInput{{ProductID: s.productID, Quantity: tc.quantity}},
+			Province:   tc.province,
+			PostalCode: tc.postalCode,
+		})
+		s.Require().NoError(err, tc.name)
+
+		prices := make(map[string]int)
+		remaining := make(map[string]int)
+		for _, option := range q.Options {
+			prices[option.Method] = option.Price
+			if option.Remaining > 0 {
+				remaining[option.Method] = option.Remaining
+			}
+		}
+		if tc.wantRemaining == nil {
+			tc.wantRemaining = map[string]int{}
+		}
+
+		s.Equal(tc.wantZone, q.Zone.Code, tc.name)
+		s.Equal(tc.quantity*productPrice, q.Total, tc.name)
+		s.Equal(tc.wantPrices, prices, tc.name)
+		s.Equal(tc.wantRemaining, remaining, tc.name)
+	}
+}
+
+func (s *shippingSuite) TestSavedSettingsReplaceDefaults() {
+	settings := shipping.DefaultSettings()
+	settings.Methods = settings.Methods[:1]
+	settings.Methods[0].FreeFrom = productPrice
+
+	_, err := s.svc.UpdateSettings(s.ctx, settings, 0)
+	s.Require().NoError(err)
+	q, err := s.svc.Quote(s.ctx, shipping.QuoteInput{
+		Items:    []promotion.QuoteItemInput{{ProductID: s.productID, Quantity: 1}},
+		Province: "Córdoba",
+	})
+
+	s.Require().NoError(err)
+	s.Require().Len(q.Options, 1)
+	s.True(q.Options[0].Free)
+	s.Zero(q.Options[0].Price)
+}
@@ -0,0 +1,234 @@
+package shipping
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomas/tienda-backend/internal/platform/slug"
+	"github.com/tomas/tienda-backend/internal/promotion"
+)
+
+// Settings are the shipping zones and the rates of every shipping method.
+// Prices are in cents, like product prices.
+type Settings struct {
+	ItemWeight int        `json:"item_weight"` // Grams per unit, to estimate parcel weight
+	Zones      []*Zone    `json:"zones"`
+	Methods    []*Method  `json:"methods"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"` // Empty while the defaults apply
+}
+
+// Zone groups destinations that share rates. A destination is in the first
+// zone whose postal code ranges include it, otherwise in the first zone
+// listing its province. A zone with neither takes every other destination.
+type Zone struct {
+	Code        string        `json:"code"`
+	Name        string        `json:"name"`
+	Provinces   []string      `json:"provinces,omitempty"`
+	PostalCodes []PostalRange `json:"postal_codes,omitempty"`
+}
+
+// PostalRange is an inclusive range of four digit postal codes
+type PostalRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Method is a way of getting an order to the customer, such as a carrier
+// service or store pickup
+type Method struct {
+	Code     string  `json:"code"`
+	Name     string  `json:"name"`
+	Carrier  string  `json:"carrier,omitempty"`
+	Pickup   bool    `json:"pickup,omitempty"`    // The customer collects the order
+	Address  string  `json:"address,omitempty"`   // Where pickup orders are collected
+	FreeFrom int     `json:"free_from,omitempty"` // Order total from which shipping is free
+	Enabled  bool    `json:"enabled"`
+	Rates    []*Rate `json:"rates"`
+}
+
+// Rate is the price of a method in a zone for parcels up to a weight and
+// quantity. A method is offered in the zones it has rates for, and rates
+// without a zone apply everywhere else.
+type Rate struct {
+	Zone        string `json:"zone,omitempty"`
+	MaxWeight   int    `json:"max_weight,omitempty"`   // Grams; unlimited when 0
+	MaxQuantity int    `json:"max_quantity,omitempty"` // Units; unlimited when 0
+	Price       int    `json:"price"`
+	FreeFrom    int    `json:"free_from,omitempty"` // Overrides the method threshold in this zone
+	MinDays     int    `json:"min_days"`            // Business days
+	MaxDays     int    `json:"max_days"`
+}
+
+// QuoteInput represents the parcel and destination to quote shipping for.
+// The parcel is either a cart or a list of items.
+type QuoteInput struct {
+	CartToken  string                     `json:"cart_token,omitempty"`
+	Items      []promotion.QuoteItemInput `json:"items,omitempty"`
+	Province   string                     `json:"province,omitempty"`
+	PostalCode string                     `json:"postal_code,omitempty"` // Four digits or a CPA, which also gives the province
+}
+
+// Quote lists the shipping options for a parcel and destination, cheapest first
+type Quote struct {
+	Province   string    `json:"province"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	Zone       *Zone     `json:"zone,omitempty"` // Empty when no zone covers the destination
+	Quantity   int       `json:"quantity"`
+	Weight     int       `json:"weight"` // Estimated grams
+	Total      int       `json:"total"`  // Order total the free shipping thresholds apply to
+	Options    []*Option `json:"options"`
+}
+
+// Option is a shipping method available for a quote
+type Option struct {
+	Method    string `json:"method"`
+	Name      string `json:"name"`
+	Carrier   string `json:"carrier,omitempty"`
+	Pickup    bool   `json:"pickup,omitempty"`
+	Address   string `json:"address,omitempty"`
+	Price     int    `json:"price"`
+	Free      bool   `json:"free"`
+	FreeFrom  int    `json:"free_from,omitempty"`
+	Remaining int    `json:"remaining_for_free,omitempty"` // Missing from the total to ship free
+	MinDays   int    `json:"min_days"`
+	MaxDays   int    `json:"max_days"`
+}
+
+// Validate checks that zones and methods are consistent and normalizes codes
+// and province names
+func (s *Settings) Validate() error {
+	if s.ItemWeight <= 0 {
+		return ErrInvalidInput("item_weight must be greater than 0")
+	}
+	if len(s.Methods) == 0 {
+		return ErrInvalidInput("at least one method is required")
+	}
+
+	zones := make(map[string]bool, len(s.Zones))
+	for i, z := range s.Zones {
+		if z == nil {
+			return ErrInvalidInput(fmt.Sprintf("zone %d is empty", i+1))
+		}
+		z.Code = slug.Make(z.Code)
+		z.Name = strings.TrimSpace(z.Name)
+		if z.Code == "" || z.Name == "" {
+			return ErrInvalidInput(fmt.Sprintf("zone %d needs a code and a name", i+1))
+		}
+		if zones[z.Code] {
+			return ErrInvalidInput(fmt.Sprintf("zone code %q is repeated", z.Code))
+		}
+		zones[z.Code] = true
+
+		for j, name := range z.Provinces {
+			province, ok := NormalizeProvince(name)
+			if !ok {
+				return ErrInvalidInput(fmt.Sprintf("zone %s: unknown province %q", z.Code, name))
+			}
+			z.Provinces[j] = province
+		}
+		for _, r := range z.PostalCodes {
+			if r.From < 1000 || r.To > 9999 || r.From > r.To {
+				return ErrInvalidInput(fmt.Sprintf("zone %s: postal code ranges go from 1000 to 9999 with from not after to", z.Code))
+			}
+		}
+	}
+
+	methods := make(map[string]bool, len(s.Methods))
+	for i, m := range s.Methods {
+		if m == nil {
+			return ErrInvalidInput(fmt.Sprintf("method %d is empty", i+1))
+		}
+		m.Code = strings.ReplaceAll(slug.Make(m.Code), "-", "_")
+		m.Name = strings.TrimSpace(m.Name)
+		m.Carrier = strings.TrimSpace(m.Carrier)
+		m.Address = strings.TrimSpace(m.Address)
+		if m.Code == "" || m.Name == "" {
+			return ErrInvalidInput(fmt.Sprintf("method %d needs a code and a name", i+1))
+		}
+		if methods[m.Code] {
+			return ErrInvalidInput(fmt.Sprintf("method code %q is repeated", m.Code))
+		}
+		methods[m.Code] = true
+
+		if m.FreeFrom < 0 {
+			return ErrInvalidInput(fmt.Sprintf("method %s: free_from cannot be negative", m.Code))
+		}
+		if len(m.Rates) == 0 {
+			return ErrInvalidInput(fmt.Sprintf("method %s needs at least one rate", m.Code))
+		}
+		for _, r := range m.Rates {
+			if r == nil {
+				return ErrInvalidInput(fmt.Sprintf("method %s has an empty rate", m.Code))
+			}
+			r.Zone = slug.Make(r.Zone)
+			switch {
+			case r.Zone != "" && !zones[r.Zone]:
+				return ErrInvalidInput(fmt.Sprintf("method %s: unknown zone %q", m.Code, r.Zone))
+			case r.Price < 0 || r.FreeFrom < 0 || r.MaxWeight < 0 || r.MaxQuantity < 0:
+				return ErrInvalidInput(fmt.Sprintf("method %s: rates cannot have negative values", m.Code))
+			case r.MinDays < 0 || r.MaxDays < r.MinDays:
+				return ErrInvalidInput(fmt.Sprintf("method %s: max_days must not be less than min_days", m.Code))
+			}
+		}
+	}
+
+	return nil
+}
+
+// zoneFor finds the zone of a destination. postalCode is 0 when unknown.
+func (s *Settings) zoneFor(province string, postalCode int) *Zone {
+	if postalCode > 0 {
+		for _, z := range s.Zones {
+			for _, r := range z.PostalCodes {
+				if postalCode >= r.From && postalCode <= r.To {
+					return z
+				}
+			}
+		}
+	}
+
+	for _, z := range s.Zones {
+		for _, p := range z.Provinces {
+			if p == province {
+				return z
+			}
+		}
+	}
+
+	for _, z := range s.Zones {
+		if len(z.Provinces) == 0 && len(z.PostalCodes) == 0 {
+			return z
+		}
+	}
+
+	return nil
+}
+
+// rateFor picks the cheapest rate of the method that fits the parcel in the
+// zone, preferring rates for the zone over rates without one. It returns nil
+// when the method does not ship the parcel there.
+func (m *Method) rateFor(zone *Zone, weight, quantity int) *Rate {
+	var zoned, general *Rate
+	for _, r := range m.Rates {
+		if (r.MaxWeight > 0 && weight > r.MaxWeight) || (r.MaxQuantity > 0 && quantity > r.MaxQuantity) {
+			continue
+		}
+
+		switch {
+		case r.Zone == "":
+			if general == nil || r.Price < general.Price {
+				general = r
+			}
+		case zone != nil && r.Zone == zone.Code:
+			if zoned == nil || r.Price < zoned.Price {
+				zoned = r
+			}
+		}
+	}
+
+	if zoned != nil {
+		return zoned
+	}
+	return general
+}
@@ -0,0 +1,67 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SQLiteRepository implements Repository using SQLite. The settings are kept
+// as a single JSON document.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository creates a new SQLite shipping repository
+func NewSQLiteRepository(db *sql.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
+// Get retrieves the saved settings, or nil when none were saved
+func (r *SQLiteRepository) Get(ctx context.Context) (*Settings, error) {
+	var data string
+	var updatedAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		"SELECT settings, updated_at FROM shipping_settings WHERE id = 1",
+	).Scan(&data, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipping settings: %w", err)
+	}
+
+	var s Settings
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, fmt.Errorf("failed to decode shipping settings: %w", err)
+	}
+	s.UpdatedAt = &updatedAt
+
+	return &s, nil
+}
+
+// Save replaces the saved settings
+func (r *SQLiteRepository) Save(ctx context.Context, settings *Settings, adminID int64) (*Settings, error) {
+	settings.UpdatedAt = nil
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode shipping settings: %w", err)
+	}
+
+	var admin interface{}
+	if adminID > 0 {
+		admin = adminID
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO shipping_settings (id, settings, admin_id, updated_at) VALUES (1, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET settings = excluded.settings, admin_id = excluded.admin_id, updated_at = excluded.updated_at
+	`, string(data), admin, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save shipping settings: %w", err)
+	}
+
+	return r.Get(ctx)
+}
@@ -17,6 +17,7 @@ type Service struct {
 	uploadDir string
 	maxSizeMB int
 	baseURL   string
+	subdir    string // Path of uploadDir within the upload directory, e.g. "designs/"
 }
 
 // NewService creates a new upload service
@@ -28,6 +29,28 @@ func NewService(uploadDir string, maxSizeMB int, baseURL string) *Service {
 	}
 }
 
+// Dir returns a service storing files in a subdirectory of the upload
+// directory, keeping them apart from the product images
+func (s *Service) Dir(name string) *Service {
+	return &Service{
+		uploadDir: filepath.Join(s.uploadDir, name),
+		maxSizeMB: s.maxSizeMB,
+		baseURL:   s.baseURL,
+		subdir:    s.subdir + name + "/",
+	}
+}
+
+// WithMaxSize returns a service accepting files up to maxSizeMB, for
+// generated files larger than uploads, such as print-ready images
+func (s *Service) WithMaxSize(maxSizeMB int) *Service {
+	return &Service{
+		uploadDir: s.uploadDir,
+		maxSizeMB: maxSizeMB,
+		baseURL:   s.baseURL,
+		subdir:    s.subdir,
+	}
+}
+
 // UploadResult contains uploaded file information
 type UploadResult struct {
 	URL      string `json:"url"`
@@ -71,7 +94,7 @@ func (s *Service) SaveFile(file multipart.File, header *multipart.FileHeader) (*
 	}
 
 	// Return result with absolute URL
-	url := s.baseURL + "/uploads/" + filename
+	url := s.baseURL + "/uploads/" + s.subdir + filename
 
 	return &UploadResult{
 		URL:      url,
@@ -80,6 +103,63 @@ func (s *Service) SaveFile(file multipart.File, header *multipart.FileHeader) (*
 	}, nil
 }
 
+// SaveBytes saves generated file content, such as a rendered image, with
+// the given extension and returns the URL
+func (s *Service) SaveBytes(data []byte, ext string) (*UploadResult, error) {
+	// Validate file size
+	maxSize := int64(s.maxSizeMB) * 1024 * 1024
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file size exceeds maximum of %dMB", s.maxSizeMB)
+	}
+
+	// Validate file type
+	if !isValidImageType(ext) {
+		return nil, fmt.Errorf("invalid file type, only jpg, jpeg, png, gif allowed")
+	}
+
+	// Ensure upload directory exists
+	if err := os.MkdirAll(s.uploadDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	// Generate unique filename
+	filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), ext)
+	if err := os.WriteFile(filepath.Join(s.uploadDir, filename), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return &UploadResult{
+		URL:      s.baseURL + "/uploads/" + s.subdir + filename,
+		Filename: filename,
+		Size:     int64(len(data)),
+	}, nil
+}
+
+// Open opens a previously uploaded file by its filename for reading
+func (s *Service) Open(filename string) (*os.File, error) {
+	// Validate filename (prevent directory traversal)
+	if filename == "" || strings.Contains(filename, "..") || strings.ContainsAny(filename, "/\\") {
+		return nil, fmt.Errorf("invalid filename %q", filename)
+	}
+
+	return os.Open(filepath.Join(s.uploadDir, filename))
+}
+
+// DeleteFile removes a previously uploaded file by its filename.
+// Files that no longer exist are ignored.
+func (s *Service) DeleteFile(filename string) error {
+	// Validate filename (prevent directory traversal)
+	if filename == "" || strings.Contains(filename, "..") || strings.ContainsAny(filename, "/\\") {
+		return fmt.Errorf("invalid filename %q", filename)
+	}
+
+	if err := os.Remove(filepath.Join(s.uploadDir, filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
 // isValidImageType checks if the file has a valid image extension
 func isValidImageType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))